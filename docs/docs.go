@@ -17,17 +17,96 @@ const docTemplate = `{
     "paths": {
         "/api/v1/container": {
             "get": {
-                "description": "Retrieves a list of all containers",
+                "description": "Retrieves a page of containers, the X-Continue-Token response header holds the token of the next page.\nWith watch=true streams ADDED, MODIFIED and DELETED container events as Server-Sent Events instead.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/event-stream"
                 ],
                 "tags": [
                     "Container"
                 ],
                 "summary": "List all containers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size, 500 by default and at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Continue token of the previous page",
+                        "name": "continue",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "image",
+                            "status",
+                            "node_id"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only containers with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only containers on this node",
+                        "name": "node_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only containers whose image starts with this prefix",
+                        "name": "image_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only containers owned by this resource, e.g. a deployment",
+                        "name": "owner_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label selector, e.g. zone=eu,tier in (web,api),!legacy",
+                        "name": "labelSelector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Stream container events",
+                        "name": "watch",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume the stream after this resource version",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -38,19 +117,34 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Updates the details of an existing container",
+                "description": "Updates the details of an existing container, labels are kept when omitted.\nThe state reported by node agents is ignored, it is set through the status sub-resource.\nThe update has to be based on the current resource version, sent as the ETag in If-Match or as resource_version in the body, a stale one fails with 409.",
                 "consumes": [
                     "application/json"
                 ],
@@ -62,6 +156,12 @@ const docTemplate = `{
                 ],
                 "summary": "Update an existing container",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the container the update is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
                     {
                         "description": "Updated container data",
                         "name": "container",
@@ -74,30 +174,54 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "204": {
-                        "description": "No Content"
+                        "description": "No Content",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Resource version of the updated container"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
                     },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Creates a new container",
+                "description": "Creates a new container, scheduling it onto a running node when node_id is omitted.\nThe node has to satisfy the placement rules, the reasons for the choice are returned in placement_reasons.\nNodes with NoSchedule or NoExecute taints are only used when spec.tolerations tolerate them.\nRetries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.",
                 "consumes": [
                     "application/json"
                 ],
@@ -109,6 +233,12 @@ const docTemplate = `{
                 ],
                 "summary": "Add a new container",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key making retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "New container data",
                         "name": "container",
@@ -129,19 +259,43 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
@@ -179,19 +333,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
@@ -224,107 +378,127 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/node": {
-            "get": {
-                "description": "Retrieves a list of all nodes",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Node"
-                ],
-                "summary": "List all nodes",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entity.Node"
-                            }
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Updates an existing node",
+            "patch": {
+                "description": "Applies a JSON Merge Patch (application/merge-patch+json) or a JSON Patch (application/json-patch+json) to the container.\nOnly the image, spec and labels can be changed, patches to other fields fail with 422. The status is reported through PUT /container/{resource_id}/status.\nAn ETag in If-Match, or else the resource_version of the patched container, has to match the current one.\nA failed test operation of a JSON Patch fails with 412.",
                 "consumes": [
-                    "application/json"
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Node"
+                    "Container"
                 ],
-                "summary": "Update a node",
+                "summary": "Patch a container",
                 "parameters": [
                     {
-                        "description": "Updated Node Data",
-                        "name": "node",
+                        "type": "string",
+                        "description": "Container's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the container the patch is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Patch document",
+                        "name": "patch",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Node"
+                            "type": "object"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Container"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Resource version of the patched container"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
                     },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Creates a new node",
+            }
+        },
+        "/api/v1/container/{resource_id}/events": {
+            "get": {
+                "description": "Lists the events recorded for a container, like moves to other nodes, oldest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -332,31 +506,52 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Node"
+                    "Container"
+                ],
+                "summary": "Get container events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Add a new node",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Node"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.ContainerEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/node/{resource_id}": {
+        "/api/v1/container/{resource_id}/history": {
             "get": {
-                "description": "Allows to get node with it ID",
+                "description": "Lists every status change of a container, oldest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -364,13 +559,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Node"
+                    "Container"
                 ],
-                "summary": "Get node by id",
+                "summary": "Get container status history",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Node's ID",
+                        "description": "Container's ID",
                         "name": "resource_id",
                         "in": "path",
                         "required": true
@@ -378,102 +573,3701 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    }
-                }
-            },
-            "delete": {
-                "description": "Deletes a node by its ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Node"
-                ],
-                "summary": "Delete a node",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Node's ID",
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.ContainerStatusChange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/container/{resource_id}/logs": {
+            "get": {
+                "description": "Returns the stored log entries of a container oldest first.\nWith follow=true the entries and every new one are streamed as Server-Sent Events instead, until the container terminates or is removed.\nFollowers resume with the Last-Event-ID header holding the seq of the last entry they received.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Container"
+                ],
+                "summary": "Get container logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container's ID",
                         "name": "resource_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the last entries",
+                        "name": "tail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries logged at or after this RFC 3339 time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Stream new entries",
+                        "name": "follow",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.LogEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
                     },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Stores log entries sent by the node agent of a container as newline delimited JSON, one entry per line.\nThe body may be streamed with chunked transfer encoding, entries are stored as they arrive in batches of up to 100.\nThe stream defaults to stdout and the timestamp to the time of arrival, seq is assigned by the API.",
+                "consumes": [
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Container"
+                ],
+                "summary": "Ingest container logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Log entries, one JSON object per line",
+                        "name": "entries",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.LogEntry"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "string"
+                                "type": "integer"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/container/{resource_id}/status": {
+            "put": {
+                "description": "Stores the status and state reported by the node agent without touching the container's spec.\nThe state replaces the previous one, exit_code, signal, oom_killed and finished_at are only accepted with the stopped, exited and failed statuses.\nAgents report the results of the liveness and readiness probes in state.liveness and state.readiness, the Live and Ready conditions are derived from them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Container"
+                ],
+                "summary": "Report the status of a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reported status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.UpdateContainerStatus"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Container"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/container:batchCreate": {
+            "post": {
+                "description": "Creates up to 500 containers like the single create does, in one transaction, scheduling each against the capacity left by the ones before it.\nIn atomic mode, the default, a failed item fails the whole batch with its status and the results member lists every item; items that were rolled back fail with 409.\nIn best_effort mode the items that succeed are created and the response is 200 with the result of every item.\nRetries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Container"
+                ],
+                "summary": "Add containers in a batch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key making retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New containers",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.BatchAddContainers"
+                        }
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ContainerBatchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/container:batchDelete": {
+            "post": {
+                "description": "Deletes up to 500 containers by their IDs in one transaction.\nIn atomic mode, the default, a failed item fails the whole batch with its status and the results member lists every item; items that were rolled back fail with 409.\nIn best_effort mode the items that succeed are deleted and the response is 200 with the result of every item.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Container"
+                ],
+                "summary": "Delete containers in a batch",
+                "parameters": [
+                    {
+                        "description": "IDs of the containers",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.BatchDelete"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.BatchDeleteResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/container:batchUpdate": {
+            "post": {
+                "description": "Updates up to 500 containers like the single update does, in one transaction.\nEvery item has to carry the resource_version it is based on, items without one fail with 428 and a stale one fails the item with 409.\nIn atomic mode, the default, a failed item fails the whole batch with its status and the results member lists every item; items that were rolled back fail with 409.\nIn best_effort mode the items that succeed are updated and the response is 200 with the result of every item.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Container"
+                ],
+                "summary": "Update containers in a batch",
+                "parameters": [
+                    {
+                        "description": "Updated containers",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.BatchUpdateContainers"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ContainerBatchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/cronjob": {
+            "get": {
+                "description": "Retrieves a page of cron jobs, the X-Continue-Token response header holds the token of the next page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "CronJob"
+                ],
+                "summary": "List all cron jobs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size, 500 by default and at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Continue token of the previous page",
+                        "name": "continue",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "name"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.CronJob"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Updates the schedule, concurrency policy, suspension, history limits and job template of a cron job.\nThe name and status cannot be changed, jobs created already keep their template.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "CronJob"
+                ],
+                "summary": "Update an existing cron job",
+                "parameters": [
+                    {
+                        "description": "Updated cron job data",
+                        "name": "cronjob",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.CronJob"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CronJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new cron job, the controller then creates a job from job_template whenever the schedule is due.\nThe schedule is a standard five field cron expression in UTC or one of the @hourly, @daily, @weekly, @monthly and @yearly macros.\nconcurrency_policy decides what happens when the previous job is still active: Allow runs both, Forbid skips the new run and Replace deletes the active job.\nRetries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "CronJob"
+                ],
+                "summary": "Add a new cron job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key making retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New cron job data",
+                        "name": "cronjob",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.AddCronJob"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CronJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/cronjob/{resource_id}": {
+            "get": {
+                "description": "Allows to get a cron job by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "CronJob"
+                ],
+                "summary": "Get cron job by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cron job's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CronJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a cron job by its ID together with its jobs and their containers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "CronJob"
+                ],
+                "summary": "Delete a cron job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cron job's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/deployment": {
+            "get": {
+                "description": "Retrieves a page of deployments, the X-Continue-Token response header holds the token of the next page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deployment"
+                ],
+                "summary": "List all deployments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size, 500 by default and at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Continue token of the previous page",
+                        "name": "continue",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "name"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.Deployment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Updates the replica count and template of a deployment, the name and status cannot be changed.\nTemplate changes apply to containers created afterwards.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deployment"
+                ],
+                "summary": "Update an existing deployment",
+                "parameters": [
+                    {
+                        "description": "Updated deployment data",
+                        "name": "deployment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Deployment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Deployment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new deployment, the controller then creates replicas containers from the template.\nThe containers are owned by the deployment and removed together with it.\nRetries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deployment"
+                ],
+                "summary": "Add a new deployment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key making retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New deployment data",
+                        "name": "deployment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.AddDeployment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Deployment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/deployment/{resource_id}": {
+            "get": {
+                "description": "Allows to get a deployment by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deployment"
+                ],
+                "summary": "Get deployment by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Deployment's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Deployment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a deployment by its ID together with its containers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deployment"
+                ],
+                "summary": "Delete a deployment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Deployment's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/job": {
+            "get": {
+                "description": "Retrieves a page of jobs, the X-Continue-Token response header holds the token of the next page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "List all jobs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size, 500 by default and at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Continue token of the previous page",
+                        "name": "continue",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "name"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only jobs owned by this cron job",
+                        "name": "owner_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.Job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new job, the controller then runs containers from the template until completions of them exit successfully.\nFailed containers are retried with an exponential backoff until more than backoff_limit of them failed.\nThe restart policy of the template defaults to never, other policies are rejected.\nRetries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "Add a new job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key making retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New job data",
+                        "name": "job",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.AddJob"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/job/{resource_id}": {
+            "get": {
+                "description": "Allows to get a job by its ID, status.runs holds the exit codes of its finished containers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "Get job by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a job by its ID together with its containers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "Delete a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node": {
+            "get": {
+                "description": "Retrieves a page of nodes, the X-Continue-Token response header holds the token of the next page.\nWith watch=true streams ADDED, MODIFIED and DELETED node events as Server-Sent Events instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "List all nodes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size, 500 by default and at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Continue token of the previous page",
+                        "name": "continue",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "status"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only nodes with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label selector, e.g. zone=eu,tier in (web,api),!legacy",
+                        "name": "labelSelector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Stream node events",
+                        "name": "watch",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume the stream after this resource version",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.Node"
+                            }
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Updates the status, labels and taints of an existing node, labels and taints are kept when omitted. Containers that do not tolerate a NoExecute taint are moved to other nodes\nThe update has to be based on the current resource version, sent as the ETag in If-Match or as resource_version in the body, a stale one fails with 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Update a node",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the node the update is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Updated Node Data",
+                        "name": "node",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Node"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Resource version of the updated node"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new node, optionally reporting its capacity, labels and taints\nRetries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Add a new node",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key making retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New node data",
+                        "name": "node",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/entity.AddNode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Node"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/{resource_id}": {
+            "get": {
+                "description": "Allows to get node with it ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Get node by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Node's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a node by its ID, a node with containers assigned to it cannot be deleted, drain it first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Delete a node",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Node's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Applies a JSON Merge Patch (application/merge-patch+json) or a JSON Patch (application/json-patch+json) to the node.\nOnly the status, labels and taints can be changed, patches to other fields fail with 422.\nAn ETag in If-Match, or else the resource_version of the patched node, has to match the current one.\nA failed test operation of a JSON Patch fails with 412.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Patch a node",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Node's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the node the patch is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Patch document",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Node"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Resource version of the patched node"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/{resource_id}/cordon": {
+            "post": {
+                "description": "Marks the node unschedulable, no new containers are placed on it while the ones already there keep running",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Cordon a node",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Node's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Node"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/{resource_id}/desired-state": {
+            "get": {
+                "description": "Returns every container assigned to the node with the node revision.\nWhen the revision query parameter equals the current revision nothing is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Get node desired state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Node's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision the agent already has",
+                        "name": "revision",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.NodeDesiredState"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/{resource_id}/drain": {
+            "post": {
+                "description": "Cordons the node and moves every container on it to other nodes chosen by the scheduler.\nContainers that cannot be moved stay on the node and are listed in failed, the response is 409 then.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Drain a node",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Node's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.NodeDrain"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Problem with the node_id, moved and failed members of the drain",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/{resource_id}/heartbeat": {
+            "post": {
+                "description": "Records that the node is alive, used to detect failed nodes, optionally updating its capacity",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Send node heartbeat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Node's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Heartbeat data",
+                        "name": "heartbeat",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/entity.NodeHeartbeat"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/{resource_id}/uncordon": {
+            "post": {
+                "description": "Makes a cordoned node schedulable again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Uncordon a node",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Node's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Node"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node:batchCreate": {
+            "post": {
+                "description": "Creates up to 500 nodes like the single create does, in one transaction.\nIn atomic mode, the default, a failed item fails the whole batch with its status and the results member lists every item; items that were rolled back fail with 409.\nIn best_effort mode the items that succeed are created and the response is 200 with the result of every item.\nRetries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Add nodes in a batch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key making retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New nodes",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.BatchAddNodes"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.NodeBatchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node:batchDelete": {
+            "post": {
+                "description": "Deletes up to 500 nodes by their IDs in one transaction, nodes with containers assigned to them cannot be deleted.\nIn atomic mode, the default, a failed item fails the whole batch with its status and the results member lists every item; items that were rolled back fail with 409.\nIn best_effort mode the items that succeed are deleted and the response is 200 with the result of every item.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Delete nodes in a batch",
+                "parameters": [
+                    {
+                        "description": "IDs of the nodes",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.BatchDelete"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.BatchDeleteResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node:batchUpdate": {
+            "post": {
+                "description": "Updates the status, labels and taints of up to 500 nodes like the single update does, in one transaction.\nEvery item has to carry the resource_version it is based on, items without one fail with 428 and a stale one fails the item with 409.\nIn atomic mode, the default, a failed item fails the whole batch with its status and the results member lists every item; items that were rolled back fail with 409.\nIn best_effort mode the items that succeed are updated and the response is 200 with the result of every item.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Update nodes in a batch",
+                "parameters": [
+                    {
+                        "description": "Updated nodes",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.BatchUpdateNodes"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.NodeBatchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/rollout": {
+            "get": {
+                "description": "Retrieves a page of rollouts, the X-Continue-Token response header holds the token of the next page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rollout"
+                ],
+                "summary": "List all rollouts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size, 500 by default and at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Continue token of the previous page",
+                        "name": "continue",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "phase"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.Rollout"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Switches every container running from_image to to_image, batch_size containers at a time.\nEach batch has to become ready before the next one starts, and at most max_unavailable of the containers may be not ready at once.\nA switched container that fails, exits or stops halts the rollout.\nRetries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rollout"
+                ],
+                "summary": "Start a rollout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key making retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New rollout data",
+                        "name": "rollout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.AddRollout"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Rollout"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/rollout/{resource_id}": {
+            "get": {
+                "description": "Allows to get a rollout by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rollout"
+                ],
+                "summary": "Get rollout by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rollout's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Rollout"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/rollout/{resource_id}/pause": {
+            "post": {
+                "description": "Stops an active rollout from switching further containers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rollout"
+                ],
+                "summary": "Pause a rollout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rollout's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Rollout"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/rollout/{resource_id}/resume": {
+            "post": {
+                "description": "Continues a paused rollout",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rollout"
+                ],
+                "summary": "Resume a rollout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rollout's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Rollout"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/rollout/{resource_id}/rollback": {
+            "post": {
+                "description": "Switches every container the rollout updated back to from_image, batch_size containers at a time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rollout"
+                ],
+                "summary": "Roll back a rollout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rollout's ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Rollout"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.BatchDeleteResult": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BatchItem"
+                    }
+                }
+            }
+        },
+        "api.BatchItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "ID is the id of a deleted item",
+                    "type": "string"
+                },
+                "index": {
+                    "description": "Index is the position of the item in the request",
+                    "type": "integer",
+                    "example": 0
+                },
+                "problem": {
+                    "description": "Problem describes why the item failed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "Status is the response status the item would have had as a single request",
+                    "type": "integer",
+                    "example": 201
+                }
+            }
+        },
+        "api.ContainerBatchItem": {
+            "type": "object",
+            "properties": {
+                "container": {
+                    "$ref": "#/definitions/entity.Container"
+                },
+                "id": {
+                    "description": "ID is the id of a deleted item",
+                    "type": "string"
+                },
+                "index": {
+                    "description": "Index is the position of the item in the request",
+                    "type": "integer",
+                    "example": 0
+                },
+                "problem": {
+                    "description": "Problem describes why the item failed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "Status is the response status the item would have had as a single request",
+                    "type": "integer",
+                    "example": 201
+                }
+            }
+        },
+        "api.ContainerBatchResult": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ContainerBatchItem"
+                    }
+                }
+            }
+        },
+        "api.NodeBatchItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "ID is the id of a deleted item",
+                    "type": "string"
+                },
+                "index": {
+                    "description": "Index is the position of the item in the request",
+                    "type": "integer",
+                    "example": 0
+                },
+                "node": {
+                    "$ref": "#/definitions/entity.Node"
+                },
+                "problem": {
+                    "description": "Problem describes why the item failed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "Status is the response status the item would have had as a single request",
+                    "type": "integer",
+                    "example": 201
+                }
+            }
+        },
+        "api.NodeBatchResult": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.NodeBatchItem"
+                    }
+                }
+            }
+        },
+        "api.Problem": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "node not found"
+                },
+                "instance": {
+                    "description": "Instance is the path of the request that failed",
+                    "type": "string",
+                    "example": "/api/v1/node/96222e5e-159e-46fd-8d43-32346436758c"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "description": "Type is a URI reference identifying the problem type, about:blank when the status explains it",
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "entity.AddContainer": {
+            "type": "object",
+            "properties": {
+                "image": {
+                    "type": "string"
+                },
+                "labels": {
+                    "$ref": "#/definitions/entity.Labels"
+                },
+                "node_id": {
+                    "description": "NodeID is optional, the scheduler picks a running node when it is omitted",
+                    "type": "string"
+                },
+                "placement": {
+                    "$ref": "#/definitions/entity.Placement"
+                },
+                "resources": {
+                    "$ref": "#/definitions/entity.Resources"
+                },
+                "spec": {
+                    "$ref": "#/definitions/entity.ContainerSpec"
+                }
+            }
+        },
+        "entity.AddCronJob": {
+            "type": "object",
+            "properties": {
+                "concurrency_policy": {
+                    "enum": [
+                        "Allow",
+                        "Forbid",
+                        "Replace"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.ConcurrencyPolicy"
+                        }
+                    ]
+                },
+                "failed_jobs_history_limit": {
+                    "description": "FailedJobsHistoryLimit is how many failed jobs are kept, 1 when omitted",
+                    "type": "integer"
+                },
+                "job_template": {
+                    "$ref": "#/definitions/entity.JobSpec"
+                },
+                "name": {
+                    "description": "Name is a lowercase DNS label of at most 52 characters unique among cron jobs",
+                    "type": "string"
+                },
+                "schedule": {
+                    "description": "Schedule is a cron expression evaluated in UTC, like \"0 3 * * *\"",
+                    "type": "string"
+                },
+                "successful_jobs_history_limit": {
+                    "description": "SuccessfulJobsHistoryLimit is how many succeeded jobs are kept, 3 when omitted",
+                    "type": "integer"
+                },
+                "suspend": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "entity.AddDeployment": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Name is a lowercase DNS label unique among deployments",
+                    "type": "string"
+                },
+                "replicas": {
+                    "type": "integer"
+                },
+                "template": {
+                    "$ref": "#/definitions/entity.ContainerTemplate"
+                }
+            }
+        },
+        "entity.AddJob": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Name is a lowercase DNS label unique among jobs",
+                    "type": "string"
+                },
+                "spec": {
+                    "$ref": "#/definitions/entity.JobSpec"
+                }
+            }
+        },
+        "entity.AddNode": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "$ref": "#/definitions/entity.Resources"
+                },
+                "labels": {
+                    "$ref": "#/definitions/entity.Labels"
+                },
+                "taints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Taint"
+                    }
+                }
+            }
+        },
+        "entity.AddRollout": {
+            "type": "object",
+            "properties": {
+                "batch_size": {
+                    "description": "BatchSize is how many containers are switched at once, 1 when omitted",
+                    "type": "integer"
+                },
+                "from_image": {
+                    "type": "string"
+                },
+                "max_unavailable": {
+                    "description": "MaxUnavailable is how many of the targeted containers may be not ready at once, BatchSize when omitted",
+                    "type": "integer"
+                },
+                "to_image": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BatchAddContainers": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.AddContainer"
+                    }
+                },
+                "mode": {
+                    "description": "Mode defaults to atomic",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.BatchMode"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.BatchAddNodes": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.AddNode"
+                    }
+                },
+                "mode": {
+                    "description": "Mode defaults to atomic",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.BatchMode"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.BatchDelete": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "mode": {
+                    "description": "Mode defaults to atomic",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.BatchMode"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.BatchMode": {
+            "type": "string",
+            "enum": [
+                "atomic",
+                "best_effort"
+            ],
+            "x-enum-varnames": [
+                "BatchModeAtomic",
+                "BatchModeBestEffort"
+            ]
+        },
+        "entity.BatchUpdateContainers": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Container"
+                    }
+                },
+                "mode": {
+                    "description": "Mode defaults to atomic",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.BatchMode"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.BatchUpdateNodes": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Node"
+                    }
+                },
+                "mode": {
+                    "description": "Mode defaults to atomic",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.BatchMode"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.ConcurrencyPolicy": {
+            "type": "string",
+            "enum": [
+                "Allow",
+                "Forbid",
+                "Replace"
+            ],
+            "x-enum-varnames": [
+                "ConcurrencyPolicyAllow",
+                "ConcurrencyPolicyForbid",
+                "ConcurrencyPolicyReplace"
+            ]
+        },
+        "entity.Container": {
+            "type": "object",
+            "properties": {
+                "conditions": {
+                    "description": "Conditions are derived from the status, the probes and the reported probe results",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ContainerCondition"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "labels": {
+                    "$ref": "#/definitions/entity.Labels"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the resource managing the container, nil for containers created directly",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.OwnerReference"
+                        }
+                    ]
+                },
+                "placement": {
+                    "$ref": "#/definitions/entity.Placement"
+                },
+                "placement_reasons": {
+                    "description": "PlacementReasons explains why the container was placed on its node",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reschedule_reason": {
+                    "description": "RescheduleReason is set while the container waits to be moved off its node",
+                    "type": "string"
+                },
+                "resource_version": {
+                    "description": "ResourceVersion grows with every change of the container, updates have\nto send back the version they were based on",
+                    "type": "integer"
+                },
+                "resources": {
+                    "$ref": "#/definitions/entity.Resources"
+                },
+                "spec": {
+                    "$ref": "#/definitions/entity.ContainerSpec"
+                },
+                "state": {
+                    "description": "State is reported by the node agent through the status sub-resource",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.ContainerState"
+                        }
+                    ]
+                },
+                "status": {
+                    "$ref": "#/definitions/entity.ContainerStatus"
+                }
+            }
+        },
+        "entity.ContainerAffinityTerm": {
+            "type": "object",
+            "properties": {
+                "image": {
+                    "description": "Image matches containers running exactly this image, any image when empty",
+                    "type": "string"
+                },
+                "label_selector": {
+                    "description": "LabelSelector matches containers by labels, any labels when empty",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ContainerCondition": {
+            "type": "object",
+            "properties": {
+                "last_transition_time": {
+                    "description": "LastTransitionTime is when Status last changed",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Reason is a short machine readable cause, like ReadinessProbeFailed",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "enum": [
+                        "Live",
+                        "Ready"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.ContainerConditionType"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.ContainerConditionType": {
+            "type": "string",
+            "enum": [
+                "Live",
+                "Ready"
+            ],
+            "x-enum-varnames": [
+                "ContainerConditionLive",
+                "ContainerConditionReady"
+            ]
+        },
+        "entity.ContainerEvent": {
+            "type": "object",
+            "properties": {
+                "container_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Reason is a short machine readable cause, like Rescheduled",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ContainerMove": {
+            "type": "object",
+            "properties": {
+                "container_id": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "description": "NodeID is the node the container was moved to",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ContainerMoveFailure": {
+            "type": "object",
+            "properties": {
+                "container_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ContainerPort": {
+            "type": "object",
+            "properties": {
+                "container_port": {
+                    "type": "integer"
+                },
+                "protocol": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ContainerSpec": {
+            "type": "object",
+            "properties": {
+                "args": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "command": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "env": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "liveness_probe": {
+                    "description": "LivenessProbe failures make the node agent restart the container according to its restart policy",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Probe"
+                        }
+                    ]
+                },
+                "ports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ContainerPort"
+                    }
+                },
+                "readiness_probe": {
+                    "description": "ReadinessProbe decides whether the container is ready, it is not restarted when the probe fails",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Probe"
+                        }
+                    ]
+                },
+                "restart_policy": {
+                    "$ref": "#/definitions/entity.RestartPolicy"
+                },
+                "tolerations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Toleration"
+                    }
+                },
+                "working_dir": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ContainerState": {
+            "type": "object",
+            "properties": {
+                "exit_code": {
+                    "description": "ExitCode of the process, only reported with the stopped, exited and failed statuses",
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "liveness": {
+                    "description": "Liveness and Readiness are the latest results of the probes of the spec",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.ProbeResult"
+                        }
+                    ]
+                },
+                "oom_killed": {
+                    "description": "OOMKilled is set when the process was killed for exceeding its memory limit",
+                    "type": "boolean"
+                },
+                "readiness": {
+                    "$ref": "#/definitions/entity.ProbeResult"
+                },
+                "reason": {
+                    "description": "Reason is a human readable explanation of the status, like why the container failed",
+                    "type": "string"
+                },
+                "restart_count": {
+                    "description": "RestartCount is how many times the node agent restarted the container according to its restart policy",
+                    "type": "integer"
+                },
+                "signal": {
+                    "description": "Signal that terminated the process, like SIGKILL",
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ContainerStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "creating",
+                "running",
+                "stopped",
+                "exited",
+                "failed",
+                "terminating"
+            ],
+            "x-enum-varnames": [
+                "ContainerStatusPending",
+                "ContainerStatusCreating",
+                "ContainerStatusRunning",
+                "ContainerStatusStopped",
+                "ContainerStatusExited",
+                "ContainerStatusFailed",
+                "ContainerStatusTerminating"
+            ]
+        },
+        "entity.ContainerStatusChange": {
+            "type": "object",
+            "properties": {
+                "changed_at": {
+                    "type": "string"
+                },
+                "container_id": {
+                    "type": "string"
+                },
+                "from": {
+                    "$ref": "#/definitions/entity.ContainerStatus"
+                },
+                "to": {
+                    "$ref": "#/definitions/entity.ContainerStatus"
+                }
+            }
+        },
+        "entity.ContainerTemplate": {
+            "type": "object",
+            "properties": {
+                "image": {
+                    "type": "string"
+                },
+                "labels": {
+                    "$ref": "#/definitions/entity.Labels"
+                },
+                "placement": {
+                    "$ref": "#/definitions/entity.Placement"
+                },
+                "resources": {
+                    "$ref": "#/definitions/entity.Resources"
+                },
+                "spec": {
+                    "$ref": "#/definitions/entity.ContainerSpec"
+                }
+            }
+        },
+        "entity.CronJob": {
+            "type": "object",
+            "properties": {
+                "concurrency_policy": {
+                    "enum": [
+                        "Allow",
+                        "Forbid",
+                        "Replace"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.ConcurrencyPolicy"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "failed_jobs_history_limit": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "job_template": {
+                    "$ref": "#/definitions/entity.JobSpec"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "schedule": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is maintained by the cron job controller and ignored on updates",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.CronJobStatus"
+                        }
+                    ]
+                },
+                "successful_jobs_history_limit": {
+                    "type": "integer"
+                },
+                "suspend": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "entity.CronJobStatus": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active lists the jobs of the cron job that did not finish yet",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "last_schedule_time": {
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Message explains why the last scheduled job was skipped or could not be created",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Deployment": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "replicas": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status is maintained by the deployment controller and ignored on updates",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.DeploymentStatus"
+                        }
+                    ]
+                },
+                "template": {
+                    "$ref": "#/definitions/entity.ContainerTemplate"
+                }
+            }
+        },
+        "entity.DeploymentStatus": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "Message explains why the deployment could not converge, it is empty when it did",
+                    "type": "string"
+                },
+                "ready_replicas": {
+                    "description": "ReadyReplicas is the number of owned containers that are ready",
+                    "type": "integer"
+                },
+                "replicas": {
+                    "description": "Replicas is the number of containers the deployment owns",
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.ExecAction": {
+            "type": "object",
+            "properties": {
+                "command": {
+                    "description": "Command is run inside the container, a zero exit code means success",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "entity.HTTPGetAction": {
+            "type": "object",
+            "properties": {
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "path": {
+                    "description": "Path defaults to /",
+                    "type": "string"
+                },
+                "port": {
+                    "type": "integer"
+                },
+                "scheme": {
+                    "description": "Scheme defaults to http",
+                    "type": "string",
+                    "enum": [
+                        "http",
+                        "https"
+                    ]
+                }
+            }
+        },
+        "entity.Job": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the cron job that created the job, nil for jobs created directly",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.OwnerReference"
+                        }
+                    ]
+                },
+                "spec": {
+                    "$ref": "#/definitions/entity.JobSpec"
+                },
+                "status": {
+                    "$ref": "#/definitions/entity.JobStatus"
                 }
             }
-        }
-    },
-    "definitions": {
-        "entity.AddContainer": {
+        },
+        "entity.JobPhase": {
+            "type": "string",
+            "enum": [
+                "active",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "JobPhaseActive",
+                "JobPhaseSucceeded",
+                "JobPhaseFailed"
+            ]
+        },
+        "entity.JobRun": {
             "type": "object",
             "properties": {
-                "image": {
+                "container_id": {
+                    "type": "string"
+                },
+                "exit_code": {
+                    "description": "ExitCode is the exit code reported by the node agent, nil when none was reported",
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Message is the reason reported by the node agent",
                     "type": "string"
                 },
                 "node_id": {
                     "type": "string"
+                },
+                "reason": {
+                    "description": "Reason is the final status of the container",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.ContainerStatus"
+                        }
+                    ]
+                },
+                "succeeded": {
+                    "type": "boolean"
                 }
             }
         },
-        "entity.Container": {
+        "entity.JobSpec": {
             "type": "object",
             "properties": {
-                "id": {
+                "backoff_limit": {
+                    "description": "BackoffLimit is how many failed containers are retried before the job fails",
+                    "type": "integer"
+                },
+                "completions": {
+                    "description": "Completions is how many containers have to exit with code 0, 1 when omitted",
+                    "type": "integer"
+                },
+                "parallelism": {
+                    "description": "Parallelism is how many containers may run at once, 1 when omitted",
+                    "type": "integer"
+                },
+                "template": {
+                    "description": "Template describes the containers of the job, they are never restarted in place",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.ContainerTemplate"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.JobStatus": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active is the number of containers of the job that did not finish yet",
+                    "type": "integer"
+                },
+                "completed_at": {
                     "type": "string"
                 },
-                "image": {
+                "failed": {
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Message explains why the job is waiting or failed",
                     "type": "string"
                 },
-                "node_id": {
+                "phase": {
+                    "enum": [
+                        "active",
+                        "succeeded",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.JobPhase"
+                        }
+                    ]
+                },
+                "runs": {
+                    "description": "Runs lists every finished container of the job, finished containers are removed from their nodes",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.JobRun"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.Labels": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "entity.LogEntry": {
+            "type": "object",
+            "properties": {
+                "line": {
                     "type": "string"
                 },
-                "status": {
-                    "$ref": "#/definitions/entity.ContainerStatus"
+                "seq": {
+                    "description": "Seq orders the entries of a container, it is assigned when the entry is stored",
+                    "type": "integer"
+                },
+                "stream": {
+                    "enum": [
+                        "stdout",
+                        "stderr"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.LogStream"
+                        }
+                    ]
+                },
+                "timestamp": {
+                    "type": "string"
                 }
             }
         },
-        "entity.ContainerStatus": {
+        "entity.LogStream": {
             "type": "string",
             "enum": [
-                "running",
-                "failed",
-                "pending"
+                "stdout",
+                "stderr"
             ],
             "x-enum-varnames": [
-                "ContainerStatusRunning",
-                "ContainerStatusFailed",
-                "ContainerStatusPending"
+                "LogStreamStdout",
+                "LogStreamStderr"
             ]
         },
         "entity.Node": {
             "type": "object",
             "properties": {
+                "capacity": {
+                    "$ref": "#/definitions/entity.Resources"
+                },
                 "containers": {
                     "type": "array",
                     "items": {
@@ -483,8 +4277,81 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "labels": {
+                    "$ref": "#/definitions/entity.Labels"
+                },
+                "last_seen": {
+                    "type": "string"
+                },
+                "resource_version": {
+                    "description": "ResourceVersion grows with every change of the node but heartbeats,\nupdates have to send back the version they were based on",
+                    "type": "integer"
+                },
+                "revision": {
+                    "type": "integer"
+                },
                 "status": {
                     "$ref": "#/definitions/entity.NodeStatus"
+                },
+                "taints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Taint"
+                    }
+                },
+                "unschedulable": {
+                    "description": "Unschedulable is set while the node is cordoned, no containers are placed on it then",
+                    "type": "boolean"
+                }
+            }
+        },
+        "entity.NodeDesiredState": {
+            "type": "object",
+            "properties": {
+                "containers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Container"
+                    }
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.NodeDrain": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ContainerMoveFailure"
+                    }
+                },
+                "moved": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ContainerMove"
+                    }
+                },
+                "node_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.NodeHeartbeat": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "description": "Capacity is optional, the stored capacity is kept when it is omitted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Resources"
+                        }
+                    ]
                 }
             }
         },
@@ -500,6 +4367,326 @@ const docTemplate = `{
                 "RunningNodeStatus",
                 "FailedNodeStatus"
             ]
+        },
+        "entity.OwnerReference": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "deployment",
+                        "job",
+                        "cronjob"
+                    ]
+                }
+            }
+        },
+        "entity.Placement": {
+            "type": "object",
+            "properties": {
+                "affinity": {
+                    "description": "Affinity terms must each match a container already on the node",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ContainerAffinityTerm"
+                    }
+                },
+                "anti_affinity": {
+                    "description": "AntiAffinity terms must not match any container already on the node",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ContainerAffinityTerm"
+                    }
+                },
+                "node_affinity": {
+                    "description": "NodeAffinity is a label selector the node labels must match",
+                    "type": "string"
+                },
+                "node_selector": {
+                    "description": "NodeSelector lists labels the node must have",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Labels"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.Probe": {
+            "type": "object",
+            "properties": {
+                "exec": {
+                    "$ref": "#/definitions/entity.ExecAction"
+                },
+                "failure_threshold": {
+                    "description": "FailureThreshold is how many probes in a row have to fail after a success",
+                    "type": "integer"
+                },
+                "http_get": {
+                    "description": "Exactly one of HTTPGet, TCPSocket and Exec is set",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.HTTPGetAction"
+                        }
+                    ]
+                },
+                "initial_delay_seconds": {
+                    "type": "integer"
+                },
+                "period_seconds": {
+                    "type": "integer"
+                },
+                "success_threshold": {
+                    "description": "SuccessThreshold is how many probes in a row have to succeed after a failure",
+                    "type": "integer"
+                },
+                "tcp_socket": {
+                    "$ref": "#/definitions/entity.TCPSocketAction"
+                },
+                "timeout_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.ProbeResult": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "type": "integer"
+                },
+                "consecutive_successes": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "probed_at": {
+                    "type": "string"
+                },
+                "succeeded": {
+                    "description": "Succeeded is the outcome of the latest probe",
+                    "type": "boolean"
+                }
+            }
+        },
+        "entity.Resources": {
+            "type": "object",
+            "properties": {
+                "cpu": {
+                    "description": "CPU in millicores",
+                    "type": "integer"
+                },
+                "disk": {
+                    "description": "Disk in bytes",
+                    "type": "integer"
+                },
+                "memory": {
+                    "description": "Memory in bytes",
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.RestartPolicy": {
+            "type": "string",
+            "enum": [
+                "always",
+                "on-failure",
+                "never"
+            ],
+            "x-enum-varnames": [
+                "RestartPolicyAlways",
+                "RestartPolicyOnFailure",
+                "RestartPolicyNever"
+            ]
+        },
+        "entity.Rollout": {
+            "type": "object",
+            "properties": {
+                "batch_size": {
+                    "type": "integer"
+                },
+                "containers": {
+                    "description": "Containers are the containers running FromImage when the rollout was created",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_image": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "max_unavailable": {
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Message explains why the rollout is waiting or failed",
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "phase": {
+                    "enum": [
+                        "progressing",
+                        "completed",
+                        "failed",
+                        "rolling_back",
+                        "rolled_back"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.RolloutPhase"
+                        }
+                    ]
+                },
+                "reverted": {
+                    "description": "Reverted are the containers switched back to FromImage by a rollback",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "to_image": {
+                    "type": "string"
+                },
+                "updated": {
+                    "description": "Updated are the containers switched to ToImage and not switched back yet",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "entity.RolloutPhase": {
+            "type": "string",
+            "enum": [
+                "progressing",
+                "completed",
+                "failed",
+                "rolling_back",
+                "rolled_back"
+            ],
+            "x-enum-varnames": [
+                "RolloutPhaseProgressing",
+                "RolloutPhaseCompleted",
+                "RolloutPhaseFailed",
+                "RolloutPhaseRollingBack",
+                "RolloutPhaseRolledBack"
+            ]
+        },
+        "entity.TCPSocketAction": {
+            "type": "object",
+            "properties": {
+                "port": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.Taint": {
+            "type": "object",
+            "properties": {
+                "effect": {
+                    "enum": [
+                        "NoSchedule",
+                        "PreferNoSchedule",
+                        "NoExecute"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.TaintEffect"
+                        }
+                    ]
+                },
+                "key": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.TaintEffect": {
+            "type": "string",
+            "enum": [
+                "NoSchedule",
+                "PreferNoSchedule",
+                "NoExecute"
+            ],
+            "x-enum-varnames": [
+                "TaintEffectNoSchedule",
+                "TaintEffectPreferNoSchedule",
+                "TaintEffectNoExecute"
+            ]
+        },
+        "entity.Toleration": {
+            "type": "object",
+            "properties": {
+                "effect": {
+                    "description": "Effect is the tolerated effect, every effect when empty",
+                    "enum": [
+                        "NoSchedule",
+                        "PreferNoSchedule",
+                        "NoExecute"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.TaintEffect"
+                        }
+                    ]
+                },
+                "key": {
+                    "description": "Key is the taint key, an empty key with the Exists operator tolerates every taint",
+                    "type": "string"
+                },
+                "operator": {
+                    "description": "Operator defaults to Equal",
+                    "enum": [
+                        "Equal",
+                        "Exists"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.TolerationOperator"
+                        }
+                    ]
+                },
+                "value": {
+                    "description": "Value must be empty for the Exists operator",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.TolerationOperator": {
+            "type": "string",
+            "enum": [
+                "Equal",
+                "Exists"
+            ],
+            "x-enum-varnames": [
+                "TolerationOpEqual",
+                "TolerationOpExists"
+            ]
+        },
+        "entity.UpdateContainerStatus": {
+            "type": "object",
+            "properties": {
+                "state": {
+                    "$ref": "#/definitions/entity.ContainerState"
+                },
+                "status": {
+                    "$ref": "#/definitions/entity.ContainerStatus"
+                }
+            }
         }
     }
 }`
@@ -5,13 +5,25 @@ import (
 	_ "github.com/wensiet/morchy-api/docs"
 	"github.com/wensiet/morchy-api/internal/config"
 	"github.com/wensiet/morchy-api/internal/infrastructure"
+	"github.com/wensiet/morchy-api/internal/infrastructure/postgres"
 	"github.com/wensiet/morchy-api/internal/routers"
 	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/internal/usecase/cronjob"
+	"github.com/wensiet/morchy-api/internal/usecase/deployment"
+	"github.com/wensiet/morchy-api/internal/usecase/idempotency"
+	"github.com/wensiet/morchy-api/internal/usecase/job"
+	"github.com/wensiet/morchy-api/internal/usecase/logs"
 	"github.com/wensiet/morchy-api/internal/usecase/node"
+	"github.com/wensiet/morchy-api/internal/usecase/rollout"
+	"github.com/wensiet/morchy-api/internal/usecase/scheduler"
+	"github.com/wensiet/morchy-api/internal/usecase/watch"
 	"log"
 )
 
-const DefaultDBPoolMaxConn = 30
+const (
+	DefaultDBPoolMaxConn    = 30
+	DefaultWatchHistorySize = 1000
+)
 
 func Run() {
 	ctx := context.Background()
@@ -34,10 +46,100 @@ func Run() {
 		log.Fatal(err)
 	}
 
-	nodeService := node.NewService(pgPool)
-	containerService := container.NewService(pgPool)
+	containerScheduler, err := scheduler.New(cfg.Scheduler.Strategy)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bus := watch.NewBus(DefaultWatchHistorySize)
+
+	nodeService := node.NewService(postgres.NewNodeRepository(pgPool), bus)
+	containerService := container.NewService(
+		postgres.NewContainerRepository(pgPool),
+		nodeService,
+		containerScheduler,
+		bus,
+	)
+	deploymentService := deployment.NewService(
+		postgres.NewDeploymentRepository(pgPool),
+		containerService,
+	)
+	rolloutService := rollout.NewService(
+		postgres.NewRolloutRepository(pgPool),
+		containerService,
+	)
+	jobService := job.NewService(
+		postgres.NewJobRepository(pgPool),
+		containerService,
+	)
+	cronJobService := cronjob.NewService(
+		postgres.NewCronJobRepository(pgPool),
+		jobService,
+	)
+	logService := logs.NewService(
+		postgres.NewContainerLogRepository(pgPool),
+		containerService,
+		cfg.Log.FollowInterval,
+	)
+	idempotencyService := idempotency.NewService(
+		postgres.NewIdempotencyRepository(pgPool),
+		cfg.Idempotency.LockTimeout,
+	)
 
-	router := routers.InitRouter(nodeService, containerService)
+	nodeMonitor := node.NewMonitor(
+		nodeService,
+		cfg.Node.HeartbeatCheckInterval,
+		cfg.Node.HeartbeatGracePeriod,
+	)
+	go nodeMonitor.Run(ctx)
+
+	taintManager := container.NewTaintManager(containerService, cfg.Node.TaintResyncInterval)
+	go taintManager.Run(ctx)
+
+	rescheduler := container.NewRescheduler(
+		containerService,
+		cfg.Node.RescheduleCheckInterval,
+		cfg.Node.FailedRescheduleDelay,
+	)
+	go rescheduler.Run(ctx)
+
+	deploymentController := deployment.NewController(deploymentService, cfg.Deployment.SyncInterval)
+	go deploymentController.Run(ctx)
+
+	rolloutController := rollout.NewController(rolloutService, cfg.Rollout.SyncInterval)
+	go rolloutController.Run(ctx)
+
+	jobController := job.NewController(jobService, cfg.Job.SyncInterval)
+	go jobController.Run(ctx)
+
+	cronJobController := cronjob.NewController(cronJobService, cfg.CronJob.SyncInterval)
+	go cronJobController.Run(ctx)
+
+	logPruner := logs.NewPruner(
+		logService,
+		cfg.Log.PruneInterval,
+		cfg.Log.Retention,
+		cfg.Log.MaxEntriesPerContainer,
+	)
+	go logPruner.Run(ctx)
+
+	idempotencyPruner := idempotency.NewPruner(
+		idempotencyService,
+		cfg.Idempotency.PruneInterval,
+		cfg.Idempotency.Retention,
+	)
+	go idempotencyPruner.Run(ctx)
+
+	router := routers.InitRouter(
+		nodeService,
+		containerService,
+		deploymentService,
+		rolloutService,
+		jobService,
+		cronJobService,
+		logService,
+		idempotencyService,
+	)
 
 	err = router.Run()
 	if err != nil {
@@ -1,6 +1,9 @@
 package config
 
-import "github.com/caarlos0/env/v11"
+import (
+	"github.com/caarlos0/env/v11"
+	"time"
+)
 
 var (
 	AppName = "master"
@@ -18,6 +21,39 @@ type Config struct {
 		Name     string `env:"DB_NAME,required"`
 		Port     string `env:"DB_PORT,required"`
 	}
+	Node struct {
+		HeartbeatGracePeriod    time.Duration `env:"NODE_HEARTBEAT_GRACE_PERIOD" envDefault:"30s"`
+		HeartbeatCheckInterval  time.Duration `env:"NODE_HEARTBEAT_CHECK_INTERVAL" envDefault:"10s"`
+		TaintResyncInterval     time.Duration `env:"NODE_TAINT_RESYNC_INTERVAL" envDefault:"30s"`
+		FailedRescheduleDelay   time.Duration `env:"NODE_FAILED_RESCHEDULE_DELAY" envDefault:"1m"`
+		RescheduleCheckInterval time.Duration `env:"NODE_RESCHEDULE_CHECK_INTERVAL" envDefault:"10s"`
+	}
+	Deployment struct {
+		SyncInterval time.Duration `env:"DEPLOYMENT_SYNC_INTERVAL" envDefault:"5s"`
+	}
+	Rollout struct {
+		SyncInterval time.Duration `env:"ROLLOUT_SYNC_INTERVAL" envDefault:"5s"`
+	}
+	Job struct {
+		SyncInterval time.Duration `env:"JOB_SYNC_INTERVAL" envDefault:"5s"`
+	}
+	CronJob struct {
+		SyncInterval time.Duration `env:"CRONJOB_SYNC_INTERVAL" envDefault:"10s"`
+	}
+	Log struct {
+		Retention              time.Duration `env:"LOG_RETENTION" envDefault:"72h"`
+		MaxEntriesPerContainer int           `env:"LOG_MAX_ENTRIES_PER_CONTAINER" envDefault:"10000"`
+		PruneInterval          time.Duration `env:"LOG_PRUNE_INTERVAL" envDefault:"1m"`
+		FollowInterval         time.Duration `env:"LOG_FOLLOW_INTERVAL" envDefault:"1s"`
+	}
+	Idempotency struct {
+		Retention     time.Duration `env:"IDEMPOTENCY_RETENTION" envDefault:"24h"`
+		LockTimeout   time.Duration `env:"IDEMPOTENCY_LOCK_TIMEOUT" envDefault:"1m"`
+		PruneInterval time.Duration `env:"IDEMPOTENCY_PRUNE_INTERVAL" envDefault:"1m"`
+	}
+	Scheduler struct {
+		Strategy string `env:"SCHEDULER_STRATEGY" envDefault:"least-loaded"`
+	}
 }
 
 func NewConfig() (*Config, error) {
@@ -0,0 +1,247 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"slices"
+	"strings"
+	"time"
+)
+
+type ContainerRepository struct {
+	store *Store
+}
+
+func NewContainerRepository(store *Store) *ContainerRepository {
+	return &ContainerRepository{store: store}
+}
+
+func (r *ContainerRepository) Get(_ context.Context, id uuid.UUID) (*entity.Container, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.containers[id]
+	if !ok {
+		return nil, usecase.ContainerNotFoundErr
+	}
+	container := cloneContainer(stored)
+	return &container, nil
+}
+
+func (r *ContainerRepository) List(_ context.Context, opts entity.ListContainersOptions) ([]entity.Container, string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	containers := []entity.Container{}
+	for _, stored := range r.store.containers {
+		if opts.Status != "" && stored.Status != opts.Status {
+			continue
+		}
+		if opts.NodeID != uuid.Nil && stored.NodeID != opts.NodeID {
+			continue
+		}
+		if opts.OwnerID != uuid.Nil && (stored.Owner == nil || stored.Owner.ID != opts.OwnerID) {
+			continue
+		}
+		if opts.OwnerKind != "" && (stored.Owner == nil || stored.Owner.Kind != opts.OwnerKind) {
+			continue
+		}
+		if opts.Rescheduling && stored.RescheduleReason == "" {
+			continue
+		}
+		if !strings.HasPrefix(stored.Image, opts.ImagePrefix) {
+			continue
+		}
+		if !opts.LabelSelector.Matches(stored.Labels) {
+			continue
+		}
+		containers = append(containers, cloneContainer(stored))
+	}
+	return paginate(containers, opts.ListOptions, pagination.ContainerSortFields)
+}
+
+func (r *ContainerRepository) Create(_ context.Context, container *entity.Container) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.create(container)
+}
+
+func (r *ContainerRepository) Update(
+	_ context.Context,
+	id uuid.UUID,
+	mutate func(container *entity.Container) error,
+) (*entity.Container, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.update(id, mutate)
+}
+
+func (r *ContainerRepository) Delete(_ context.Context, id uuid.UUID) (*entity.Container, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.delete(id)
+}
+
+func (r *ContainerRepository) CreateBatch(_ context.Context, containers []*entity.Container, atomic bool) ([]error, error) {
+	return r.store.runBatch(len(containers), atomic, func(i int) error {
+		return r.create(containers[i])
+	}), nil
+}
+
+func (r *ContainerRepository) UpdateBatch(
+	_ context.Context,
+	ids []uuid.UUID,
+	mutate func(i int, container *entity.Container) error,
+	atomic bool,
+) ([]*entity.Container, []error, error) {
+	updated := make([]*entity.Container, len(ids))
+	errs := r.store.runBatch(len(ids), atomic, func(i int) (err error) {
+		updated[i], err = r.update(ids[i], func(container *entity.Container) error {
+			return mutate(i, container)
+		})
+		return err
+	})
+	return batchResults(updated, errs), errs, nil
+}
+
+func (r *ContainerRepository) DeleteBatch(_ context.Context, ids []uuid.UUID, atomic bool) ([]*entity.Container, []error, error) {
+	deleted := make([]*entity.Container, len(ids))
+	errs := r.store.runBatch(len(ids), atomic, func(i int) (err error) {
+		deleted[i], err = r.delete(ids[i])
+		return err
+	})
+	return batchResults(deleted, errs), errs, nil
+}
+
+func (r *ContainerRepository) ListStatusHistory(_ context.Context, id uuid.UUID) ([]entity.ContainerStatusChange, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.containers[id]; !ok {
+		return nil, usecase.ContainerNotFoundErr
+	}
+	history := slices.Clone(r.store.history[id])
+	if history == nil {
+		history = []entity.ContainerStatusChange{}
+	}
+	return history, nil
+}
+
+func (r *ContainerRepository) AddEvent(_ context.Context, event *entity.ContainerEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.containers[event.ContainerID]; !ok {
+		return usecase.ContainerNotFoundErr
+	}
+	r.store.events[event.ContainerID] = append(r.store.events[event.ContainerID], *event)
+	return nil
+}
+
+func (r *ContainerRepository) ListEvents(_ context.Context, id uuid.UUID) ([]entity.ContainerEvent, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.containers[id]; !ok {
+		return nil, usecase.ContainerNotFoundErr
+	}
+	events := slices.Clone(r.store.events[id])
+	if events == nil {
+		events = []entity.ContainerEvent{}
+	}
+	return events, nil
+}
+
+func (r *ContainerRepository) addStatusChange(id uuid.UUID, from, to entity.ContainerStatus) {
+	r.store.history[id] = append(r.store.history[id], entity.ContainerStatusChange{
+		ContainerID: id,
+		From:        from,
+		To:          to,
+		ChangedAt:   time.Now().UTC(),
+	})
+}
+
+func (r *ContainerRepository) create(container *entity.Container) error {
+	if err := r.reserveCapacity(container); err != nil {
+		return err
+	}
+	stored := cloneContainer(container)
+	r.store.containers[container.ID] = &stored
+	r.addStatusChange(container.ID, "", container.Status)
+	r.store.bumpRevision(container.NodeID)
+	return nil
+}
+
+func (r *ContainerRepository) update(id uuid.UUID, mutate func(container *entity.Container) error) (*entity.Container, error) {
+	current, ok := r.store.containers[id]
+	if !ok {
+		return nil, usecase.ContainerNotFoundErr
+	}
+
+	updated := cloneContainer(current)
+	if err := mutate(&updated); err != nil {
+		return nil, err
+	}
+	updated.ID = current.ID
+	updated.ResourceVersion = current.ResourceVersion + 1
+	if _, ok = r.store.nodes[updated.NodeID]; !ok {
+		return nil, usecase.NodeNotFoundErr
+	}
+	if updated.NodeID != current.NodeID || updated.Resources != current.Resources {
+		if err := r.reserveCapacity(&updated); err != nil {
+			return nil, err
+		}
+	}
+
+	if current.Status != updated.Status {
+		r.addStatusChange(id, current.Status, updated.Status)
+	}
+	if current.DesiredStateChanged(&updated) {
+		r.store.bumpRevision(current.NodeID)
+		if updated.NodeID != current.NodeID {
+			r.store.bumpRevision(updated.NodeID)
+		}
+	}
+
+	stored := cloneContainer(&updated)
+	r.store.containers[id] = &stored
+	return &updated, nil
+}
+
+func (r *ContainerRepository) delete(id uuid.UUID) (*entity.Container, error) {
+	container, ok := r.store.containers[id]
+	if !ok {
+		return nil, usecase.ContainerNotFoundErr
+	}
+	delete(r.store.containers, id)
+	delete(r.store.history, id)
+	delete(r.store.events, id)
+	delete(r.store.logs, id)
+	r.store.bumpRevision(container.NodeID)
+	return container, nil
+}
+
+// reserveCapacity checks that the container fits next to the others on its node.
+func (r *ContainerRepository) reserveCapacity(container *entity.Container) error {
+	node, ok := r.store.nodes[container.NodeID]
+	if !ok {
+		return usecase.NodeNotFoundErr
+	}
+	var allocated entity.Resources
+	for _, other := range r.store.containers {
+		if other.NodeID == container.NodeID && other.ID != container.ID {
+			allocated = allocated.Add(other.Resources)
+		}
+	}
+	if !node.Capacity.Fits(allocated, container.Resources) {
+		return fmt.Errorf("%w: node %s", usecase.InsufficientCapacityErr, container.NodeID)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+)
+
+type DeploymentRepository struct {
+	store *Store
+}
+
+func NewDeploymentRepository(store *Store) *DeploymentRepository {
+	return &DeploymentRepository{store: store}
+}
+
+func (r *DeploymentRepository) Get(_ context.Context, id uuid.UUID) (*entity.Deployment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.deployments[id]
+	if !ok {
+		return nil, usecase.DeploymentNotFoundErr
+	}
+	deployment := cloneDeployment(stored)
+	return &deployment, nil
+}
+
+func (r *DeploymentRepository) List(_ context.Context, opts entity.ListOptions) ([]entity.Deployment, string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	deployments := []entity.Deployment{}
+	for _, stored := range r.store.deployments {
+		deployments = append(deployments, cloneDeployment(stored))
+	}
+	return paginate(deployments, opts, pagination.DeploymentSortFields)
+}
+
+func (r *DeploymentRepository) Create(_ context.Context, deployment *entity.Deployment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, stored := range r.store.deployments {
+		if stored.Name == deployment.Name {
+			return usecase.DeploymentExistsErr
+		}
+	}
+	stored := cloneDeployment(deployment)
+	r.store.deployments[deployment.ID] = &stored
+	return nil
+}
+
+func (r *DeploymentRepository) Update(
+	_ context.Context,
+	id uuid.UUID,
+	mutate func(deployment *entity.Deployment) error,
+) (*entity.Deployment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.deployments[id]
+	if !ok {
+		return nil, usecase.DeploymentNotFoundErr
+	}
+
+	updated := cloneDeployment(current)
+	if err := mutate(&updated); err != nil {
+		return nil, err
+	}
+	updated.ID = id
+	updated.Name = current.Name
+
+	stored := cloneDeployment(&updated)
+	r.store.deployments[id] = &stored
+	return &updated, nil
+}
+
+func (r *DeploymentRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.deployments[id]; !ok {
+		return usecase.DeploymentNotFoundErr
+	}
+	delete(r.store.deployments, id)
+	return nil
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"time"
+)
+
+type IdempotencyRepository struct {
+	store *Store
+}
+
+func NewIdempotencyRepository(store *Store) *IdempotencyRepository {
+	return &IdempotencyRepository{store: store}
+}
+
+func (r *IdempotencyRepository) Get(_ context.Context, key string) (*entity.IdempotencyRecord, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.idempotency[key]
+	if !ok {
+		return nil, usecase.IdempotencyKeyNotFoundErr
+	}
+	record := cloneIdempotencyRecord(stored)
+	return &record, nil
+}
+
+func (r *IdempotencyRepository) Create(_ context.Context, record *entity.IdempotencyRecord) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.idempotency[record.Key]; ok {
+		return usecase.IdempotencyKeyExistsErr
+	}
+	stored := cloneIdempotencyRecord(record)
+	r.store.idempotency[record.Key] = &stored
+	return nil
+}
+
+func (r *IdempotencyRepository) Complete(_ context.Context, key string, response *entity.IdempotentResponse) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.idempotency[key]
+	if !ok {
+		return usecase.IdempotencyKeyNotFoundErr
+	}
+	completed := cloneIdempotencyRecord(&entity.IdempotencyRecord{Response: response})
+	stored.Response = completed.Response
+	return nil
+}
+
+func (r *IdempotencyRepository) Delete(_ context.Context, key string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.idempotency, key)
+	return nil
+}
+
+func (r *IdempotencyRepository) Prune(_ context.Context, olderThan time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var removed int64
+	for key, record := range r.store.idempotency {
+		if record.CreatedAt.Before(olderThan) {
+			delete(r.store.idempotency, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
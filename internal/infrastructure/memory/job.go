@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+)
+
+type JobRepository struct {
+	store *Store
+}
+
+func NewJobRepository(store *Store) *JobRepository {
+	return &JobRepository{store: store}
+}
+
+func (r *JobRepository) Get(_ context.Context, id uuid.UUID) (*entity.Job, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.jobs[id]
+	if !ok {
+		return nil, usecase.JobNotFoundErr
+	}
+	job := cloneJob(stored)
+	return &job, nil
+}
+
+func (r *JobRepository) List(_ context.Context, opts entity.ListJobsOptions) ([]entity.Job, string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	jobs := []entity.Job{}
+	for _, stored := range r.store.jobs {
+		if opts.OwnerID != uuid.Nil && (stored.Owner == nil || stored.Owner.ID != opts.OwnerID) {
+			continue
+		}
+		jobs = append(jobs, cloneJob(stored))
+	}
+	return paginate(jobs, opts.ListOptions, pagination.JobSortFields)
+}
+
+func (r *JobRepository) Create(_ context.Context, job *entity.Job) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, stored := range r.store.jobs {
+		if stored.Name == job.Name {
+			return usecase.JobExistsErr
+		}
+	}
+	stored := cloneJob(job)
+	r.store.jobs[job.ID] = &stored
+	return nil
+}
+
+func (r *JobRepository) Update(
+	_ context.Context,
+	id uuid.UUID,
+	mutate func(job *entity.Job) error,
+) (*entity.Job, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.jobs[id]
+	if !ok {
+		return nil, usecase.JobNotFoundErr
+	}
+
+	updated := cloneJob(current)
+	if err := mutate(&updated); err != nil {
+		return nil, err
+	}
+
+	// Only the status of a job can change.
+	stored := cloneJob(current)
+	stored.Status = cloneJob(&updated).Status
+	r.store.jobs[id] = &stored
+
+	job := cloneJob(&stored)
+	return &job, nil
+}
+
+func (r *JobRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.jobs[id]; !ok {
+		return usecase.JobNotFoundErr
+	}
+	delete(r.store.jobs, id)
+	return nil
+}
+
+type CronJobRepository struct {
+	store *Store
+}
+
+func NewCronJobRepository(store *Store) *CronJobRepository {
+	return &CronJobRepository{store: store}
+}
+
+func (r *CronJobRepository) Get(_ context.Context, id uuid.UUID) (*entity.CronJob, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.cronJobs[id]
+	if !ok {
+		return nil, usecase.CronJobNotFoundErr
+	}
+	cronJob := cloneCronJob(stored)
+	return &cronJob, nil
+}
+
+func (r *CronJobRepository) List(_ context.Context, opts entity.ListOptions) ([]entity.CronJob, string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	cronJobs := []entity.CronJob{}
+	for _, stored := range r.store.cronJobs {
+		cronJobs = append(cronJobs, cloneCronJob(stored))
+	}
+	return paginate(cronJobs, opts, pagination.CronJobSortFields)
+}
+
+func (r *CronJobRepository) Create(_ context.Context, cronJob *entity.CronJob) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, stored := range r.store.cronJobs {
+		if stored.Name == cronJob.Name {
+			return usecase.CronJobExistsErr
+		}
+	}
+	stored := cloneCronJob(cronJob)
+	r.store.cronJobs[cronJob.ID] = &stored
+	return nil
+}
+
+func (r *CronJobRepository) Update(
+	_ context.Context,
+	id uuid.UUID,
+	mutate func(cronJob *entity.CronJob) error,
+) (*entity.CronJob, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.cronJobs[id]
+	if !ok {
+		return nil, usecase.CronJobNotFoundErr
+	}
+
+	updated := cloneCronJob(current)
+	if err := mutate(&updated); err != nil {
+		return nil, err
+	}
+	updated.ID = id
+	updated.Name = current.Name
+	updated.CreatedAt = current.CreatedAt
+
+	stored := cloneCronJob(&updated)
+	r.store.cronJobs[id] = &stored
+	return &updated, nil
+}
+
+func (r *CronJobRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.cronJobs[id]; !ok {
+		return usecase.CronJobNotFoundErr
+	}
+	delete(r.store.cronJobs, id)
+	return nil
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"slices"
+	"time"
+)
+
+type ContainerLogRepository struct {
+	store *Store
+}
+
+func NewContainerLogRepository(store *Store) *ContainerLogRepository {
+	return &ContainerLogRepository{store: store}
+}
+
+func (r *ContainerLogRepository) Append(_ context.Context, containerID uuid.UUID, entries []entity.LogEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.containers[containerID]; !ok {
+		return usecase.ContainerNotFoundErr
+	}
+	for i := range entries {
+		r.store.logSeq++
+		entries[i].Seq = r.store.logSeq
+	}
+	r.store.logs[containerID] = append(r.store.logs[containerID], entries...)
+	return nil
+}
+
+func (r *ContainerLogRepository) List(_ context.Context, containerID uuid.UUID, opts entity.LogOptions) ([]entity.LogEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.containers[containerID]; !ok {
+		return nil, usecase.ContainerNotFoundErr
+	}
+	entries := []entity.LogEntry{}
+	for _, entry := range r.store.logs[containerID] {
+		if entry.Seq <= opts.AfterSeq || entry.Timestamp.Before(opts.Since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if opts.Tail > 0 && len(entries) > opts.Tail {
+		entries = entries[len(entries)-opts.Tail:]
+	}
+	return entries, nil
+}
+
+func (r *ContainerLogRepository) Prune(_ context.Context, olderThan time.Time, maxEntries int) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var removed int64
+	for id, entries := range r.store.logs {
+		kept := slices.DeleteFunc(slices.Clone(entries), func(entry entity.LogEntry) bool {
+			return entry.Timestamp.Before(olderThan)
+		})
+		if len(kept) > maxEntries {
+			kept = kept[len(kept)-maxEntries:]
+		}
+		removed += int64(len(entries) - len(kept))
+		r.store.logs[id] = kept
+	}
+	return removed, nil
+}
@@ -0,0 +1,335 @@
+// Package memory implements the usecase repositories in process memory. It
+// mirrors the semantics of the postgres package and is meant for tests and
+// local development.
+package memory
+
+import (
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store holds the data shared by the repositories, like a database would.
+type Store struct {
+	mu          sync.Mutex
+	nodes       map[uuid.UUID]*entity.Node
+	containers  map[uuid.UUID]*entity.Container
+	history     map[uuid.UUID][]entity.ContainerStatusChange
+	events      map[uuid.UUID][]entity.ContainerEvent
+	logs        map[uuid.UUID][]entity.LogEntry
+	logSeq      int64
+	deployments map[uuid.UUID]*entity.Deployment
+	rollouts    map[uuid.UUID]*entity.Rollout
+	jobs        map[uuid.UUID]*entity.Job
+	cronJobs    map[uuid.UUID]*entity.CronJob
+	idempotency map[string]*entity.IdempotencyRecord
+}
+
+func NewStore() *Store {
+	return &Store{
+		nodes:       make(map[uuid.UUID]*entity.Node),
+		containers:  make(map[uuid.UUID]*entity.Container),
+		history:     make(map[uuid.UUID][]entity.ContainerStatusChange),
+		events:      make(map[uuid.UUID][]entity.ContainerEvent),
+		logs:        make(map[uuid.UUID][]entity.LogEntry),
+		deployments: make(map[uuid.UUID]*entity.Deployment),
+		rollouts:    make(map[uuid.UUID]*entity.Rollout),
+		jobs:        make(map[uuid.UUID]*entity.Job),
+		cronJobs:    make(map[uuid.UUID]*entity.CronJob),
+		idempotency: make(map[string]*entity.IdempotencyRecord),
+	}
+}
+
+// nodeContainers returns copies of the containers assigned to the node ordered by id.
+func (s *Store) nodeContainers(nodeID uuid.UUID) []entity.Container {
+	containers := []entity.Container{}
+	for _, container := range s.containers {
+		if container.NodeID == nodeID {
+			containers = append(containers, cloneContainer(container))
+		}
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		return containers[i].ID.String() < containers[j].ID.String()
+	})
+	return containers
+}
+
+func (s *Store) bumpRevision(nodeID uuid.UUID) {
+	if node, ok := s.nodes[nodeID]; ok {
+		node.Revision++
+	}
+}
+
+// storeSnapshot is the state of the nodes and containers a batch may change.
+type storeSnapshot struct {
+	nodes      map[uuid.UUID]entity.Node
+	containers map[uuid.UUID]*entity.Container
+	history    map[uuid.UUID][]entity.ContainerStatusChange
+	events     map[uuid.UUID][]entity.ContainerEvent
+	logs       map[uuid.UUID][]entity.LogEntry
+}
+
+// snapshot copies the nodes, which are changed in place, and the maps of the
+// container data, whose values are only ever replaced or appended to.
+func (s *Store) snapshot() storeSnapshot {
+	nodes := make(map[uuid.UUID]entity.Node, len(s.nodes))
+	for id, node := range s.nodes {
+		nodes[id] = cloneNode(node)
+	}
+	return storeSnapshot{
+		nodes:      nodes,
+		containers: maps.Clone(s.containers),
+		history:    maps.Clone(s.history),
+		events:     maps.Clone(s.events),
+		logs:       maps.Clone(s.logs),
+	}
+}
+
+func (s *Store) restore(snapshot storeSnapshot) {
+	s.nodes = make(map[uuid.UUID]*entity.Node, len(snapshot.nodes))
+	for id, node := range snapshot.nodes {
+		s.nodes[id] = &node
+	}
+	s.containers = snapshot.containers
+	s.history = snapshot.history
+	s.events = snapshot.events
+	s.logs = snapshot.logs
+}
+
+// runBatch calls apply for every item of a batch with the store locked. When
+// atomic the first failure restores the store as it was before the batch.
+func (s *Store) runBatch(items int, atomic bool, apply func(i int) error) []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var before storeSnapshot
+	if atomic {
+		before = s.snapshot()
+	}
+	errs := make([]error, items)
+	for i := range errs {
+		if errs[i] = apply(i); errs[i] != nil && atomic {
+			s.restore(before)
+			return usecase.AbortBatch(errs, i)
+		}
+	}
+	return errs
+}
+
+// batchResults drops the results of the items that failed or were rolled back.
+func batchResults[T any](results []*T, errs []error) []*T {
+	for i := range results {
+		if errs[i] != nil {
+			results[i] = nil
+		}
+	}
+	return results
+}
+
+// paginate sorts items by the requested field and id, skips the items up to
+// the continue token and returns one page together with the next token.
+func paginate[T any](items []T, opts entity.ListOptions, fields map[string]func(*T) string) ([]T, string, error) {
+	page, err := pagination.Parse(opts, func(field string) bool {
+		_, ok := fields[field]
+		return ok
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	value, id := fields[page.SortBy], fields[pagination.DefaultSortBy]
+
+	compare := func(a, b *T) int {
+		if c := strings.Compare(value(a), value(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(id(a), id(b))
+	}
+	slices.SortFunc(items, func(a, b T) int {
+		if page.Descending {
+			return compare(&b, &a)
+		}
+		return compare(&a, &b)
+	})
+
+	if page.After != nil {
+		start := len(items)
+		for i := range items {
+			c := strings.Compare(value(&items[i]), page.After.Value)
+			if c == 0 {
+				c = strings.Compare(id(&items[i]), page.After.ID)
+			}
+			if page.Descending {
+				c = -c
+			}
+			if c > 0 {
+				start = i
+				break
+			}
+		}
+		items = items[start:]
+	}
+
+	var next string
+	if page.Limit > 0 && len(items) > page.Limit {
+		items = items[:page.Limit]
+		last := &items[len(items)-1]
+		next = page.NextToken(value(last), id(last))
+	}
+	return items, next, nil
+}
+
+func cloneNode(node *entity.Node) entity.Node {
+	clone := *node
+	if node.LastSeen != nil {
+		lastSeen := *node.LastSeen
+		clone.LastSeen = &lastSeen
+	}
+	clone.Labels = cloneLabels(node.Labels)
+	clone.Taints = append([]entity.Taint{}, node.Taints...)
+	clone.Containers = nil
+	return clone
+}
+
+func cloneContainer(container *entity.Container) entity.Container {
+	clone := *container
+	clone.Spec.Command = slices.Clone(container.Spec.Command)
+	clone.Spec.Args = slices.Clone(container.Spec.Args)
+	clone.Spec.Env = maps.Clone(container.Spec.Env)
+	clone.Spec.Ports = slices.Clone(container.Spec.Ports)
+	clone.Spec.Tolerations = slices.Clone(container.Spec.Tolerations)
+	clone.Spec.LivenessProbe = cloneProbe(container.Spec.LivenessProbe)
+	clone.Spec.ReadinessProbe = cloneProbe(container.Spec.ReadinessProbe)
+	clone.Labels = cloneLabels(container.Labels)
+	clone.Placement.NodeSelector = maps.Clone(container.Placement.NodeSelector)
+	clone.Placement.Affinity = slices.Clone(container.Placement.Affinity)
+	clone.Placement.AntiAffinity = slices.Clone(container.Placement.AntiAffinity)
+	clone.PlacementReasons = append([]string{}, container.PlacementReasons...)
+	if container.Owner != nil {
+		owner := *container.Owner
+		clone.Owner = &owner
+	}
+	if container.State.ExitCode != nil {
+		exitCode := *container.State.ExitCode
+		clone.State.ExitCode = &exitCode
+	}
+	if container.State.StartedAt != nil {
+		startedAt := *container.State.StartedAt
+		clone.State.StartedAt = &startedAt
+	}
+	if container.State.FinishedAt != nil {
+		finishedAt := *container.State.FinishedAt
+		clone.State.FinishedAt = &finishedAt
+	}
+	if container.State.Liveness != nil {
+		liveness := *container.State.Liveness
+		clone.State.Liveness = &liveness
+	}
+	if container.State.Readiness != nil {
+		readiness := *container.State.Readiness
+		clone.State.Readiness = &readiness
+	}
+	clone.Conditions = slices.Clone(container.Conditions)
+	return clone
+}
+
+func cloneProbe(probe *entity.Probe) *entity.Probe {
+	if probe == nil {
+		return nil
+	}
+	clone := *probe
+	if probe.HTTPGet != nil {
+		httpGet := *probe.HTTPGet
+		httpGet.Headers = maps.Clone(probe.HTTPGet.Headers)
+		clone.HTTPGet = &httpGet
+	}
+	if probe.TCPSocket != nil {
+		tcpSocket := *probe.TCPSocket
+		clone.TCPSocket = &tcpSocket
+	}
+	if probe.Exec != nil {
+		clone.Exec = &entity.ExecAction{Command: slices.Clone(probe.Exec.Command)}
+	}
+	return &clone
+}
+
+func cloneDeployment(deployment *entity.Deployment) entity.Deployment {
+	clone := *deployment
+	clone.Template = cloneTemplate(deployment.Template)
+	return clone
+}
+
+// cloneTemplate copies the template the way cloneContainer copies a container.
+func cloneTemplate(template entity.ContainerTemplate) entity.ContainerTemplate {
+	container := cloneContainer(&entity.Container{Spec: template.Spec, Labels: template.Labels, Placement: template.Placement})
+	template.Spec = container.Spec
+	template.Labels = container.Labels
+	template.Placement = container.Placement
+	return template
+}
+
+func cloneRollout(rollout *entity.Rollout) entity.Rollout {
+	clone := *rollout
+	clone.Containers = append([]uuid.UUID{}, rollout.Containers...)
+	clone.Updated = append([]uuid.UUID{}, rollout.Updated...)
+	clone.Reverted = append([]uuid.UUID{}, rollout.Reverted...)
+	return clone
+}
+
+func cloneJob(job *entity.Job) entity.Job {
+	clone := *job
+	clone.Spec.Template = cloneTemplate(job.Spec.Template)
+	if job.Owner != nil {
+		owner := *job.Owner
+		clone.Owner = &owner
+	}
+	clone.Status.Runs = make([]entity.JobRun, len(job.Status.Runs))
+	for i, run := range job.Status.Runs {
+		if run.ExitCode != nil {
+			exitCode := *run.ExitCode
+			run.ExitCode = &exitCode
+		}
+		clone.Status.Runs[i] = run
+	}
+	if job.Status.CompletedAt != nil {
+		completedAt := *job.Status.CompletedAt
+		clone.Status.CompletedAt = &completedAt
+	}
+	return clone
+}
+
+func cloneCronJob(cronJob *entity.CronJob) entity.CronJob {
+	clone := *cronJob
+	clone.JobTemplate.Template = cloneTemplate(cronJob.JobTemplate.Template)
+	clone.Status.Active = append([]uuid.UUID{}, cronJob.Status.Active...)
+	if cronJob.Status.LastScheduleTime != nil {
+		lastScheduleTime := *cronJob.Status.LastScheduleTime
+		clone.Status.LastScheduleTime = &lastScheduleTime
+	}
+	return clone
+}
+
+func cloneIdempotencyRecord(record *entity.IdempotencyRecord) entity.IdempotencyRecord {
+	clone := *record
+	if record.Response != nil {
+		response := *record.Response
+		response.Header = record.Response.Header.Clone()
+		response.Body = slices.Clone(record.Response.Body)
+		clone.Response = &response
+	}
+	return clone
+}
+
+// cloneLabels copies labels, nil labels are stored as empty ones like in the database.
+func cloneLabels(labels entity.Labels) entity.Labels {
+	clone := make(entity.Labels, len(labels))
+	for key, value := range labels {
+		clone[key] = value
+	}
+	return clone
+}
@@ -0,0 +1,23 @@
+package memory_test
+
+import (
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/infrastructure/repotest"
+	"testing"
+)
+
+func TestRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store := memory.NewStore()
+		return repotest.Repositories{
+			Nodes:       memory.NewNodeRepository(store),
+			Containers:  memory.NewContainerRepository(store),
+			Deployments: memory.NewDeploymentRepository(store),
+			Rollouts:    memory.NewRolloutRepository(store),
+			Jobs:        memory.NewJobRepository(store),
+			CronJobs:    memory.NewCronJobRepository(store),
+			Logs:        memory.NewContainerLogRepository(store),
+			Idempotency: memory.NewIdempotencyRepository(store),
+		}
+	})
+}
@@ -0,0 +1,224 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"sort"
+	"time"
+)
+
+type NodeRepository struct {
+	store *Store
+}
+
+func NewNodeRepository(store *Store) *NodeRepository {
+	return &NodeRepository{store: store}
+}
+
+func (r *NodeRepository) Get(_ context.Context, id uuid.UUID) (*entity.Node, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.nodes[id]
+	if !ok {
+		return nil, usecase.NodeNotFoundErr
+	}
+	node := cloneNode(stored)
+	node.Containers = r.store.nodeContainers(id)
+	return &node, nil
+}
+
+func (r *NodeRepository) List(_ context.Context, opts entity.ListNodesOptions) ([]*entity.Node, string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var matching []entity.Node
+	for _, stored := range r.store.nodes {
+		if opts.Status != "" && stored.Status != opts.Status {
+			continue
+		}
+		if !opts.LabelSelector.Matches(stored.Labels) {
+			continue
+		}
+		matching = append(matching, cloneNode(stored))
+	}
+
+	page, next, err := paginate(matching, opts.ListOptions, pagination.NodeSortFields)
+	if err != nil {
+		return nil, "", err
+	}
+	nodes := make([]*entity.Node, len(page))
+	for i := range page {
+		page[i].Containers = r.store.nodeContainers(page[i].ID)
+		nodes[i] = &page[i]
+	}
+	return nodes, next, nil
+}
+
+func (r *NodeRepository) Create(_ context.Context, node *entity.Node) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.create(node)
+	return nil
+}
+
+func (r *NodeRepository) Update(
+	_ context.Context,
+	id uuid.UUID,
+	mutate func(node *entity.Node) error,
+) (*entity.Node, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.update(id, mutate)
+}
+
+func (r *NodeRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.delete(id)
+}
+
+func (r *NodeRepository) CreateBatch(_ context.Context, nodes []*entity.Node, atomic bool) ([]error, error) {
+	return r.store.runBatch(len(nodes), atomic, func(i int) error {
+		r.create(nodes[i])
+		return nil
+	}), nil
+}
+
+func (r *NodeRepository) UpdateBatch(
+	_ context.Context,
+	ids []uuid.UUID,
+	mutate func(i int, node *entity.Node) error,
+	atomic bool,
+) ([]*entity.Node, []error, error) {
+	updated := make([]*entity.Node, len(ids))
+	errs := r.store.runBatch(len(ids), atomic, func(i int) (err error) {
+		updated[i], err = r.update(ids[i], func(node *entity.Node) error {
+			return mutate(i, node)
+		})
+		return err
+	})
+	return batchResults(updated, errs), errs, nil
+}
+
+func (r *NodeRepository) DeleteBatch(_ context.Context, ids []uuid.UUID, atomic bool) ([]error, error) {
+	return r.store.runBatch(len(ids), atomic, func(i int) error {
+		return r.delete(ids[i])
+	}), nil
+}
+
+func (r *NodeRepository) Heartbeat(_ context.Context, id uuid.UUID, seenAt time.Time, capacity *entity.Resources) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	node, ok := r.store.nodes[id]
+	if !ok {
+		return false, usecase.NodeNotFoundErr
+	}
+	node.LastSeen = &seenAt
+	if capacity == nil || node.Capacity == *capacity {
+		return false, nil
+	}
+	node.Capacity = *capacity
+	node.ResourceVersion++
+	return true, nil
+}
+
+func (r *NodeRepository) FailStale(_ context.Context, deadline time.Time) ([]uuid.UUID, error) {
+	return r.updateStatuses(entity.FailedNodeStatus, func(node *entity.Node) bool {
+		return node.Status == entity.RunningNodeStatus && (node.LastSeen == nil || node.LastSeen.Before(deadline))
+	}), nil
+}
+
+func (r *NodeRepository) Recover(_ context.Context, deadline time.Time) ([]uuid.UUID, error) {
+	return r.updateStatuses(entity.RunningNodeStatus, func(node *entity.Node) bool {
+		return (node.Status == entity.NewNodeStatus || node.Status == entity.FailedNodeStatus) &&
+			node.LastSeen != nil && !node.LastSeen.Before(deadline)
+	}), nil
+}
+
+func (r *NodeRepository) GetDesiredState(_ context.Context, id uuid.UUID) (*entity.NodeDesiredState, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	node, ok := r.store.nodes[id]
+	if !ok {
+		return nil, usecase.NodeNotFoundErr
+	}
+	return &entity.NodeDesiredState{
+		NodeID:     id,
+		Revision:   node.Revision,
+		Containers: r.store.nodeContainers(id),
+	}, nil
+}
+
+// updateStatuses sets status on every node matching and returns their ids.
+func (r *NodeRepository) updateStatuses(status entity.NodeStatus, matching func(node *entity.Node) bool) []uuid.UUID {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var ids []uuid.UUID
+	for id, node := range r.store.nodes {
+		if matching(node) {
+			node.Status = status
+			node.ResourceVersion++
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	return ids
+}
+
+func (r *NodeRepository) create(node *entity.Node) {
+	stored := cloneNode(node)
+	r.store.nodes[node.ID] = &stored
+}
+
+func (r *NodeRepository) update(id uuid.UUID, mutate func(node *entity.Node) error) (*entity.Node, error) {
+	current, ok := r.store.nodes[id]
+	if !ok {
+		return nil, usecase.NodeNotFoundErr
+	}
+
+	updated := cloneNode(current)
+	updated.Containers = r.store.nodeContainers(id)
+	if err := mutate(&updated); err != nil {
+		return nil, err
+	}
+	updated.ID = id
+	if current.Status != entity.RunningNodeStatus && updated.Status == entity.RunningNodeStatus {
+		// Nodes moved to running are not failed before their first heartbeat is due.
+		now := time.Now().UTC()
+		current.LastSeen, updated.LastSeen = &now, &now
+	}
+
+	current.Status = updated.Status
+	current.Capacity = updated.Capacity
+	current.Labels = cloneLabels(updated.Labels)
+	current.Taints = append([]entity.Taint{}, updated.Taints...)
+	current.Unschedulable = updated.Unschedulable
+	current.ResourceVersion++
+	updated.ResourceVersion = current.ResourceVersion
+	return &updated, nil
+}
+
+func (r *NodeRepository) delete(id uuid.UUID) error {
+	if _, ok := r.store.nodes[id]; !ok {
+		return usecase.NodeNotFoundErr
+	}
+	for _, container := range r.store.containers {
+		if container.NodeID == id {
+			return usecase.NodeHasContainersErr
+		}
+	}
+	delete(r.store.nodes, id)
+	return nil
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"slices"
+)
+
+type RolloutRepository struct {
+	store *Store
+}
+
+func NewRolloutRepository(store *Store) *RolloutRepository {
+	return &RolloutRepository{store: store}
+}
+
+func (r *RolloutRepository) Get(_ context.Context, id uuid.UUID) (*entity.Rollout, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.rollouts[id]
+	if !ok {
+		return nil, usecase.RolloutNotFoundErr
+	}
+	rollout := cloneRollout(stored)
+	return &rollout, nil
+}
+
+func (r *RolloutRepository) List(_ context.Context, opts entity.ListOptions) ([]entity.Rollout, string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rollouts := []entity.Rollout{}
+	for _, stored := range r.store.rollouts {
+		rollouts = append(rollouts, cloneRollout(stored))
+	}
+	return paginate(rollouts, opts, pagination.RolloutSortFields)
+}
+
+func (r *RolloutRepository) Create(_ context.Context, rollout *entity.Rollout) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rollout.Phase.IsActive() && r.conflicts(rollout) {
+		return usecase.RolloutConflictErr
+	}
+	stored := cloneRollout(rollout)
+	r.store.rollouts[rollout.ID] = &stored
+	return nil
+}
+
+func (r *RolloutRepository) Update(
+	_ context.Context,
+	id uuid.UUID,
+	mutate func(rollout *entity.Rollout) error,
+) (*entity.Rollout, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.rollouts[id]
+	if !ok {
+		return nil, usecase.RolloutNotFoundErr
+	}
+
+	updated := cloneRollout(current)
+	if err := mutate(&updated); err != nil {
+		return nil, err
+	}
+	updated.ID = id
+	updated.FromImage = current.FromImage
+	updated.ToImage = current.ToImage
+	updated.CreatedAt = current.CreatedAt
+	if updated.Phase.IsActive() && !current.Phase.IsActive() && r.conflicts(&updated) {
+		return nil, usecase.RolloutConflictErr
+	}
+
+	stored := cloneRollout(&updated)
+	r.store.rollouts[id] = &stored
+	return &updated, nil
+}
+
+// conflicts reports whether another active rollout has a container of rollout.
+func (r *RolloutRepository) conflicts(rollout *entity.Rollout) bool {
+	for _, active := range r.store.rollouts {
+		if active.ID == rollout.ID || !active.Phase.IsActive() {
+			continue
+		}
+		if slices.ContainsFunc(rollout.Containers, func(id uuid.UUID) bool { return slices.Contains(active.Containers, id) }) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,449 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"strings"
+	"time"
+)
+
+const (
+	GetContainerQuery   = "SELECT " + containerColumns + " FROM container WHERE id = $1"
+	LockContainerQuery  = GetContainerQuery + " FOR UPDATE"
+	ListContainersQuery = "SELECT " + containerColumns + " FROM container"
+	AddContainerQuery   = "INSERT INTO container (" + containerColumns + ") " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)"
+	UpdateContainerQuery = `
+		UPDATE container
+		SET node_id = $1, image = $2, status = $3, cpu_request = $4, memory_request = $5, disk_request = $6,
+		    spec = $7, labels = $8, placement = $9, placement_reasons = $10, reschedule_reason = $11,
+		    owner_kind = $12, owner_id = $13, state = $14, conditions = $15, resource_version = $16
+		WHERE id = $17`
+	LockNodeCapacityQuery = "SELECT cpu_capacity, memory_capacity, disk_capacity FROM node WHERE id = $1 FOR UPDATE"
+	NodeAllocatedQuery    = `
+		SELECT COALESCE(SUM(cpu_request), 0), COALESCE(SUM(memory_request), 0), COALESCE(SUM(disk_request), 0)
+		FROM container
+		WHERE node_id = $1 AND id <> $2`
+	DeleteContainerQuery          = "DELETE FROM container WHERE id = $1 RETURNING " + containerColumns
+	ContainerExistsQuery          = "SELECT EXISTS(SELECT 1 FROM container WHERE id = $1)"
+	AddContainerStatusChangeQuery = `
+		INSERT INTO container_status_history (container_id, from_status, to_status, changed_at)
+		VALUES ($1, $2, $3, $4)`
+	ListContainerStatusHistoryQuery = `
+		SELECT container_id, from_status, to_status, changed_at
+		FROM container_status_history
+		WHERE container_id = $1
+		ORDER BY changed_at, id`
+	AddContainerEventQuery = `
+		INSERT INTO container_event (container_id, reason, message, created_at)
+		VALUES ($1, $2, $3, $4)`
+	ListContainerEventsQuery = `
+		SELECT container_id, reason, message, created_at
+		FROM container_event
+		WHERE container_id = $1
+		ORDER BY created_at, id`
+)
+
+var containerSortColumns = map[string]string{
+	"id":      "id",
+	"image":   "image",
+	"status":  "status",
+	"node_id": "node_id",
+}
+
+type ContainerRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewContainerRepository(dbPool *pgxpool.Pool) *ContainerRepository {
+	return &ContainerRepository{dbPool: dbPool}
+}
+
+func (r *ContainerRepository) Get(ctx context.Context, id uuid.UUID) (*entity.Container, error) {
+	var container entity.Container
+	err := scanContainer(r.dbPool.QueryRow(ctx, GetContainerQuery, id), &container)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ContainerNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+func (r *ContainerRepository) List(ctx context.Context, opts entity.ListContainersOptions) ([]entity.Container, string, error) {
+	var b pagination.Builder
+	if opts.Status != "" {
+		b.Where("status = %s", opts.Status)
+	}
+	if opts.NodeID != uuid.Nil {
+		b.Where("node_id = %s", opts.NodeID)
+	}
+	if opts.OwnerID != uuid.Nil {
+		b.Where("owner_id = %s", opts.OwnerID)
+	}
+	if opts.OwnerKind != "" {
+		b.Where("owner_kind = %s", opts.OwnerKind)
+	}
+	if opts.Rescheduling {
+		b.Where("reschedule_reason <> ''")
+	}
+	if opts.ImagePrefix != "" {
+		b.Where(`image LIKE %s ESCAPE '\'`, pagination.EscapeLike(opts.ImagePrefix)+"%")
+	}
+	whereLabels(&b, opts.LabelSelector)
+	page, orderBy, limit, err := b.Page(opts.ListOptions, containerSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := strings.Join([]string{ListContainersQuery, b.WhereClause(), orderBy, limit}, " ")
+	rows, err := r.dbPool.Query(ctx, query, b.Args()...)
+	if err != nil {
+		return nil, "", err
+	}
+	containers, err := scanContainers(rows)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if page.Limit > 0 && len(containers) > page.Limit {
+		containers = containers[:page.Limit]
+		last := containers[len(containers)-1]
+		next = page.NextToken(pagination.ContainerSortFields[page.SortBy](&last), last.ID.String())
+	}
+	return containers, next, nil
+}
+
+func (r *ContainerRepository) Create(ctx context.Context, container *entity.Container) error {
+	tx, err := r.dbPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = createContainer(ctx, tx, container); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *ContainerRepository) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	mutate func(container *entity.Container) error,
+) (*entity.Container, error) {
+	tx, err := r.dbPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	updated, err := updateContainer(ctx, tx, id, mutate)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (r *ContainerRepository) Delete(ctx context.Context, id uuid.UUID) (*entity.Container, error) {
+	tx, err := r.dbPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	container, err := deleteContainer(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return container, nil
+}
+
+func (r *ContainerRepository) CreateBatch(ctx context.Context, containers []*entity.Container, atomic bool) ([]error, error) {
+	return runBatch(ctx, r.dbPool, len(containers), atomic, func(q querier, i int) error {
+		return createContainer(ctx, q, containers[i])
+	})
+}
+
+func (r *ContainerRepository) UpdateBatch(
+	ctx context.Context,
+	ids []uuid.UUID,
+	mutate func(i int, container *entity.Container) error,
+	atomic bool,
+) ([]*entity.Container, []error, error) {
+	updated := make([]*entity.Container, len(ids))
+	errs, err := runBatch(ctx, r.dbPool, len(ids), atomic, func(q querier, i int) (err error) {
+		updated[i], err = updateContainer(ctx, q, ids[i], func(container *entity.Container) error {
+			return mutate(i, container)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return batchResults(updated, errs), errs, nil
+}
+
+func (r *ContainerRepository) DeleteBatch(ctx context.Context, ids []uuid.UUID, atomic bool) ([]*entity.Container, []error, error) {
+	deleted := make([]*entity.Container, len(ids))
+	errs, err := runBatch(ctx, r.dbPool, len(ids), atomic, func(q querier, i int) (err error) {
+		deleted[i], err = deleteContainer(ctx, q, ids[i])
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return batchResults(deleted, errs), errs, nil
+}
+
+func (r *ContainerRepository) ListStatusHistory(ctx context.Context, id uuid.UUID) ([]entity.ContainerStatusChange, error) {
+	var exists bool
+	if err := r.dbPool.QueryRow(ctx, ContainerExistsQuery, id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, usecase.ContainerNotFoundErr
+	}
+
+	rows, err := r.dbPool.Query(ctx, ListContainerStatusHistoryQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []entity.ContainerStatusChange{}
+	for rows.Next() {
+		var change entity.ContainerStatusChange
+		var from sql.NullString
+		err = rows.Scan(&change.ContainerID, &from, &change.To, &change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		change.From = entity.ContainerStatus(from.String)
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
+
+func (r *ContainerRepository) AddEvent(ctx context.Context, event *entity.ContainerEvent) error {
+	_, err := r.dbPool.Exec(ctx, AddContainerEventQuery, event.ContainerID, event.Reason, event.Message, event.CreatedAt)
+	if isForeignKeyViolation(err) {
+		return usecase.ContainerNotFoundErr
+	}
+	return err
+}
+
+func (r *ContainerRepository) ListEvents(ctx context.Context, id uuid.UUID) ([]entity.ContainerEvent, error) {
+	var exists bool
+	if err := r.dbPool.QueryRow(ctx, ContainerExistsQuery, id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, usecase.ContainerNotFoundErr
+	}
+
+	rows, err := r.dbPool.Query(ctx, ListContainerEventsQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []entity.ContainerEvent{}
+	for rows.Next() {
+		var event entity.ContainerEvent
+		if err = rows.Scan(&event.ContainerID, &event.Reason, &event.Message, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// createContainer inserts the container with its initial status.
+func createContainer(ctx context.Context, q querier, container *entity.Container) error {
+	if err := reserveCapacity(ctx, q, container); err != nil {
+		return err
+	}
+	if err := insertContainer(ctx, q, container); err != nil {
+		return err
+	}
+	if err := addStatusChange(ctx, q, container.ID, "", container.Status); err != nil {
+		return err
+	}
+	_, err := q.Exec(ctx, BumpNodeRevisionQuery, container.NodeID)
+	return err
+}
+
+// updateContainer locks the container, so q must be a transaction.
+func updateContainer(
+	ctx context.Context,
+	q querier,
+	id uuid.UUID,
+	mutate func(container *entity.Container) error,
+) (*entity.Container, error) {
+	var current entity.Container
+	err := scanContainer(q.QueryRow(ctx, LockContainerQuery, id), &current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ContainerNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updated := current
+	if err = mutate(&updated); err != nil {
+		return nil, err
+	}
+	updated.ID = current.ID
+	updated.ResourceVersion = current.ResourceVersion + 1
+	if updated.NodeID != current.NodeID || updated.Resources != current.Resources {
+		if err = reserveCapacity(ctx, q, &updated); err != nil {
+			return nil, err
+		}
+	}
+
+	spec, labels, placement, reasons, state, conditions, err := containerDocuments(&updated)
+	if err != nil {
+		return nil, err
+	}
+	ownerKind, ownerID := ownerColumns(updated.Owner)
+	_, err = q.Exec(
+		ctx,
+		UpdateContainerQuery,
+		updated.NodeID,
+		updated.Image,
+		updated.Status,
+		updated.Resources.CPU,
+		updated.Resources.Memory,
+		updated.Resources.Disk,
+		spec,
+		labels,
+		placement,
+		reasons,
+		updated.RescheduleReason,
+		ownerKind,
+		ownerID,
+		state,
+		conditions,
+		updated.ResourceVersion,
+		updated.ID,
+	)
+	if isForeignKeyViolation(err) {
+		return nil, usecase.NodeNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Status != updated.Status {
+		if err = addStatusChange(ctx, q, updated.ID, current.Status, updated.Status); err != nil {
+			return nil, err
+		}
+	}
+	if !current.DesiredStateChanged(&updated) {
+		return &updated, nil
+	}
+	if _, err = q.Exec(ctx, BumpNodeRevisionQuery, current.NodeID); err != nil {
+		return nil, err
+	}
+	if updated.NodeID != current.NodeID {
+		if _, err = q.Exec(ctx, BumpNodeRevisionQuery, updated.NodeID); err != nil {
+			return nil, err
+		}
+	}
+	return &updated, nil
+}
+
+func deleteContainer(ctx context.Context, q querier, id uuid.UUID) (*entity.Container, error) {
+	var container entity.Container
+	err := scanContainer(q.QueryRow(ctx, DeleteContainerQuery, id), &container)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ContainerNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err = q.Exec(ctx, BumpNodeRevisionQuery, container.NodeID); err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+// reserveCapacity locks the node of the container until the end of the
+// transaction and checks that the container fits next to the others on it,
+// so q must be a transaction.
+func reserveCapacity(ctx context.Context, q querier, container *entity.Container) error {
+	var capacity, allocated entity.Resources
+	err := q.QueryRow(ctx, LockNodeCapacityQuery, container.NodeID).Scan(&capacity.CPU, &capacity.Memory, &capacity.Disk)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return usecase.NodeNotFoundErr
+	}
+	if err != nil {
+		return err
+	}
+	err = q.QueryRow(ctx, NodeAllocatedQuery, container.NodeID, container.ID).
+		Scan(&allocated.CPU, &allocated.Memory, &allocated.Disk)
+	if err != nil {
+		return err
+	}
+	if !capacity.Fits(allocated, container.Resources) {
+		return fmt.Errorf("%w: node %s", usecase.InsufficientCapacityErr, container.NodeID)
+	}
+	return nil
+}
+
+func insertContainer(ctx context.Context, q querier, container *entity.Container) error {
+	spec, labels, placement, reasons, state, conditions, err := containerDocuments(container)
+	if err != nil {
+		return err
+	}
+	ownerKind, ownerID := ownerColumns(container.Owner)
+	_, err = q.Exec(
+		ctx,
+		AddContainerQuery,
+		container.ID,
+		container.NodeID,
+		container.Image,
+		container.Status,
+		container.Resources.CPU,
+		container.Resources.Memory,
+		container.Resources.Disk,
+		spec,
+		labels,
+		placement,
+		reasons,
+		container.RescheduleReason,
+		ownerKind,
+		ownerID,
+		state,
+		conditions,
+		container.ResourceVersion,
+	)
+	if isForeignKeyViolation(err) {
+		return usecase.NodeNotFoundErr
+	}
+	return err
+}
+
+// addStatusChange records a status change, from is empty for the initial status.
+func addStatusChange(ctx context.Context, q querier, id uuid.UUID, from, to entity.ContainerStatus) error {
+	fromStatus := sql.NullString{String: string(from), Valid: from != ""}
+	_, err := q.Exec(ctx, AddContainerStatusChangeQuery, id, fromStatus, to, time.Now().UTC())
+	return err
+}
@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"strings"
+)
+
+const (
+	deploymentColumns = "id, name, replicas, template, status"
+
+	GetDeploymentQuery    = "SELECT " + deploymentColumns + " FROM deployment WHERE id = $1"
+	LockDeploymentQuery   = GetDeploymentQuery + " FOR UPDATE"
+	ListDeploymentsQuery  = "SELECT " + deploymentColumns + " FROM deployment"
+	AddDeploymentQuery    = "INSERT INTO deployment (" + deploymentColumns + ") VALUES ($1, $2, $3, $4, $5)"
+	UpdateDeploymentQuery = "UPDATE deployment SET replicas = $1, template = $2, status = $3 WHERE id = $4"
+	DeleteDeploymentQuery = "DELETE FROM deployment WHERE id = $1"
+)
+
+var deploymentSortColumns = map[string]string{
+	"id":   "id",
+	"name": "name",
+}
+
+type DeploymentRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewDeploymentRepository(dbPool *pgxpool.Pool) *DeploymentRepository {
+	return &DeploymentRepository{dbPool: dbPool}
+}
+
+func (r *DeploymentRepository) Get(ctx context.Context, id uuid.UUID) (*entity.Deployment, error) {
+	var deployment entity.Deployment
+	err := scanDeployment(r.dbPool.QueryRow(ctx, GetDeploymentQuery, id), &deployment)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.DeploymentNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+func (r *DeploymentRepository) List(ctx context.Context, opts entity.ListOptions) ([]entity.Deployment, string, error) {
+	var b pagination.Builder
+	page, orderBy, limit, err := b.Page(opts, deploymentSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := strings.Join([]string{ListDeploymentsQuery, b.WhereClause(), orderBy, limit}, " ")
+	rows, err := r.dbPool.Query(ctx, query, b.Args()...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	deployments := []entity.Deployment{}
+	for rows.Next() {
+		var deployment entity.Deployment
+		if err = scanDeployment(rows, &deployment); err != nil {
+			return nil, "", err
+		}
+		deployments = append(deployments, deployment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if page.Limit > 0 && len(deployments) > page.Limit {
+		deployments = deployments[:page.Limit]
+		last := deployments[len(deployments)-1]
+		next = page.NextToken(pagination.DeploymentSortFields[page.SortBy](&last), last.ID.String())
+	}
+	return deployments, next, nil
+}
+
+func (r *DeploymentRepository) Create(ctx context.Context, deployment *entity.Deployment) error {
+	template, status, err := deploymentDocuments(deployment)
+	if err != nil {
+		return err
+	}
+	_, err = r.dbPool.Exec(
+		ctx,
+		AddDeploymentQuery,
+		deployment.ID,
+		deployment.Name,
+		deployment.Replicas,
+		template,
+		status,
+	)
+	if isUniqueViolation(err) {
+		return usecase.DeploymentExistsErr
+	}
+	return err
+}
+
+func (r *DeploymentRepository) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	mutate func(deployment *entity.Deployment) error,
+) (*entity.Deployment, error) {
+	tx, err := r.dbPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var current entity.Deployment
+	err = scanDeployment(tx.QueryRow(ctx, LockDeploymentQuery, id), &current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.DeploymentNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updated := current
+	if err = mutate(&updated); err != nil {
+		return nil, err
+	}
+	updated.ID = current.ID
+	updated.Name = current.Name
+
+	template, status, err := deploymentDocuments(&updated)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tx.Exec(ctx, UpdateDeploymentQuery, updated.Replicas, template, status, id); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *DeploymentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.dbPool.Exec(ctx, DeleteDeploymentQuery, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.DeploymentNotFoundErr
+	}
+	return nil
+}
+
+func scanDeployment(row pgx.Row, deployment *entity.Deployment) error {
+	var template, status []byte
+	err := row.Scan(&deployment.ID, &deployment.Name, &deployment.Replicas, &template, &status)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(template, &deployment.Template); err != nil {
+		return err
+	}
+	return json.Unmarshal(status, &deployment.Status)
+}
+
+// deploymentDocuments encodes the JSONB columns of a deployment.
+func deploymentDocuments(deployment *entity.Deployment) (template, status string, err error) {
+	rawTemplate, err := json.Marshal(deployment.Template)
+	if err != nil {
+		return
+	}
+	rawStatus, err := json.Marshal(deployment.Status)
+	return string(rawTemplate), string(rawStatus), err
+}
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"time"
+)
+
+const (
+	idempotencyColumns = "key, request_hash, response_status, response_header, response_body, created_at"
+
+	GetIdempotencyRecordQuery = "SELECT " + idempotencyColumns + " FROM idempotency_key WHERE key = $1"
+	AddIdempotencyRecordQuery = `
+		INSERT INTO idempotency_key (key, request_hash, created_at)
+		VALUES ($1, $2, $3)`
+	CompleteIdempotencyRecordQuery = `
+		UPDATE idempotency_key
+		SET response_status = $1, response_header = $2, response_body = $3
+		WHERE key = $4`
+	DeleteIdempotencyRecordQuery = "DELETE FROM idempotency_key WHERE key = $1"
+	PruneIdempotencyQuery        = "DELETE FROM idempotency_key WHERE created_at < $1"
+)
+
+type IdempotencyRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(dbPool *pgxpool.Pool) *IdempotencyRepository {
+	return &IdempotencyRepository{dbPool: dbPool}
+}
+
+func (r *IdempotencyRepository) Get(ctx context.Context, key string) (*entity.IdempotencyRecord, error) {
+	var record entity.IdempotencyRecord
+	var status sql.NullInt32
+	var header, body []byte
+	err := r.dbPool.QueryRow(ctx, GetIdempotencyRecordQuery, key).Scan(
+		&record.Key,
+		&record.RequestHash,
+		&status,
+		&header,
+		&body,
+		&record.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.IdempotencyKeyNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status.Valid {
+		record.Response = &entity.IdempotentResponse{Status: int(status.Int32), Body: body}
+		if err = json.Unmarshal(header, &record.Response.Header); err != nil {
+			return nil, err
+		}
+	}
+	return &record, nil
+}
+
+func (r *IdempotencyRepository) Create(ctx context.Context, record *entity.IdempotencyRecord) error {
+	_, err := r.dbPool.Exec(ctx, AddIdempotencyRecordQuery, record.Key, record.RequestHash, record.CreatedAt)
+	if isUniqueViolation(err) {
+		return usecase.IdempotencyKeyExistsErr
+	}
+	return err
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, key string, response *entity.IdempotentResponse) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+	tag, err := r.dbPool.Exec(ctx, CompleteIdempotencyRecordQuery, response.Status, header, response.Body, key)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.IdempotencyKeyNotFoundErr
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) Delete(ctx context.Context, key string) error {
+	_, err := r.dbPool.Exec(ctx, DeleteIdempotencyRecordQuery, key)
+	return err
+}
+
+func (r *IdempotencyRepository) Prune(ctx context.Context, olderThan time.Time) (int64, error) {
+	tag, err := r.dbPool.Exec(ctx, PruneIdempotencyQuery, olderThan)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,389 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"strings"
+)
+
+const (
+	jobColumns     = "id, name, spec, status, owner_kind, owner_id, created_at"
+	cronJobColumns = "id, name, schedule, concurrency_policy, suspend, successful_jobs_history_limit, " +
+		"failed_jobs_history_limit, job_template, status, created_at"
+
+	GetJobQuery    = "SELECT " + jobColumns + " FROM job WHERE id = $1"
+	LockJobQuery   = GetJobQuery + " FOR UPDATE"
+	ListJobsQuery  = "SELECT " + jobColumns + " FROM job"
+	AddJobQuery    = "INSERT INTO job (" + jobColumns + ") VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	UpdateJobQuery = "UPDATE job SET status = $1 WHERE id = $2"
+	DeleteJobQuery = "DELETE FROM job WHERE id = $1"
+
+	GetCronJobQuery    = "SELECT " + cronJobColumns + " FROM cronjob WHERE id = $1"
+	LockCronJobQuery   = GetCronJobQuery + " FOR UPDATE"
+	ListCronJobsQuery  = "SELECT " + cronJobColumns + " FROM cronjob"
+	AddCronJobQuery    = "INSERT INTO cronjob (" + cronJobColumns + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
+	UpdateCronJobQuery = `
+		UPDATE cronjob
+		SET schedule = $1, concurrency_policy = $2, suspend = $3, successful_jobs_history_limit = $4,
+		    failed_jobs_history_limit = $5, job_template = $6, status = $7
+		WHERE id = $8`
+	DeleteCronJobQuery = "DELETE FROM cronjob WHERE id = $1"
+)
+
+var (
+	jobSortColumns = map[string]string{
+		"id":   "id",
+		"name": "name",
+	}
+	cronJobSortColumns = map[string]string{
+		"id":   "id",
+		"name": "name",
+	}
+)
+
+type JobRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewJobRepository(dbPool *pgxpool.Pool) *JobRepository {
+	return &JobRepository{dbPool: dbPool}
+}
+
+func (r *JobRepository) Get(ctx context.Context, id uuid.UUID) (*entity.Job, error) {
+	var job entity.Job
+	err := scanJob(r.dbPool.QueryRow(ctx, GetJobQuery, id), &job)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.JobNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *JobRepository) List(ctx context.Context, opts entity.ListJobsOptions) ([]entity.Job, string, error) {
+	var b pagination.Builder
+	if opts.OwnerID != uuid.Nil {
+		b.Where("owner_id = %s", opts.OwnerID)
+	}
+	page, orderBy, limit, err := b.Page(opts.ListOptions, jobSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := strings.Join([]string{ListJobsQuery, b.WhereClause(), orderBy, limit}, " ")
+	rows, err := r.dbPool.Query(ctx, query, b.Args()...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	jobs := []entity.Job{}
+	for rows.Next() {
+		var job entity.Job
+		if err = scanJob(rows, &job); err != nil {
+			return nil, "", err
+		}
+		jobs = append(jobs, job)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if page.Limit > 0 && len(jobs) > page.Limit {
+		jobs = jobs[:page.Limit]
+		last := jobs[len(jobs)-1]
+		next = page.NextToken(pagination.JobSortFields[page.SortBy](&last), last.ID.String())
+	}
+	return jobs, next, nil
+}
+
+func (r *JobRepository) Create(ctx context.Context, job *entity.Job) error {
+	spec, err := json.Marshal(job.Spec)
+	if err != nil {
+		return err
+	}
+	status, err := marshalJobStatus(job.Status)
+	if err != nil {
+		return err
+	}
+	ownerKind, ownerID := ownerColumns(job.Owner)
+	_, err = r.dbPool.Exec(ctx, AddJobQuery, job.ID, job.Name, string(spec), status, ownerKind, ownerID, job.CreatedAt)
+	if isUniqueViolation(err) {
+		return usecase.JobExistsErr
+	}
+	return err
+}
+
+func (r *JobRepository) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	mutate func(job *entity.Job) error,
+) (*entity.Job, error) {
+	tx, err := r.dbPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var current entity.Job
+	err = scanJob(tx.QueryRow(ctx, LockJobQuery, id), &current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.JobNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updated := current
+	updated.Status.Runs = append([]entity.JobRun{}, current.Status.Runs...)
+	if err = mutate(&updated); err != nil {
+		return nil, err
+	}
+	// Only the status of a job can change.
+	current.Status = updated.Status
+
+	status, err := marshalJobStatus(current.Status)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tx.Exec(ctx, UpdateJobQuery, status, id); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &current, nil
+}
+
+func (r *JobRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.dbPool.Exec(ctx, DeleteJobQuery, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.JobNotFoundErr
+	}
+	return nil
+}
+
+type CronJobRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewCronJobRepository(dbPool *pgxpool.Pool) *CronJobRepository {
+	return &CronJobRepository{dbPool: dbPool}
+}
+
+func (r *CronJobRepository) Get(ctx context.Context, id uuid.UUID) (*entity.CronJob, error) {
+	var cronJob entity.CronJob
+	err := scanCronJob(r.dbPool.QueryRow(ctx, GetCronJobQuery, id), &cronJob)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.CronJobNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cronJob, nil
+}
+
+func (r *CronJobRepository) List(ctx context.Context, opts entity.ListOptions) ([]entity.CronJob, string, error) {
+	var b pagination.Builder
+	page, orderBy, limit, err := b.Page(opts, cronJobSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := strings.Join([]string{ListCronJobsQuery, b.WhereClause(), orderBy, limit}, " ")
+	rows, err := r.dbPool.Query(ctx, query, b.Args()...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	cronJobs := []entity.CronJob{}
+	for rows.Next() {
+		var cronJob entity.CronJob
+		if err = scanCronJob(rows, &cronJob); err != nil {
+			return nil, "", err
+		}
+		cronJobs = append(cronJobs, cronJob)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if page.Limit > 0 && len(cronJobs) > page.Limit {
+		cronJobs = cronJobs[:page.Limit]
+		last := cronJobs[len(cronJobs)-1]
+		next = page.NextToken(pagination.CronJobSortFields[page.SortBy](&last), last.ID.String())
+	}
+	return cronJobs, next, nil
+}
+
+func (r *CronJobRepository) Create(ctx context.Context, cronJob *entity.CronJob) error {
+	template, status, err := cronJobDocuments(cronJob)
+	if err != nil {
+		return err
+	}
+	_, err = r.dbPool.Exec(
+		ctx,
+		AddCronJobQuery,
+		cronJob.ID,
+		cronJob.Name,
+		cronJob.Schedule,
+		cronJob.ConcurrencyPolicy,
+		cronJob.Suspend,
+		cronJob.SuccessfulJobsHistoryLimit,
+		cronJob.FailedJobsHistoryLimit,
+		template,
+		status,
+		cronJob.CreatedAt,
+	)
+	if isUniqueViolation(err) {
+		return usecase.CronJobExistsErr
+	}
+	return err
+}
+
+func (r *CronJobRepository) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	mutate func(cronJob *entity.CronJob) error,
+) (*entity.CronJob, error) {
+	tx, err := r.dbPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var current entity.CronJob
+	err = scanCronJob(tx.QueryRow(ctx, LockCronJobQuery, id), &current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.CronJobNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updated := current
+	updated.Status.Active = append([]uuid.UUID{}, current.Status.Active...)
+	if err = mutate(&updated); err != nil {
+		return nil, err
+	}
+	updated.ID = current.ID
+	updated.Name = current.Name
+	updated.CreatedAt = current.CreatedAt
+
+	template, status, err := cronJobDocuments(&updated)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(
+		ctx,
+		UpdateCronJobQuery,
+		updated.Schedule,
+		updated.ConcurrencyPolicy,
+		updated.Suspend,
+		updated.SuccessfulJobsHistoryLimit,
+		updated.FailedJobsHistoryLimit,
+		template,
+		status,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *CronJobRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.dbPool.Exec(ctx, DeleteCronJobQuery, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.CronJobNotFoundErr
+	}
+	return nil
+}
+
+func scanJob(row pgx.Row, job *entity.Job) error {
+	var spec, status []byte
+	var ownerKind, ownerID sql.NullString
+	err := row.Scan(&job.ID, &job.Name, &spec, &status, &ownerKind, &ownerID, &job.CreatedAt)
+	if err != nil {
+		return err
+	}
+	job.CreatedAt = job.CreatedAt.UTC()
+	if ownerKind.Valid {
+		job.Owner = &entity.OwnerReference{Kind: ownerKind.String}
+		if job.Owner.ID, err = uuid.Parse(ownerID.String); err != nil {
+			return err
+		}
+	}
+	if err = json.Unmarshal(spec, &job.Spec); err != nil {
+		return err
+	}
+	return json.Unmarshal(status, &job.Status)
+}
+
+func scanCronJob(row pgx.Row, cronJob *entity.CronJob) error {
+	var template, status []byte
+	err := row.Scan(
+		&cronJob.ID,
+		&cronJob.Name,
+		&cronJob.Schedule,
+		&cronJob.ConcurrencyPolicy,
+		&cronJob.Suspend,
+		&cronJob.SuccessfulJobsHistoryLimit,
+		&cronJob.FailedJobsHistoryLimit,
+		&template,
+		&status,
+		&cronJob.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	cronJob.CreatedAt = cronJob.CreatedAt.UTC()
+	if err = json.Unmarshal(template, &cronJob.JobTemplate); err != nil {
+		return err
+	}
+	return json.Unmarshal(status, &cronJob.Status)
+}
+
+// marshalJobStatus encodes the status of a job, nil runs are stored as an empty array.
+func marshalJobStatus(status entity.JobStatus) (string, error) {
+	if status.Runs == nil {
+		status.Runs = []entity.JobRun{}
+	}
+	raw, err := json.Marshal(status)
+	return string(raw), err
+}
+
+// cronJobDocuments encodes the JSONB columns of a cron job.
+func cronJobDocuments(cronJob *entity.CronJob) (template, status string, err error) {
+	rawTemplate, err := json.Marshal(cronJob.JobTemplate)
+	if err != nil {
+		return
+	}
+	cronStatus := cronJob.Status
+	if cronStatus.Active == nil {
+		cronStatus.Active = []uuid.UUID{}
+	}
+	rawStatus, err := json.Marshal(cronStatus)
+	return string(rawTemplate), string(rawStatus), err
+}
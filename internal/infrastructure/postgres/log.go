@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	AddLogEntryQuery = `
+		INSERT INTO container_log (container_id, stream, logged_at, line)
+		VALUES ($1, $2, $3, $4)
+		RETURNING seq`
+	ListLogEntriesQuery = "SELECT seq, stream, logged_at, line FROM container_log"
+	PruneOldLogsQuery   = "DELETE FROM container_log WHERE logged_at < $1"
+	PruneExcessLogQuery = `
+		DELETE FROM container_log
+		WHERE seq IN (
+			SELECT seq
+			FROM (
+				SELECT seq, row_number() OVER (PARTITION BY container_id ORDER BY seq DESC) AS position
+				FROM container_log
+			) ranked
+			WHERE position > $1
+		)`
+)
+
+type ContainerLogRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewContainerLogRepository(dbPool *pgxpool.Pool) *ContainerLogRepository {
+	return &ContainerLogRepository{dbPool: dbPool}
+}
+
+func (r *ContainerLogRepository) Append(ctx context.Context, containerID uuid.UUID, entries []entity.LogEntry) error {
+	tx, err := r.dbPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for i := range entries {
+		entry := &entries[i]
+		err = tx.QueryRow(ctx, AddLogEntryQuery, containerID, entry.Stream, entry.Timestamp, entry.Line).Scan(&entry.Seq)
+		if isForeignKeyViolation(err) {
+			return usecase.ContainerNotFoundErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *ContainerLogRepository) List(ctx context.Context, containerID uuid.UUID, opts entity.LogOptions) ([]entity.LogEntry, error) {
+	var exists bool
+	if err := r.dbPool.QueryRow(ctx, ContainerExistsQuery, containerID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, usecase.ContainerNotFoundErr
+	}
+
+	var b pagination.Builder
+	b.Where("container_id = %s", containerID)
+	if opts.AfterSeq > 0 {
+		b.Where("seq > %s", opts.AfterSeq)
+	}
+	if !opts.Since.IsZero() {
+		b.Where("logged_at >= %s", opts.Since)
+	}
+	// The tail is selected newest first and put back in order below.
+	orderBy := "ORDER BY seq"
+	if opts.Tail > 0 {
+		orderBy = "ORDER BY seq DESC LIMIT " + strconv.Itoa(opts.Tail)
+	}
+
+	query := strings.Join([]string{ListLogEntriesQuery, b.WhereClause(), orderBy}, " ")
+	rows, err := r.dbPool.Query(ctx, query, b.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []entity.LogEntry{}
+	for rows.Next() {
+		var entry entity.LogEntry
+		if err = rows.Scan(&entry.Seq, &entry.Stream, &entry.Timestamp, &entry.Line); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if opts.Tail > 0 {
+		slices.Reverse(entries)
+	}
+	return entries, nil
+}
+
+func (r *ContainerLogRepository) Prune(ctx context.Context, olderThan time.Time, maxEntries int) (int64, error) {
+	old, err := r.dbPool.Exec(ctx, PruneOldLogsQuery, olderThan)
+	if err != nil {
+		return 0, err
+	}
+	excess, err := r.dbPool.Exec(ctx, PruneExcessLogQuery, maxEntries)
+	if err != nil {
+		return 0, err
+	}
+	return old.RowsAffected() + excess.RowsAffected(), nil
+}
@@ -0,0 +1,426 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"time"
+)
+
+const (
+	nodeColumns = "id, status, last_seen, cpu_capacity, memory_capacity, disk_capacity, revision, labels, taints, " +
+		"unschedulable, resource_version"
+
+	GetNodeQuery  = "SELECT " + nodeColumns + " FROM node WHERE id = $1"
+	LockNodeQuery = GetNodeQuery + " FOR UPDATE"
+	// ListNodesQuery is completed with the WHERE, ORDER BY and LIMIT clauses.
+	ListNodesQuery             = "SELECT " + nodeColumns + " FROM node %s %s %s"
+	ListContainersOfNodesQuery = "SELECT " + containerColumns + " FROM container WHERE node_id = ANY($1) ORDER BY id"
+	AddNodeQuery               = `
+		INSERT INTO node(id, status, cpu_capacity, memory_capacity, disk_capacity, labels, taints, unschedulable,
+		                 resource_version)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	UpdateNodeQuery = `
+		UPDATE node
+		SET status = $1, cpu_capacity = $2, memory_capacity = $3, disk_capacity = $4, labels = $5, taints = $6,
+		    unschedulable = $7, resource_version = $8, last_seen = COALESCE($9, last_seen)
+		WHERE id = $10`
+	DeleteNodeQuery                = "DELETE FROM node WHERE id = $1"
+	HeartbeatNodeQuery             = "UPDATE node SET last_seen = $1 WHERE id = $2"
+	HeartbeatNodeWithCapacityQuery = `
+		UPDATE node
+		SET last_seen = $1, cpu_capacity = $2, memory_capacity = $3, disk_capacity = $4,
+		    resource_version = node.resource_version + CASE
+		        WHEN (old.cpu_capacity, old.memory_capacity, old.disk_capacity) = ($2, $3, $4) THEN 0 ELSE 1
+		    END
+		FROM node AS old
+		WHERE node.id = $5 AND old.id = node.id
+		RETURNING node.resource_version <> old.resource_version`
+	FailStaleNodesQuery = `
+		UPDATE node SET status = $1, resource_version = resource_version + 1
+		WHERE status = $2 AND (last_seen IS NULL OR last_seen < $3)
+		RETURNING id`
+	RecoverNodesQuery = `
+		UPDATE node SET status = $1, resource_version = resource_version + 1
+		WHERE status IN ($2, $3) AND last_seen >= $4
+		RETURNING id`
+	GetNodeRevisionQuery = "SELECT revision FROM node WHERE id = $1"
+)
+
+var nodeSortColumns = map[string]string{
+	"id":     "id",
+	"status": "status",
+}
+
+type NodeRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewNodeRepository(dbPool *pgxpool.Pool) *NodeRepository {
+	return &NodeRepository{dbPool: dbPool}
+}
+
+func (r *NodeRepository) Get(ctx context.Context, id uuid.UUID) (*entity.Node, error) {
+	tx, err := r.dbPool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var node entity.Node
+	err = scanNode(tx.QueryRow(ctx, GetNodeQuery, id), &node)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.NodeNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = fillContainers(ctx, tx, []*entity.Node{&node}); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+func (r *NodeRepository) List(ctx context.Context, opts entity.ListNodesOptions) ([]*entity.Node, string, error) {
+	var b pagination.Builder
+	if opts.Status != "" {
+		b.Where("status = %s", opts.Status)
+	}
+	whereLabels(&b, opts.LabelSelector)
+	page, orderBy, limit, err := b.Page(opts.ListOptions, nodeSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tx, err := r.dbPool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, "", err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(ListNodesQuery, b.WhereClause(), orderBy, limit), b.Args()...)
+	if err != nil {
+		return nil, "", err
+	}
+	nodes := []*entity.Node{}
+	for rows.Next() {
+		var node entity.Node
+		if err = scanNode(rows, &node); err != nil {
+			rows.Close()
+			return nil, "", err
+		}
+		nodes = append(nodes, &node)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if page.Limit > 0 && len(nodes) > page.Limit {
+		nodes = nodes[:page.Limit]
+		last := nodes[len(nodes)-1]
+		next = page.NextToken(pagination.NodeSortFields[page.SortBy](last), last.ID.String())
+	}
+
+	if err = fillContainers(ctx, tx, nodes); err != nil {
+		return nil, "", err
+	}
+	return nodes, next, nil
+}
+
+func (r *NodeRepository) Create(ctx context.Context, node *entity.Node) error {
+	return createNode(ctx, r.dbPool, node)
+}
+
+func (r *NodeRepository) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	mutate func(node *entity.Node) error,
+) (*entity.Node, error) {
+	tx, err := r.dbPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	node, err := updateNode(ctx, tx, id, mutate)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (r *NodeRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return deleteNode(ctx, r.dbPool, id)
+}
+
+func (r *NodeRepository) CreateBatch(ctx context.Context, nodes []*entity.Node, atomic bool) ([]error, error) {
+	return runBatch(ctx, r.dbPool, len(nodes), atomic, func(q querier, i int) error {
+		return createNode(ctx, q, nodes[i])
+	})
+}
+
+func (r *NodeRepository) UpdateBatch(
+	ctx context.Context,
+	ids []uuid.UUID,
+	mutate func(i int, node *entity.Node) error,
+	atomic bool,
+) ([]*entity.Node, []error, error) {
+	updated := make([]*entity.Node, len(ids))
+	errs, err := runBatch(ctx, r.dbPool, len(ids), atomic, func(q querier, i int) (err error) {
+		updated[i], err = updateNode(ctx, q, ids[i], func(node *entity.Node) error {
+			return mutate(i, node)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return batchResults(updated, errs), errs, nil
+}
+
+func (r *NodeRepository) DeleteBatch(ctx context.Context, ids []uuid.UUID, atomic bool) ([]error, error) {
+	return runBatch(ctx, r.dbPool, len(ids), atomic, func(q querier, i int) error {
+		return deleteNode(ctx, q, ids[i])
+	})
+}
+
+func (r *NodeRepository) Heartbeat(ctx context.Context, id uuid.UUID, seenAt time.Time, capacity *entity.Resources) (bool, error) {
+	if capacity == nil {
+		tag, err := r.dbPool.Exec(ctx, HeartbeatNodeQuery, seenAt, id)
+		if err != nil {
+			return false, err
+		}
+		if tag.RowsAffected() == 0 {
+			return false, usecase.NodeNotFoundErr
+		}
+		return false, nil
+	}
+
+	var changed bool
+	err := r.dbPool.QueryRow(ctx, HeartbeatNodeWithCapacityQuery,
+		seenAt, capacity.CPU, capacity.Memory, capacity.Disk, id).Scan(&changed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, usecase.NodeNotFoundErr
+	}
+	return changed, err
+}
+
+func (r *NodeRepository) FailStale(ctx context.Context, deadline time.Time) ([]uuid.UUID, error) {
+	return r.updateStatuses(ctx, FailStaleNodesQuery, entity.FailedNodeStatus, entity.RunningNodeStatus, deadline)
+}
+
+func (r *NodeRepository) Recover(ctx context.Context, deadline time.Time) ([]uuid.UUID, error) {
+	return r.updateStatuses(
+		ctx,
+		RecoverNodesQuery,
+		entity.RunningNodeStatus,
+		entity.NewNodeStatus,
+		entity.FailedNodeStatus,
+		deadline,
+	)
+}
+
+func (r *NodeRepository) GetDesiredState(ctx context.Context, id uuid.UUID) (*entity.NodeDesiredState, error) {
+	tx, err := r.dbPool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	state := entity.NodeDesiredState{NodeID: id}
+	err = tx.QueryRow(ctx, GetNodeRevisionQuery, id).Scan(&state.Revision)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.NodeNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, ListContainersOfNodesQuery, []string{id.String()})
+	if err != nil {
+		return nil, err
+	}
+	state.Containers, err = scanContainers(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// updateStatuses runs a status update query returning the ids of the changed nodes.
+func (r *NodeRepository) updateStatuses(ctx context.Context, query string, args ...interface{}) ([]uuid.UUID, error) {
+	rows, err := r.dbPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func createNode(ctx context.Context, q querier, node *entity.Node) error {
+	labels, err := marshalLabels(node.Labels)
+	if err != nil {
+		return err
+	}
+	taints, err := marshalTaints(node.Taints)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(
+		ctx,
+		AddNodeQuery,
+		node.ID,
+		node.Status,
+		node.Capacity.CPU,
+		node.Capacity.Memory,
+		node.Capacity.Disk,
+		labels,
+		taints,
+		node.Unschedulable,
+		node.ResourceVersion,
+	)
+	return err
+}
+
+// updateNode locks the node, so q must be a transaction.
+func updateNode(ctx context.Context, q querier, id uuid.UUID, mutate func(node *entity.Node) error) (*entity.Node, error) {
+	var node entity.Node
+	err := scanNode(q.QueryRow(ctx, LockNodeQuery, id), &node)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.NodeNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = fillContainers(ctx, q, []*entity.Node{&node}); err != nil {
+		return nil, err
+	}
+
+	version, status := node.ResourceVersion, node.Status
+	if err = mutate(&node); err != nil {
+		return nil, err
+	}
+	node.ID = id
+	node.ResourceVersion = version + 1
+	var seenAt *time.Time
+	if status != entity.RunningNodeStatus && node.Status == entity.RunningNodeStatus {
+		// Nodes moved to running are not failed before their first heartbeat is due.
+		now := time.Now().UTC()
+		seenAt, node.LastSeen = &now, &now
+	}
+
+	labels, err := marshalLabels(node.Labels)
+	if err != nil {
+		return nil, err
+	}
+	taints, err := marshalTaints(node.Taints)
+	if err != nil {
+		return nil, err
+	}
+	_, err = q.Exec(
+		ctx,
+		UpdateNodeQuery,
+		node.Status,
+		node.Capacity.CPU,
+		node.Capacity.Memory,
+		node.Capacity.Disk,
+		labels,
+		taints,
+		node.Unschedulable,
+		node.ResourceVersion,
+		seenAt,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+func deleteNode(ctx context.Context, q querier, id uuid.UUID) error {
+	tag, err := q.Exec(ctx, DeleteNodeQuery, id)
+	if isForeignKeyViolation(err) {
+		return usecase.NodeHasContainersErr
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.NodeNotFoundErr
+	}
+	return nil
+}
+
+// fillContainers loads the containers assigned to the nodes.
+func fillContainers(ctx context.Context, q querier, nodes []*entity.Node) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	ids := make([]string, len(nodes))
+	byID := make(map[uuid.UUID]*entity.Node, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID.String()
+		byID[node.ID] = node
+		node.Containers = []entity.Container{}
+	}
+
+	rows, err := q.Query(ctx, ListContainersOfNodesQuery, ids)
+	if err != nil {
+		return err
+	}
+	containers, err := scanContainers(rows)
+	if err != nil {
+		return err
+	}
+	for _, container := range containers {
+		node := byID[container.NodeID]
+		node.Containers = append(node.Containers, container)
+	}
+	return nil
+}
+
+func scanNode(row pgx.Row, node *entity.Node) error {
+	var labels, taints []byte
+	err := row.Scan(
+		&node.ID,
+		&node.Status,
+		&node.LastSeen,
+		&node.Capacity.CPU,
+		&node.Capacity.Memory,
+		&node.Capacity.Disk,
+		&node.Revision,
+		&labels,
+		&taints,
+		&node.Unschedulable,
+		&node.ResourceVersion,
+	)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(labels, &node.Labels); err != nil {
+		return err
+	}
+	return json.Unmarshal(taints, &node.Taints)
+}
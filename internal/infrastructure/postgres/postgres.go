@@ -0,0 +1,252 @@
+// Package postgres implements the usecase repositories on top of PostgreSQL.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"strings"
+)
+
+const (
+	foreignKeyViolationCode = "23503"
+	uniqueViolationCode     = "23505"
+
+	containerColumns = "id, node_id, image, status, cpu_request, memory_request, disk_request, spec, labels, " +
+		"placement, placement_reasons, reschedule_reason, owner_kind, owner_id, state, conditions, resource_version"
+
+	BumpNodeRevisionQuery = "UPDATE node SET revision = revision + 1 WHERE id = $1"
+)
+
+// querier is implemented by connections and transactions alike.
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// runBatch calls apply for every item of a batch in one transaction, each
+// item within its own savepoint so that a failed item leaves the others
+// intact. When atomic the first failure rolls back the whole transaction.
+// Item errors are returned per item, the error is for the batch itself.
+func runBatch(
+	ctx context.Context,
+	dbPool *pgxpool.Pool,
+	items int,
+	atomic bool,
+	apply func(q querier, i int) error,
+) ([]error, error) {
+	tx, err := dbPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	errs := make([]error, items)
+	for i := range errs {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if errs[i] = apply(savepoint, i); errs[i] != nil {
+			if atomic {
+				return usecase.AbortBatch(errs, i), nil
+			}
+			if err = savepoint.Rollback(ctx); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err = savepoint.Commit(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// batchResults drops the results of the items that failed or were rolled back.
+func batchResults[T any](results []*T, errs []error) []*T {
+	for i := range results {
+		if errs[i] != nil {
+			results[i] = nil
+		}
+	}
+	return results
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+// scanContainer scans a row selected with containerColumns.
+func scanContainer(row pgx.Row, container *entity.Container) error {
+	var spec, labels, placement, reasons, state, conditions []byte
+	var ownerKind, ownerID sql.NullString
+	err := row.Scan(
+		&container.ID,
+		&container.NodeID,
+		&container.Image,
+		&container.Status,
+		&container.Resources.CPU,
+		&container.Resources.Memory,
+		&container.Resources.Disk,
+		&spec,
+		&labels,
+		&placement,
+		&reasons,
+		&container.RescheduleReason,
+		&ownerKind,
+		&ownerID,
+		&state,
+		&conditions,
+		&container.ResourceVersion,
+	)
+	if err != nil {
+		return err
+	}
+	if ownerKind.Valid {
+		container.Owner = &entity.OwnerReference{Kind: ownerKind.String}
+		if container.Owner.ID, err = uuid.Parse(ownerID.String); err != nil {
+			return err
+		}
+	}
+	if err = json.Unmarshal(spec, &container.Spec); err != nil {
+		return err
+	}
+	if err = json.Unmarshal(labels, &container.Labels); err != nil {
+		return err
+	}
+	if err = json.Unmarshal(placement, &container.Placement); err != nil {
+		return err
+	}
+	if err = json.Unmarshal(state, &container.State); err != nil {
+		return err
+	}
+	if err = json.Unmarshal(conditions, &container.Conditions); err != nil {
+		return err
+	}
+	return json.Unmarshal(reasons, &container.PlacementReasons)
+}
+
+func scanContainers(rows pgx.Rows) ([]entity.Container, error) {
+	defer rows.Close()
+
+	containers := []entity.Container{}
+	for rows.Next() {
+		var container entity.Container
+		if err := scanContainer(rows, &container); err != nil {
+			return nil, err
+		}
+		containers = append(containers, container)
+	}
+	return containers, rows.Err()
+}
+
+// marshalLabels encodes labels for a JSONB column, nil labels are stored as an empty object.
+func marshalLabels(labels entity.Labels) (string, error) {
+	if labels == nil {
+		labels = entity.Labels{}
+	}
+	raw, err := json.Marshal(labels)
+	return string(raw), err
+}
+
+// marshalTaints encodes taints for a JSONB column, nil taints are stored as an empty array.
+func marshalTaints(taints []entity.Taint) (string, error) {
+	if taints == nil {
+		taints = []entity.Taint{}
+	}
+	raw, err := json.Marshal(taints)
+	return string(raw), err
+}
+
+// ownerColumns returns the owner_kind and owner_id values of owner, both NULL when it is nil.
+func ownerColumns(owner *entity.OwnerReference) (kind, id sql.NullString) {
+	if owner == nil {
+		return
+	}
+	return sql.NullString{String: owner.Kind, Valid: true}, sql.NullString{String: owner.ID.String(), Valid: true}
+}
+
+// containerDocuments encodes the JSONB columns of a container.
+func containerDocuments(container *entity.Container) (spec, labels, placement, reasons, state, conditions string, err error) {
+	rawSpec, err := json.Marshal(container.Spec)
+	if err != nil {
+		return
+	}
+	if labels, err = marshalLabels(container.Labels); err != nil {
+		return
+	}
+	rawPlacement, err := json.Marshal(container.Placement)
+	if err != nil {
+		return
+	}
+	placementReasons := container.PlacementReasons
+	if placementReasons == nil {
+		placementReasons = []string{}
+	}
+	rawReasons, err := json.Marshal(placementReasons)
+	if err != nil {
+		return
+	}
+	rawState, err := json.Marshal(container.State)
+	if err != nil {
+		return
+	}
+	containerConditions := container.Conditions
+	if containerConditions == nil {
+		containerConditions = []entity.ContainerCondition{}
+	}
+	rawConditions, err := json.Marshal(containerConditions)
+	return string(rawSpec), labels, string(rawPlacement), string(rawReasons), string(rawState), string(rawConditions), err
+}
+
+// whereLabels adds a condition on the labels column for every requirement of selector.
+func whereLabels(b *pagination.Builder, selector entity.LabelSelector) {
+	for _, requirement := range selector {
+		switch requirement.Operator {
+		case entity.SelectorEquals:
+			b.Where("labels @> %s::jsonb", labelPair(requirement.Key, requirement.Values[0]))
+		case entity.SelectorNotEquals:
+			b.Where("NOT labels @> %s::jsonb", labelPair(requirement.Key, requirement.Values[0]))
+		case entity.SelectorIn, entity.SelectorNotIn:
+			conditions := make([]string, len(requirement.Values))
+			pairs := make([]interface{}, len(requirement.Values))
+			for i, value := range requirement.Values {
+				conditions[i] = "labels @> %s::jsonb"
+				pairs[i] = labelPair(requirement.Key, value)
+			}
+			format := "(" + strings.Join(conditions, " OR ") + ")"
+			if requirement.Operator == entity.SelectorNotIn {
+				format = "NOT " + format
+			}
+			b.Where(format, pairs...)
+		case entity.SelectorExists:
+			b.Where("labels ? %s", requirement.Key)
+		case entity.SelectorDoesNotExist:
+			b.Where("NOT labels ? %s", requirement.Key)
+		}
+	}
+}
+
+func labelPair(key, value string) string {
+	raw, _ := json.Marshal(map[string]string{key: value})
+	return string(raw)
+}
@@ -0,0 +1,43 @@
+package postgres_test
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/postgres"
+	"github.com/wensiet/morchy-api/internal/infrastructure/repotest"
+	"os"
+	"testing"
+)
+
+// TestRepositories runs against the migrated database in TEST_DATABASE_URL,
+// every table is truncated before each test.
+func TestRepositories(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	conf, err := pgxpool.ParseConfig(url)
+	require.NoError(t, err)
+	conf.ConnConfig.PreferSimpleProtocol = true
+	pool, err := pgxpool.ConnectConfig(ctx, conf)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		_, err := pool.Exec(ctx, "TRUNCATE idempotency_key, container_log, container_event, container_status_history, container, node, deployment, rollout_container, rollout, job, cronjob")
+		require.NoError(t, err)
+		return repotest.Repositories{
+			Nodes:       postgres.NewNodeRepository(pool),
+			Containers:  postgres.NewContainerRepository(pool),
+			Deployments: postgres.NewDeploymentRepository(pool),
+			Rollouts:    postgres.NewRolloutRepository(pool),
+			Jobs:        postgres.NewJobRepository(pool),
+			CronJobs:    postgres.NewCronJobRepository(pool),
+			Logs:        postgres.NewContainerLogRepository(pool),
+			Idempotency: postgres.NewIdempotencyRepository(pool),
+		}
+	})
+}
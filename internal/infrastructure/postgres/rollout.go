@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"strings"
+)
+
+const (
+	rolloutColumns = "id, from_image, to_image, batch_size, max_unavailable, phase, paused, message, " +
+		"containers, updated, reverted, created_at"
+
+	GetRolloutQuery    = "SELECT " + rolloutColumns + " FROM rollout WHERE id = $1"
+	LockRolloutQuery   = GetRolloutQuery + " FOR UPDATE"
+	ListRolloutsQuery  = "SELECT " + rolloutColumns + " FROM rollout"
+	AddRolloutQuery    = "INSERT INTO rollout (" + rolloutColumns + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)"
+	UpdateRolloutQuery = `
+		UPDATE rollout
+		SET batch_size = $1, max_unavailable = $2, phase = $3, paused = $4, message = $5,
+		    containers = $6, updated = $7, reverted = $8
+		WHERE id = $9`
+	AddRolloutContainersQuery = `
+		INSERT INTO rollout_container (rollout_id, container_id, active)
+		SELECT $1::text, unnest($2::text[]), $3::boolean`
+	ActivateRolloutContainersQuery = "UPDATE rollout_container SET active = $2 WHERE rollout_id = $1"
+)
+
+var rolloutSortColumns = map[string]string{
+	"id":    "id",
+	"phase": "phase",
+}
+
+type RolloutRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewRolloutRepository(dbPool *pgxpool.Pool) *RolloutRepository {
+	return &RolloutRepository{dbPool: dbPool}
+}
+
+func (r *RolloutRepository) Get(ctx context.Context, id uuid.UUID) (*entity.Rollout, error) {
+	var rollout entity.Rollout
+	err := scanRollout(r.dbPool.QueryRow(ctx, GetRolloutQuery, id), &rollout)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.RolloutNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rollout, nil
+}
+
+func (r *RolloutRepository) List(ctx context.Context, opts entity.ListOptions) ([]entity.Rollout, string, error) {
+	var b pagination.Builder
+	page, orderBy, limit, err := b.Page(opts, rolloutSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := strings.Join([]string{ListRolloutsQuery, b.WhereClause(), orderBy, limit}, " ")
+	rows, err := r.dbPool.Query(ctx, query, b.Args()...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	rollouts := []entity.Rollout{}
+	for rows.Next() {
+		var rollout entity.Rollout
+		if err = scanRollout(rows, &rollout); err != nil {
+			return nil, "", err
+		}
+		rollouts = append(rollouts, rollout)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if page.Limit > 0 && len(rollouts) > page.Limit {
+		rollouts = rollouts[:page.Limit]
+		last := rollouts[len(rollouts)-1]
+		next = page.NextToken(pagination.RolloutSortFields[page.SortBy](&last), last.ID.String())
+	}
+	return rollouts, next, nil
+}
+
+func (r *RolloutRepository) Create(ctx context.Context, rollout *entity.Rollout) error {
+	containers, updated, reverted, err := rolloutDocuments(rollout)
+	if err != nil {
+		return err
+	}
+	tx, err := r.dbPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(
+		ctx,
+		AddRolloutQuery,
+		rollout.ID,
+		rollout.FromImage,
+		rollout.ToImage,
+		rollout.BatchSize,
+		rollout.MaxUnavailable,
+		rollout.Phase,
+		rollout.Paused,
+		rollout.Message,
+		containers,
+		updated,
+		reverted,
+		rollout.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(rollout.Containers))
+	for i, id := range rollout.Containers {
+		ids[i] = id.String()
+	}
+	_, err = tx.Exec(ctx, AddRolloutContainersQuery, rollout.ID, ids, rollout.Phase.IsActive())
+	if isUniqueViolation(err) {
+		return usecase.RolloutConflictErr
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *RolloutRepository) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	mutate func(rollout *entity.Rollout) error,
+) (*entity.Rollout, error) {
+	tx, err := r.dbPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var current entity.Rollout
+	err = scanRollout(tx.QueryRow(ctx, LockRolloutQuery, id), &current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.RolloutNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updated := current
+	updated.Containers = append([]uuid.UUID{}, current.Containers...)
+	updated.Updated = append([]uuid.UUID{}, current.Updated...)
+	updated.Reverted = append([]uuid.UUID{}, current.Reverted...)
+	if err = mutate(&updated); err != nil {
+		return nil, err
+	}
+	updated.ID = current.ID
+	updated.FromImage = current.FromImage
+	updated.ToImage = current.ToImage
+	updated.CreatedAt = current.CreatedAt
+
+	containers, updatedIDs, reverted, err := rolloutDocuments(&updated)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(
+		ctx,
+		UpdateRolloutQuery,
+		updated.BatchSize,
+		updated.MaxUnavailable,
+		updated.Phase,
+		updated.Paused,
+		updated.Message,
+		containers,
+		updatedIDs,
+		reverted,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if updated.Phase.IsActive() != current.Phase.IsActive() {
+		_, err = tx.Exec(ctx, ActivateRolloutContainersQuery, id, updated.Phase.IsActive())
+		if isUniqueViolation(err) {
+			return nil, usecase.RolloutConflictErr
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func scanRollout(row pgx.Row, rollout *entity.Rollout) error {
+	var containers, updated, reverted []byte
+	err := row.Scan(
+		&rollout.ID,
+		&rollout.FromImage,
+		&rollout.ToImage,
+		&rollout.BatchSize,
+		&rollout.MaxUnavailable,
+		&rollout.Phase,
+		&rollout.Paused,
+		&rollout.Message,
+		&containers,
+		&updated,
+		&reverted,
+		&rollout.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	rollout.CreatedAt = rollout.CreatedAt.UTC()
+	for _, column := range []struct {
+		raw []byte
+		ids *[]uuid.UUID
+	}{
+		{containers, &rollout.Containers},
+		{updated, &rollout.Updated},
+		{reverted, &rollout.Reverted},
+	} {
+		if err = json.Unmarshal(column.raw, column.ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rolloutDocuments encodes the JSONB columns of a rollout.
+func rolloutDocuments(rollout *entity.Rollout) (containers, updated, reverted string, err error) {
+	var raw [3][]byte
+	for i, ids := range [][]uuid.UUID{rollout.Containers, rollout.Updated, rollout.Reverted} {
+		if ids == nil {
+			ids = []uuid.UUID{}
+		}
+		if raw[i], err = json.Marshal(ids); err != nil {
+			return
+		}
+	}
+	return string(raw[0]), string(raw[1]), string(raw[2]), nil
+}
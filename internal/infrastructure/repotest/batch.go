@@ -0,0 +1,155 @@
+package repotest
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+)
+
+var batchTests = map[string]func(t *testing.T, repos Repositories){
+	"ContainerCreateBatch":       testContainerCreateBatch,
+	"ContainerUpdateDeleteBatch": testContainerUpdateDeleteBatch,
+	"NodeBatch":                  testNodeBatch,
+}
+
+func listContainers(t *testing.T, containers usecase.ContainerRepository) []entity.Container {
+	t.Helper()
+	list, _, err := containers.List(context.Background(), entity.ListContainersOptions{})
+	require.NoError(t, err)
+	return list
+}
+
+func testContainerCreateBatch(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	node := createNode(t, repos.Nodes, entity.Resources{})
+	batch := []*entity.Container{
+		entity.NewContainer(node.ID, "nginx"),
+		entity.NewContainer(uuid.New(), "redis"),
+		entity.NewContainer(node.ID, "postgres"),
+	}
+
+	errs, err := repos.Containers.CreateBatch(ctx, batch, true)
+	require.NoError(t, err)
+	require.Len(t, errs, 3)
+	assert.ErrorIs(t, errs[0], usecase.BatchAbortedErr)
+	assert.ErrorIs(t, errs[1], usecase.NodeNotFoundErr)
+	assert.ErrorIs(t, errs[2], usecase.BatchAbortedErr)
+	assert.Empty(t, listContainers(t, repos.Containers))
+	_, err = repos.Containers.ListStatusHistory(ctx, batch[0].ID)
+	assert.ErrorIs(t, err, usecase.ContainerNotFoundErr)
+	got, err := repos.Nodes.Get(ctx, node.ID)
+	require.NoError(t, err)
+	assert.Equal(t, node.Revision, got.Revision, "the rolled back batch left the revision alone")
+
+	errs, err = repos.Containers.CreateBatch(ctx, batch, false)
+	require.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], usecase.NodeNotFoundErr)
+	assert.NoError(t, errs[2])
+	assert.Len(t, listContainers(t, repos.Containers), 2)
+	history, err := repos.Containers.ListStatusHistory(ctx, batch[2].ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+	got, err = repos.Nodes.Get(ctx, node.ID)
+	require.NoError(t, err)
+	assert.Equal(t, node.Revision+2, got.Revision)
+}
+
+func testContainerUpdateDeleteBatch(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	node := createNode(t, repos.Nodes, entity.Resources{})
+	first := createContainer(t, repos.Containers, node.ID, "nginx")
+	second := createContainer(t, repos.Containers, node.ID, "redis")
+	ids := []uuid.UUID{first.ID, uuid.New(), second.ID}
+	toRunning := func(_ int, c *entity.Container) error {
+		c.Status = entity.ContainerStatusRunning
+		return nil
+	}
+
+	updated, errs, err := repos.Containers.UpdateBatch(ctx, ids, toRunning, true)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Container{nil, nil, nil}, updated)
+	assert.ErrorIs(t, errs[0], usecase.BatchAbortedErr)
+	assert.ErrorIs(t, errs[1], usecase.ContainerNotFoundErr)
+	got, err := repos.Containers.Get(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first, got)
+
+	updated, errs, err = repos.Containers.UpdateBatch(ctx, ids, toRunning, false)
+	require.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], usecase.ContainerNotFoundErr)
+	assert.NoError(t, errs[2])
+	require.NotNil(t, updated[2])
+	assert.Equal(t, entity.ContainerStatusRunning, updated[2].Status)
+	assert.Equal(t, second.ResourceVersion+1, updated[2].ResourceVersion)
+	assert.Nil(t, updated[1])
+
+	deleted, errs, err := repos.Containers.DeleteBatch(ctx, ids, true)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Container{nil, nil, nil}, deleted)
+	assert.ErrorIs(t, errs[1], usecase.ContainerNotFoundErr)
+	assert.Len(t, listContainers(t, repos.Containers), 2)
+	history, err := repos.Containers.ListStatusHistory(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 2, "the rolled back batch kept the history")
+
+	deleted, errs, err = repos.Containers.DeleteBatch(ctx, ids, false)
+	require.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], usecase.ContainerNotFoundErr)
+	assert.NoError(t, errs[2])
+	require.NotNil(t, deleted[0])
+	assert.Equal(t, first.ID, deleted[0].ID)
+	assert.Empty(t, listContainers(t, repos.Containers))
+}
+
+func testNodeBatch(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	batch := []*entity.Node{
+		entity.NewNode(entity.Resources{CPU: 1000}),
+		entity.NewNode(entity.Resources{CPU: 2000}),
+	}
+	errs, err := repos.Nodes.CreateBatch(ctx, batch, true)
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil, nil}, errs)
+	createContainer(t, repos.Containers, batch[1].ID, "nginx")
+
+	ids := []uuid.UUID{batch[0].ID, batch[1].ID}
+	updated, errs, err := repos.Nodes.UpdateBatch(ctx, ids, func(i int, node *entity.Node) error {
+		if i == 1 {
+			node.Unschedulable = true
+			return usecase.InvalidPlacementErr
+		}
+		node.Labels = entity.Labels{"zone": "a"}
+		return nil
+	}, false)
+	require.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], usecase.InvalidPlacementErr)
+	require.NotNil(t, updated[0])
+	assert.Equal(t, entity.Labels{"zone": "a"}, updated[0].Labels)
+	assert.Nil(t, updated[1])
+	got, err := repos.Nodes.Get(ctx, batch[1].ID)
+	require.NoError(t, err)
+	assert.False(t, got.Unschedulable)
+
+	errs, err = repos.Nodes.DeleteBatch(ctx, ids, true)
+	require.NoError(t, err)
+	assert.ErrorIs(t, errs[0], usecase.BatchAbortedErr)
+	assert.ErrorIs(t, errs[1], usecase.NodeHasContainersErr)
+	got, err = repos.Nodes.Get(ctx, batch[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.Labels{"zone": "a"}, got.Labels)
+
+	errs, err = repos.Nodes.DeleteBatch(ctx, ids, false)
+	require.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], usecase.NodeHasContainersErr)
+	_, err = repos.Nodes.Get(ctx, batch[0].ID)
+	assert.ErrorIs(t, err, usecase.NodeNotFoundErr)
+}
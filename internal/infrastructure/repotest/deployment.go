@@ -0,0 +1,127 @@
+package repotest
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+)
+
+var deploymentTests = map[string]func(t *testing.T, repos Repositories){
+	"DeploymentCRUD":       testDeploymentCRUD,
+	"DeploymentList":       testDeploymentList,
+	"ContainerOwner":       testContainerOwner,
+	"DeploymentNameUnique": testDeploymentNameUnique,
+}
+
+func newDeployment(name string) *entity.Deployment {
+	return entity.NewDeployment(name, 2, entity.ContainerTemplate{
+		Image:     "nginx",
+		Resources: entity.Resources{CPU: 100},
+		Spec:      entity.ContainerSpec{Env: map[string]string{"PORT": "8080"}, RestartPolicy: entity.RestartPolicyAlways},
+		Labels:    entity.Labels{"app": name},
+	})
+}
+
+func testDeploymentCRUD(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	deployment := newDeployment("web")
+	require.NoError(t, repos.Deployments.Create(ctx, deployment))
+
+	got, err := repos.Deployments.Get(ctx, deployment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, deployment, got)
+
+	updated, err := repos.Deployments.Update(ctx, deployment.ID, func(d *entity.Deployment) error {
+		d.Name = "renamed"
+		d.Replicas = 3
+		d.Template.Image = "nginx:1.27"
+		d.Status = entity.DeploymentStatus{Replicas: 2, ReadyReplicas: 1}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "web", updated.Name, "the name cannot change")
+
+	got, err = repos.Deployments.Get(ctx, deployment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated, got)
+
+	_, err = repos.Deployments.Update(ctx, deployment.ID, func(d *entity.Deployment) error {
+		d.Replicas = 10
+		return usecase.InvalidListOptionsErr
+	})
+	assert.ErrorIs(t, err, usecase.InvalidListOptionsErr)
+	got, err = repos.Deployments.Get(ctx, deployment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, got.Replicas)
+
+	require.NoError(t, repos.Deployments.Delete(ctx, deployment.ID))
+	_, err = repos.Deployments.Get(ctx, deployment.ID)
+	assert.ErrorIs(t, err, usecase.DeploymentNotFoundErr)
+	assert.ErrorIs(t, repos.Deployments.Delete(ctx, deployment.ID), usecase.DeploymentNotFoundErr)
+	_, err = repos.Deployments.Update(ctx, uuid.New(), func(*entity.Deployment) error { return nil })
+	assert.ErrorIs(t, err, usecase.DeploymentNotFoundErr)
+}
+
+func testDeploymentNameUnique(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	require.NoError(t, repos.Deployments.Create(ctx, newDeployment("web")))
+	assert.ErrorIs(t, repos.Deployments.Create(ctx, newDeployment("web")), usecase.DeploymentExistsErr)
+}
+
+func testDeploymentList(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	for _, name := range []string{"c", "a", "b"} {
+		require.NoError(t, repos.Deployments.Create(ctx, newDeployment(name)))
+	}
+
+	page, next, err := repos.Deployments.List(ctx, entity.ListOptions{Limit: 2, SortBy: "name"})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "a", page[0].Name)
+	assert.Equal(t, "b", page[1].Name)
+	require.NotEmpty(t, next)
+
+	page, next, err = repos.Deployments.List(ctx, entity.ListOptions{Limit: 2, SortBy: "name", Continue: next})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "c", page[0].Name)
+	assert.Empty(t, next)
+
+	_, _, err = repos.Deployments.List(ctx, entity.ListOptions{SortBy: "replicas"})
+	assert.ErrorIs(t, err, usecase.InvalidListOptionsErr)
+}
+
+func testContainerOwner(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	node := createNode(t, repos.Nodes, entity.Resources{})
+	owner := &entity.OwnerReference{Kind: entity.OwnerKindDeployment, ID: uuid.New()}
+
+	owned := entity.NewContainer(node.ID, "nginx")
+	owned.Owner = owner
+	require.NoError(t, repos.Containers.Create(ctx, owned))
+	unowned := createContainer(t, repos.Containers, node.ID, "nginx")
+
+	got, err := repos.Containers.Get(ctx, owned.ID)
+	require.NoError(t, err)
+	assert.Equal(t, owner, got.Owner)
+	got, err = repos.Containers.Get(ctx, unowned.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.Owner)
+
+	list, _, err := repos.Containers.List(ctx, entity.ListContainersOptions{OwnerID: owner.ID})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, owned.ID, list[0].ID)
+
+	list, _, err = repos.Containers.List(ctx, entity.ListContainersOptions{OwnerKind: entity.OwnerKindDeployment})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, owned.ID, list[0].ID)
+	list, _, err = repos.Containers.List(ctx, entity.ListContainersOptions{OwnerKind: entity.OwnerKindJob})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
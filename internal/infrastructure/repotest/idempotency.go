@@ -0,0 +1,75 @@
+package repotest
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var idempotencyTests = map[string]func(t *testing.T, repos Repositories){
+	"IdempotencyRecord":      testIdempotencyRecord,
+	"IdempotencyRecordPrune": testIdempotencyRecordPrune,
+}
+
+func createIdempotencyRecord(t *testing.T, idempotency usecase.IdempotencyRepository, key string, createdAt time.Time) {
+	t.Helper()
+	record := &entity.IdempotencyRecord{Key: key, RequestHash: "hash-" + key, CreatedAt: createdAt}
+	require.NoError(t, idempotency.Create(context.Background(), record))
+}
+
+func testIdempotencyRecord(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	createdAt := time.Now().UTC().Truncate(time.Millisecond)
+
+	_, err := repos.Idempotency.Get(ctx, "first")
+	assert.ErrorIs(t, err, usecase.IdempotencyKeyNotFoundErr)
+	assert.ErrorIs(t, repos.Idempotency.Complete(ctx, "first", &entity.IdempotentResponse{Status: 200}), usecase.IdempotencyKeyNotFoundErr)
+
+	createIdempotencyRecord(t, repos.Idempotency, "first", createdAt)
+	err = repos.Idempotency.Create(ctx, &entity.IdempotencyRecord{Key: "first", RequestHash: "other", CreatedAt: createdAt})
+	assert.ErrorIs(t, err, usecase.IdempotencyKeyExistsErr)
+
+	record, err := repos.Idempotency.Get(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, "hash-first", record.RequestHash)
+	assert.Equal(t, createdAt, record.CreatedAt.UTC())
+	assert.True(t, record.InProgress())
+
+	response := &entity.IdempotentResponse{
+		Status: 201,
+		Header: http.Header{"Content-Type": {"application/json; charset=utf-8"}, "Etag": {`"1"`}},
+		Body:   []byte(`{"id": 1}`),
+	}
+	require.NoError(t, repos.Idempotency.Complete(ctx, "first", response))
+	record, err = repos.Idempotency.Get(ctx, "first")
+	require.NoError(t, err)
+	assert.False(t, record.InProgress())
+	assert.Equal(t, response, record.Response)
+
+	require.NoError(t, repos.Idempotency.Delete(ctx, "first"))
+	require.NoError(t, repos.Idempotency.Delete(ctx, "first"))
+	_, err = repos.Idempotency.Get(ctx, "first")
+	assert.ErrorIs(t, err, usecase.IdempotencyKeyNotFoundErr)
+}
+
+func testIdempotencyRecordPrune(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(time.Millisecond)
+
+	createIdempotencyRecord(t, repos.Idempotency, "old", start)
+	createIdempotencyRecord(t, repos.Idempotency, "new", start.Add(time.Minute))
+
+	removed, err := repos.Idempotency.Prune(ctx, start.Add(time.Second))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, removed)
+
+	_, err = repos.Idempotency.Get(ctx, "old")
+	assert.ErrorIs(t, err, usecase.IdempotencyKeyNotFoundErr)
+	_, err = repos.Idempotency.Get(ctx, "new")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,165 @@
+package repotest
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+	"time"
+)
+
+var jobTests = map[string]func(t *testing.T, repos Repositories){
+	"JobCRUD":     testJobCRUD,
+	"JobList":     testJobList,
+	"CronJobCRUD": testCronJobCRUD,
+}
+
+func newJob(name string) *entity.Job {
+	job := entity.NewJob(name, entity.JobSpec{
+		Template: entity.ContainerTemplate{
+			Image:  "migrate",
+			Spec:   entity.ContainerSpec{Args: []string{"up"}},
+			Labels: entity.Labels{"app": name},
+		},
+		Completions:  2,
+		Parallelism:  1,
+		BackoffLimit: 3,
+	})
+	job.CreatedAt = job.CreatedAt.Truncate(time.Millisecond)
+	return job
+}
+
+func testJobCRUD(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	job := newJob("migrate")
+	require.NoError(t, repos.Jobs.Create(ctx, job))
+	assert.ErrorIs(t, repos.Jobs.Create(ctx, newJob("migrate")), usecase.JobExistsErr)
+
+	got, err := repos.Jobs.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.True(t, job.CreatedAt.Equal(got.CreatedAt))
+	got.CreatedAt = job.CreatedAt
+	assert.Equal(t, job, got)
+
+	exitCode := 0
+	completedAt := time.Now().UTC().Truncate(time.Millisecond)
+	updated, err := repos.Jobs.Update(ctx, job.ID, func(j *entity.Job) error {
+		j.Spec.Completions = 5
+		j.Status.Phase = entity.JobPhaseSucceeded
+		j.Status.Succeeded = 1
+		j.Status.CompletedAt = &completedAt
+		j.Status.Runs = append(j.Status.Runs, entity.JobRun{
+			ContainerID: uuid.New(),
+			ExitCode:    &exitCode,
+			Succeeded:   true,
+			Reason:      entity.ContainerStatusExited,
+			FinishedAt:  completedAt,
+		})
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Spec.Completions, "only the status can change")
+
+	got, err = repos.Jobs.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.JobPhaseSucceeded, got.Status.Phase)
+	require.Len(t, got.Status.Runs, 1)
+	assert.Equal(t, &exitCode, got.Status.Runs[0].ExitCode)
+	assert.True(t, completedAt.Equal(*got.Status.CompletedAt))
+
+	require.NoError(t, repos.Jobs.Delete(ctx, job.ID))
+	_, err = repos.Jobs.Get(ctx, job.ID)
+	assert.ErrorIs(t, err, usecase.JobNotFoundErr)
+	assert.ErrorIs(t, repos.Jobs.Delete(ctx, job.ID), usecase.JobNotFoundErr)
+	_, err = repos.Jobs.Update(ctx, job.ID, func(*entity.Job) error { return nil })
+	assert.ErrorIs(t, err, usecase.JobNotFoundErr)
+}
+
+func testJobList(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	owner := &entity.OwnerReference{Kind: entity.OwnerKindCronJob, ID: uuid.New()}
+	for _, name := range []string{"b", "a", "c"} {
+		job := newJob(name)
+		if name != "c" {
+			job.Owner = owner
+		}
+		require.NoError(t, repos.Jobs.Create(ctx, job))
+	}
+
+	page, next, err := repos.Jobs.List(ctx, entity.ListJobsOptions{
+		ListOptions: entity.ListOptions{Limit: 1, SortBy: "name"},
+		OwnerID:     owner.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "a", page[0].Name)
+	assert.Equal(t, owner, page[0].Owner)
+	require.NotEmpty(t, next)
+
+	page, next, err = repos.Jobs.List(ctx, entity.ListJobsOptions{
+		ListOptions: entity.ListOptions{Limit: 1, SortBy: "name", Continue: next},
+		OwnerID:     owner.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "b", page[0].Name)
+	assert.Empty(t, next)
+
+	all, _, err := repos.Jobs.List(ctx, entity.ListJobsOptions{})
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
+
+func testCronJobCRUD(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	cronJob := entity.NewCronJob(&entity.AddCronJob{
+		Name:     "report",
+		Schedule: "0 3 * * *",
+		JobTemplate: entity.JobSpec{
+			Template:    entity.ContainerTemplate{Image: "report", Labels: entity.Labels{"app": "report"}},
+			Completions: 1,
+			Parallelism: 1,
+		},
+	})
+	cronJob.CreatedAt = cronJob.CreatedAt.Truncate(time.Millisecond)
+	require.NoError(t, repos.CronJobs.Create(ctx, cronJob))
+	assert.ErrorIs(t, repos.CronJobs.Create(ctx, entity.NewCronJob(&entity.AddCronJob{Name: "report"})), usecase.CronJobExistsErr)
+
+	got, err := repos.CronJobs.Get(ctx, cronJob.ID)
+	require.NoError(t, err)
+	got.CreatedAt = cronJob.CreatedAt
+	assert.Equal(t, cronJob, got)
+
+	scheduledAt := time.Now().UTC().Truncate(time.Minute)
+	updated, err := repos.CronJobs.Update(ctx, cronJob.ID, func(c *entity.CronJob) error {
+		c.Name = "renamed"
+		c.Schedule = "@hourly"
+		c.ConcurrencyPolicy = entity.ConcurrencyPolicyForbid
+		c.Suspend = true
+		c.FailedJobsHistoryLimit = 0
+		c.Status.LastScheduleTime = &scheduledAt
+		c.Status.Active = []uuid.UUID{uuid.New()}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "report", updated.Name, "the name cannot change")
+
+	got, err = repos.CronJobs.Get(ctx, cronJob.ID)
+	require.NoError(t, err)
+	assert.True(t, scheduledAt.Equal(*got.Status.LastScheduleTime))
+	got.Status.LastScheduleTime = updated.Status.LastScheduleTime
+	got.CreatedAt = updated.CreatedAt
+	assert.Equal(t, updated, got)
+
+	list, _, err := repos.CronJobs.List(ctx, entity.ListOptions{SortBy: "name"})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, repos.CronJobs.Delete(ctx, cronJob.ID))
+	_, err = repos.CronJobs.Get(ctx, cronJob.ID)
+	assert.ErrorIs(t, err, usecase.CronJobNotFoundErr)
+	assert.ErrorIs(t, repos.CronJobs.Delete(ctx, cronJob.ID), usecase.CronJobNotFoundErr)
+}
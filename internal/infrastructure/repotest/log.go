@@ -0,0 +1,103 @@
+package repotest
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+	"time"
+)
+
+var logTests = map[string]func(t *testing.T, repos Repositories){
+	"ContainerLogs":     testContainerLogs,
+	"ContainerLogPrune": testContainerLogPrune,
+}
+
+// appendLines stores one stdout entry per line, logged a second apart from start.
+func appendLines(t *testing.T, logs usecase.ContainerLogRepository, id uuid.UUID, start time.Time, lines ...string) {
+	t.Helper()
+	entries := make([]entity.LogEntry, len(lines))
+	for i, line := range lines {
+		entries[i] = entity.LogEntry{
+			Stream:    entity.LogStreamStdout,
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			Line:      line,
+		}
+	}
+	require.NoError(t, logs.Append(context.Background(), id, entries))
+}
+
+func logLines(entries []entity.LogEntry) []string {
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.Line
+	}
+	return lines
+}
+
+func testContainerLogs(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	node := createNode(t, repos.Nodes, entity.Resources{})
+	container := createContainer(t, repos.Containers, node.ID, "nginx")
+	start := time.Now().UTC().Truncate(time.Millisecond)
+
+	assert.ErrorIs(t, repos.Logs.Append(ctx, uuid.New(), []entity.LogEntry{{Line: "lost"}}), usecase.ContainerNotFoundErr)
+	_, err := repos.Logs.List(ctx, uuid.New(), entity.LogOptions{})
+	assert.ErrorIs(t, err, usecase.ContainerNotFoundErr)
+
+	empty, err := repos.Logs.List(ctx, container.ID, entity.LogOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	appendLines(t, repos.Logs, container.ID, start, "one", "two", "three", "four")
+	all, err := repos.Logs.List(ctx, container.ID, entity.LogOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "two", "three", "four"}, logLines(all))
+	assert.Equal(t, start, all[0].Timestamp)
+	assert.Equal(t, entity.LogStreamStdout, all[0].Stream)
+	for i := 1; i < len(all); i++ {
+		assert.Greater(t, all[i].Seq, all[i-1].Seq)
+	}
+
+	tail, err := repos.Logs.List(ctx, container.ID, entity.LogOptions{Tail: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"three", "four"}, logLines(tail))
+
+	since, err := repos.Logs.List(ctx, container.ID, entity.LogOptions{Since: start.Add(time.Second), Tail: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"two", "three", "four"}, logLines(since))
+
+	after, err := repos.Logs.List(ctx, container.ID, entity.LogOptions{AfterSeq: all[2].Seq})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"four"}, logLines(after))
+
+	_, err = repos.Containers.Delete(ctx, container.ID)
+	require.NoError(t, err)
+	_, err = repos.Logs.List(ctx, container.ID, entity.LogOptions{})
+	assert.ErrorIs(t, err, usecase.ContainerNotFoundErr)
+}
+
+func testContainerLogPrune(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	node := createNode(t, repos.Nodes, entity.Resources{})
+	first := createContainer(t, repos.Containers, node.ID, "nginx")
+	second := createContainer(t, repos.Containers, node.ID, "redis")
+	start := time.Now().UTC().Truncate(time.Millisecond)
+
+	appendLines(t, repos.Logs, first.ID, start, "old", "a", "b", "c")
+	appendLines(t, repos.Logs, second.ID, start.Add(time.Second), "x", "y")
+
+	removed, err := repos.Logs.Prune(ctx, start.Add(time.Second), 2)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, removed)
+
+	kept, err := repos.Logs.List(ctx, first.ID, entity.LogOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, logLines(kept))
+	kept, err = repos.Logs.List(ctx, second.ID, entity.LogOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x", "y"}, logLines(kept))
+}
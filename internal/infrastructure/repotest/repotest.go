@@ -0,0 +1,823 @@
+// Package repotest is the conformance suite every implementation of the
+// usecase repositories has to pass.
+package repotest
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Repositories share one storage.
+type Repositories struct {
+	Nodes       usecase.NodeRepository
+	Containers  usecase.ContainerRepository
+	Deployments usecase.DeploymentRepository
+	Rollouts    usecase.RolloutRepository
+	Jobs        usecase.JobRepository
+	CronJobs    usecase.CronJobRepository
+	Logs        usecase.ContainerLogRepository
+	Idempotency usecase.IdempotencyRepository
+}
+
+// Factory returns repositories backed by empty storage.
+type Factory func(t *testing.T) Repositories
+
+// Run runs the whole suite, every test gets fresh repositories from factory.
+func Run(t *testing.T, factory Factory) {
+	tests := map[string]func(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository){
+		"NodeCreateGet":              testNodeCreateGet,
+		"NodeNotFound":               testNodeNotFound,
+		"NodeUpdate":                 testNodeUpdate,
+		"NodeDelete":                 testNodeDelete,
+		"NodeHeartbeat":              testNodeHeartbeat,
+		"NodeFailStaleAndRecover":    testNodeFailStaleAndRecover,
+		"NodeList":                   testNodeList,
+		"NodeListLabelSelector":      testNodeListLabelSelector,
+		"NodeDesiredState":           testNodeDesiredState,
+		"ContainerCreateGet":         testContainerCreateGet,
+		"ContainerNotFound":          testContainerNotFound,
+		"ContainerUnknownNode":       testContainerUnknownNode,
+		"ContainerUpdate":            testContainerUpdate,
+		"ContainerUpdateAborted":     testContainerUpdateAborted,
+		"ContainerStatusReport":      testContainerStatusReport,
+		"ContainerMove":              testContainerMove,
+		"ContainerCapacity":          testContainerCapacity,
+		"ContainerDelete":            testContainerDelete,
+		"ContainerEvents":            testContainerEvents,
+		"ContainerList":              testContainerList,
+		"ContainerListPagination":    testContainerListPagination,
+		"ContainerListDescendingAll": testContainerListDescendingAll,
+		"ContainerListLabelSelector": testContainerListLabelSelector,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			repos := factory(t)
+			test(t, repos.Nodes, repos.Containers)
+		})
+	}
+	for _, suite := range []map[string]func(t *testing.T, repos Repositories){
+		deploymentTests,
+		rolloutTests,
+		jobTests,
+		logTests,
+		idempotencyTests,
+		batchTests,
+	} {
+		for name, test := range suite {
+			t.Run(name, func(t *testing.T) {
+				test(t, factory(t))
+			})
+		}
+	}
+}
+
+func createNode(t *testing.T, nodes usecase.NodeRepository, capacity entity.Resources) *entity.Node {
+	t.Helper()
+	node := entity.NewNode(capacity)
+	require.NoError(t, nodes.Create(context.Background(), node))
+	return node
+}
+
+func createContainer(t *testing.T, containers usecase.ContainerRepository, nodeID uuid.UUID, image string) *entity.Container {
+	t.Helper()
+	container := entity.NewContainer(nodeID, image)
+	container.Resources = entity.Resources{CPU: 100, Memory: 64}
+	container.Spec = entity.ContainerSpec{
+		Command:       []string{"/bin/server"},
+		Env:           map[string]string{"PORT": "8080"},
+		Ports:         []entity.ContainerPort{{ContainerPort: 8080, Protocol: "tcp"}},
+		RestartPolicy: entity.RestartPolicyAlways,
+	}
+	require.NoError(t, containers.Create(context.Background(), container))
+	return container
+}
+
+func setNodeStatus(ctx context.Context, nodes usecase.NodeRepository, id uuid.UUID, status entity.NodeStatus) error {
+	_, err := nodes.Update(ctx, id, func(node *entity.Node) error {
+		node.Status = status
+		return nil
+	})
+	return err
+}
+
+func heartbeat(ctx context.Context, nodes usecase.NodeRepository, id uuid.UUID, seenAt time.Time) error {
+	_, err := nodes.Heartbeat(ctx, id, seenAt, nil)
+	return err
+}
+
+func mustParseSelector(t *testing.T, selector string) entity.LabelSelector {
+	t.Helper()
+	parsed, err := entity.ParseLabelSelector(selector)
+	require.NoError(t, err)
+	return parsed
+}
+
+func testNodeCreateGet(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := entity.NewNode(entity.Resources{CPU: 4000, Memory: 8192, Disk: 100})
+	node.Labels = entity.Labels{"zone": "eu-1", "morchy.io/gpu": ""}
+	require.NoError(t, nodes.Create(ctx, node))
+	container := createContainer(t, containers, node.ID, "nginx")
+
+	got, err := nodes.Get(ctx, node.ID)
+	require.NoError(t, err)
+	assert.Equal(t, node.ID, got.ID)
+	assert.Equal(t, entity.NewNodeStatus, got.Status)
+	assert.Nil(t, got.LastSeen)
+	assert.Equal(t, node.Capacity, got.Capacity)
+	assert.Equal(t, node.Labels, got.Labels)
+	require.Len(t, got.Containers, 1)
+	assert.Equal(t, *container, got.Containers[0])
+}
+
+func testNodeNotFound(t *testing.T, nodes usecase.NodeRepository, _ usecase.ContainerRepository) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	_, err := nodes.Get(ctx, id)
+	assert.ErrorIs(t, err, usecase.NodeNotFoundErr)
+	assert.ErrorIs(t, setNodeStatus(ctx, nodes, id, entity.RunningNodeStatus), usecase.NodeNotFoundErr)
+	assert.ErrorIs(t, nodes.Delete(ctx, id), usecase.NodeNotFoundErr)
+	assert.ErrorIs(t, heartbeat(ctx, nodes, id, time.Now()), usecase.NodeNotFoundErr)
+	_, err = nodes.GetDesiredState(ctx, id)
+	assert.ErrorIs(t, err, usecase.NodeNotFoundErr)
+}
+
+func testNodeUpdate(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{CPU: 1000})
+	container := createContainer(t, containers, node.ID, "nginx")
+
+	updated, err := nodes.Update(ctx, node.ID, func(current *entity.Node) error {
+		assert.Len(t, current.Containers, 1, "containers are loaded before mutate")
+		current.Status = entity.RunningNodeStatus
+		current.Capacity.Memory = 2048
+		current.Labels["zone"] = "eu-2"
+		current.Taints = []entity.Taint{{Key: "gpu", Value: "a100", Effect: entity.TaintEffectNoSchedule}}
+		current.Unschedulable = true
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.RunningNodeStatus, updated.Status)
+	assert.Equal(t, node.ResourceVersion+1, updated.ResourceVersion)
+
+	got, err := nodes.Get(ctx, node.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.RunningNodeStatus, got.Status)
+	assert.Equal(t, entity.Resources{CPU: 1000, Memory: 2048}, got.Capacity)
+	assert.Equal(t, entity.Labels{"zone": "eu-2"}, got.Labels)
+	assert.Equal(t, []entity.Taint{{Key: "gpu", Value: "a100", Effect: entity.TaintEffectNoSchedule}}, got.Taints)
+	assert.True(t, got.Unschedulable)
+	assert.Equal(t, updated.ResourceVersion, got.ResourceVersion)
+	assert.Equal(t, []entity.Container{*container}, got.Containers)
+
+	_, err = nodes.Update(ctx, node.ID, func(current *entity.Node) error {
+		current.Status = entity.FailedNodeStatus
+		current.Labels = entity.Labels{}
+		return usecase.InsufficientCapacityErr
+	})
+	assert.ErrorIs(t, err, usecase.InsufficientCapacityErr)
+
+	got, err = nodes.Get(ctx, node.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.RunningNodeStatus, got.Status)
+	assert.Equal(t, updated.ResourceVersion, got.ResourceVersion, "aborted updates keep the version")
+	assert.Equal(t, entity.Labels{"zone": "eu-2"}, got.Labels)
+	assert.Equal(t, []entity.Taint{{Key: "gpu", Value: "a100", Effect: entity.TaintEffectNoSchedule}}, got.Taints)
+}
+
+func testNodeDelete(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{})
+	container := createContainer(t, containers, node.ID, "nginx")
+
+	assert.ErrorIs(t, nodes.Delete(ctx, node.ID), usecase.NodeHasContainersErr)
+
+	_, err := containers.Delete(ctx, container.ID)
+	require.NoError(t, err)
+	require.NoError(t, nodes.Delete(ctx, node.ID))
+	_, err = nodes.Get(ctx, node.ID)
+	assert.ErrorIs(t, err, usecase.NodeNotFoundErr)
+}
+
+func testNodeHeartbeat(t *testing.T, nodes usecase.NodeRepository, _ usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{CPU: 1000})
+	seenAt := time.Now().UTC().Truncate(time.Millisecond)
+
+	require.NoError(t, heartbeat(ctx, nodes, node.ID, seenAt))
+	got, err := nodes.Get(ctx, node.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.LastSeen)
+	assert.True(t, seenAt.Equal(*got.LastSeen))
+	assert.Equal(t, entity.Resources{CPU: 1000}, got.Capacity)
+
+	assert.Equal(t, node.ResourceVersion, got.ResourceVersion)
+
+	capacity := entity.Resources{CPU: 2000, Memory: 1024, Disk: 10}
+	changed, err := nodes.Heartbeat(ctx, node.ID, seenAt, &capacity)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	got, err = nodes.Get(ctx, node.ID)
+	require.NoError(t, err)
+	assert.Equal(t, capacity, got.Capacity)
+	assert.Equal(t, node.ResourceVersion+1, got.ResourceVersion, "changing the capacity is a modification")
+
+	changed, err = nodes.Heartbeat(ctx, node.ID, seenAt, &capacity)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	got, err = nodes.Get(ctx, node.ID)
+	require.NoError(t, err)
+	assert.Equal(t, node.ResourceVersion+1, got.ResourceVersion)
+}
+
+func testNodeFailStaleAndRecover(t *testing.T, nodes usecase.NodeRepository, _ usecase.ContainerRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	deadline := now.Add(-time.Minute)
+
+	stale := createNode(t, nodes, entity.Resources{})
+	require.NoError(t, setNodeStatus(ctx, nodes, stale.ID, entity.RunningNodeStatus))
+	require.NoError(t, heartbeat(ctx, nodes, stale.ID, now.Add(-time.Hour)))
+
+	alive := createNode(t, nodes, entity.Resources{})
+	require.NoError(t, heartbeat(ctx, nodes, alive.ID, now))
+
+	silent := createNode(t, nodes, entity.Resources{})
+
+	unseen := createNode(t, nodes, entity.Resources{})
+	require.NoError(t, setNodeStatus(ctx, nodes, unseen.ID, entity.RunningNodeStatus))
+	got, err := nodes.Get(ctx, unseen.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.LastSeen, "nodes moved to running count as seen")
+
+	failed, err := nodes.FailStale(ctx, deadline)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{stale.ID}, failed, "nodes that just started running get a grace period")
+
+	recovered, err := nodes.Recover(ctx, deadline)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{alive.ID}, recovered)
+
+	require.NoError(t, heartbeat(ctx, nodes, stale.ID, now))
+	recovered, err = nodes.Recover(ctx, deadline)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{stale.ID}, recovered)
+
+	got, err = nodes.Get(ctx, silent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.NewNodeStatus, got.Status)
+	assert.Equal(t, silent.ResourceVersion, got.ResourceVersion)
+
+	got, err = nodes.Get(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, stale.ResourceVersion+3, got.ResourceVersion, "status changes increment the version, heartbeats do not")
+}
+
+func testNodeList(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	created := map[uuid.UUID]bool{}
+	for i := 0; i < 5; i++ {
+		node := createNode(t, nodes, entity.Resources{})
+		created[node.ID] = true
+		if i%2 == 0 {
+			require.NoError(t, setNodeStatus(ctx, nodes, node.ID, entity.RunningNodeStatus))
+			createContainer(t, containers, node.ID, "nginx")
+		}
+	}
+
+	seen := map[uuid.UUID]bool{}
+	opts := entity.ListNodesOptions{ListOptions: entity.ListOptions{Limit: 2}}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3)
+		page, next, err := nodes.List(ctx, opts)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(page), 2)
+		for _, node := range page {
+			assert.False(t, seen[node.ID], "node listed twice")
+			seen[node.ID] = true
+			if node.Status == entity.RunningNodeStatus {
+				assert.Len(t, node.Containers, 1)
+			} else {
+				assert.Empty(t, node.Containers)
+			}
+		}
+		if next == "" {
+			break
+		}
+		opts.Continue = next
+	}
+	assert.Equal(t, created, seen)
+
+	running, next, err := nodes.List(ctx, entity.ListNodesOptions{Status: entity.RunningNodeStatus})
+	require.NoError(t, err)
+	assert.Empty(t, next)
+	assert.Len(t, running, 3)
+
+	sorted, _, err := nodes.List(ctx, entity.ListNodesOptions{
+		ListOptions: entity.ListOptions{SortBy: "status", Order: entity.SortDescending},
+	})
+	require.NoError(t, err)
+	require.Len(t, sorted, 5)
+	assert.Equal(t, entity.RunningNodeStatus, sorted[0].Status)
+	assert.Equal(t, entity.NewNodeStatus, sorted[4].Status)
+
+	_, _, err = nodes.List(ctx, entity.ListNodesOptions{ListOptions: entity.ListOptions{SortBy: "capacity"}})
+	assert.ErrorIs(t, err, usecase.InvalidListOptionsErr)
+	_, _, err = nodes.List(ctx, entity.ListNodesOptions{ListOptions: entity.ListOptions{Continue: "garbage"}})
+	assert.ErrorIs(t, err, usecase.InvalidContinueTokenErr)
+}
+
+func testNodeDesiredState(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{})
+
+	state, err := nodes.GetDesiredState(ctx, node.ID)
+	require.NoError(t, err)
+	assert.Equal(t, node.ID, state.NodeID)
+	assert.NotNil(t, state.Containers)
+	assert.Empty(t, state.Containers)
+	revision := state.Revision
+
+	container := createContainer(t, containers, node.ID, "nginx")
+	state, err = nodes.GetDesiredState(ctx, node.ID)
+	require.NoError(t, err)
+	assert.Greater(t, state.Revision, revision)
+	assert.Equal(t, []entity.Container{*container}, state.Containers)
+	revision = state.Revision
+
+	_, err = containers.Delete(ctx, container.ID)
+	require.NoError(t, err)
+	state, err = nodes.GetDesiredState(ctx, node.ID)
+	require.NoError(t, err)
+	assert.Greater(t, state.Revision, revision)
+	assert.Empty(t, state.Containers)
+}
+
+func testContainerCreateGet(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{})
+	container := entity.NewContainer(node.ID, "nginx:1.27")
+	container.Placement = entity.Placement{
+		NodeSelector: entity.Labels{"zone": "eu"},
+		NodeAffinity: "disk in (ssd,nvme)",
+		AntiAffinity: []entity.ContainerAffinityTerm{{Image: "nginx:1.27"}},
+	}
+	container.PlacementReasons = []string{"node requested explicitly"}
+	require.NoError(t, containers.Create(ctx, container))
+
+	got, err := containers.Get(ctx, container.ID)
+	require.NoError(t, err)
+	assert.Equal(t, container, got)
+
+	history, err := containers.ListStatusHistory(ctx, container.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, container.ID, history[0].ContainerID)
+	assert.Empty(t, history[0].From)
+	assert.Equal(t, entity.ContainerStatusPending, history[0].To)
+}
+
+func testContainerNotFound(t *testing.T, _ usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	_, err := containers.Get(ctx, id)
+	assert.ErrorIs(t, err, usecase.ContainerNotFoundErr)
+	_, err = containers.Update(ctx, id, func(*entity.Container) error { return nil })
+	assert.ErrorIs(t, err, usecase.ContainerNotFoundErr)
+	_, err = containers.Delete(ctx, id)
+	assert.ErrorIs(t, err, usecase.ContainerNotFoundErr)
+	_, err = containers.ListStatusHistory(ctx, id)
+	assert.ErrorIs(t, err, usecase.ContainerNotFoundErr)
+}
+
+func testContainerUnknownNode(t *testing.T, _ usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	container := entity.NewContainer(uuid.New(), "nginx")
+
+	assert.ErrorIs(t, containers.Create(ctx, container), usecase.NodeNotFoundErr)
+	_, err := containers.Get(ctx, container.ID)
+	assert.ErrorIs(t, err, usecase.ContainerNotFoundErr)
+}
+
+func testContainerUpdate(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{})
+	container := createContainer(t, containers, node.ID, "nginx")
+	before, err := nodes.GetDesiredState(ctx, node.ID)
+	require.NoError(t, err)
+
+	updated, err := containers.Update(ctx, container.ID, func(c *entity.Container) error {
+		c.Status = entity.ContainerStatusCreating
+		c.Image = "nginx:1.27"
+		c.Spec.Env["DEBUG"] = "1"
+		c.Spec.Tolerations = []entity.Toleration{{Key: "gpu", Operator: entity.TolerationOpExists}}
+		c.RescheduleReason = "node is draining"
+		exitCode := 137
+		startedAt := time.Now().UTC().Truncate(time.Millisecond)
+		c.State = entity.ContainerState{
+			ExitCode:     &exitCode,
+			Signal:       "SIGKILL",
+			OOMKilled:    true,
+			RestartCount: 2,
+			StartedAt:    &startedAt,
+			Reason:       "out of memory",
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.ContainerStatusCreating, updated.Status)
+	assert.Equal(t, container.ResourceVersion+1, updated.ResourceVersion)
+
+	got, err := containers.Get(ctx, container.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated, got)
+	assert.Equal(t, map[string]string{"PORT": "8080", "DEBUG": "1"}, got.Spec.Env)
+
+	history, err := containers.ListStatusHistory(ctx, container.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, entity.ContainerStatusPending, history[1].From)
+	assert.Equal(t, entity.ContainerStatusCreating, history[1].To)
+
+	after, err := nodes.GetDesiredState(ctx, node.ID)
+	require.NoError(t, err)
+	assert.Greater(t, after.Revision, before.Revision)
+
+	_, err = containers.Update(ctx, container.ID, func(c *entity.Container) error {
+		c.Image = "nginx:1.28"
+		return nil
+	})
+	require.NoError(t, err)
+	history, err = containers.ListStatusHistory(ctx, container.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 2, "only status changes are recorded")
+}
+
+func testContainerStatusReport(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{})
+	container := createContainer(t, containers, node.ID, "nginx")
+	before, err := nodes.GetDesiredState(ctx, node.ID)
+	require.NoError(t, err)
+
+	startedAt := time.Now().UTC().Truncate(time.Millisecond)
+	_, err = containers.Update(ctx, container.ID, func(c *entity.Container) error {
+		c.Status = entity.ContainerStatusRunning
+		c.State = entity.ContainerState{RestartCount: 1, StartedAt: &startedAt}
+		c.RefreshConditions(startedAt)
+		return nil
+	})
+	require.NoError(t, err)
+
+	after, err := nodes.GetDesiredState(ctx, node.ID)
+	require.NoError(t, err)
+	assert.Equal(t, before.Revision, after.Revision, "reported status and state leave the desired state alone")
+}
+
+func testContainerUpdateAborted(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{})
+	container := createContainer(t, containers, node.ID, "nginx")
+
+	_, err := containers.Update(ctx, container.ID, func(c *entity.Container) error {
+		c.Status = entity.ContainerStatusRunning
+		return usecase.InvalidStatusTransitionErr
+	})
+	assert.ErrorIs(t, err, usecase.InvalidStatusTransitionErr)
+
+	got, err := containers.Get(ctx, container.ID)
+	require.NoError(t, err)
+	assert.Equal(t, container, got)
+
+	_, err = containers.Update(ctx, container.ID, func(c *entity.Container) error {
+		c.NodeID = uuid.New()
+		return nil
+	})
+	assert.ErrorIs(t, err, usecase.NodeNotFoundErr)
+
+	got, err = containers.Get(ctx, container.ID)
+	require.NoError(t, err)
+	assert.Equal(t, container, got)
+}
+
+func testContainerCapacity(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{CPU: 1000})
+	other := createNode(t, nodes, entity.Resources{})
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			container := entity.NewContainer(node.ID, "nginx")
+			container.Resources = entity.Resources{CPU: 200}
+			errs[i] = containers.Create(ctx, container)
+		}()
+	}
+	wg.Wait()
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+		} else {
+			assert.ErrorIs(t, err, usecase.InsufficientCapacityErr)
+		}
+	}
+	assert.Equal(t, 5, created, "concurrent creates do not overcommit the node")
+
+	container := entity.NewContainer(other.ID, "redis")
+	container.Resources = entity.Resources{CPU: 200}
+	require.NoError(t, containers.Create(ctx, container))
+	_, err := containers.Update(ctx, container.ID, func(c *entity.Container) error {
+		c.NodeID = node.ID
+		return nil
+	})
+	assert.ErrorIs(t, err, usecase.InsufficientCapacityErr)
+	got, err := containers.Get(ctx, container.ID)
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, got.NodeID)
+
+	_, err = containers.Update(ctx, container.ID, func(c *entity.Container) error {
+		c.Image = "redis:7"
+		return nil
+	})
+	assert.NoError(t, err, "containers staying on their node are not checked")
+}
+
+func testContainerMove(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	from := createNode(t, nodes, entity.Resources{})
+	to := createNode(t, nodes, entity.Resources{})
+	container := createContainer(t, containers, from.ID, "nginx")
+
+	fromBefore, err := nodes.GetDesiredState(ctx, from.ID)
+	require.NoError(t, err)
+	toBefore, err := nodes.GetDesiredState(ctx, to.ID)
+	require.NoError(t, err)
+
+	_, err = containers.Update(ctx, container.ID, func(c *entity.Container) error {
+		c.NodeID = to.ID
+		return nil
+	})
+	require.NoError(t, err)
+
+	fromAfter, err := nodes.GetDesiredState(ctx, from.ID)
+	require.NoError(t, err)
+	assert.Greater(t, fromAfter.Revision, fromBefore.Revision)
+	assert.Empty(t, fromAfter.Containers)
+
+	toAfter, err := nodes.GetDesiredState(ctx, to.ID)
+	require.NoError(t, err)
+	assert.Greater(t, toAfter.Revision, toBefore.Revision)
+	require.Len(t, toAfter.Containers, 1)
+	assert.Equal(t, container.ID, toAfter.Containers[0].ID)
+}
+
+func testContainerDelete(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{})
+	container := createContainer(t, containers, node.ID, "nginx")
+
+	deleted, err := containers.Delete(ctx, container.ID)
+	require.NoError(t, err)
+	assert.Equal(t, container, deleted)
+
+	_, err = containers.Get(ctx, container.ID)
+	assert.ErrorIs(t, err, usecase.ContainerNotFoundErr)
+	_, err = containers.Delete(ctx, container.ID)
+	assert.ErrorIs(t, err, usecase.ContainerNotFoundErr)
+}
+
+func testContainerEvents(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{})
+	container := createContainer(t, containers, node.ID, "nginx")
+
+	events, err := containers.ListEvents(ctx, container.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	createdAt := time.Now().UTC().Truncate(time.Millisecond)
+	recorded := []entity.ContainerEvent{
+		{ContainerID: container.ID, Reason: entity.EventReasonRescheduleFailed, Message: "no node", CreatedAt: createdAt},
+		{ContainerID: container.ID, Reason: entity.EventReasonRescheduled, Message: "moved", CreatedAt: createdAt.Add(time.Second)},
+	}
+	for i := range recorded {
+		require.NoError(t, containers.AddEvent(ctx, &recorded[i]))
+	}
+	events, err = containers.ListEvents(ctx, container.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	for i, event := range events {
+		assert.Equal(t, recorded[i].Reason, event.Reason)
+		assert.Equal(t, recorded[i].Message, event.Message)
+		assert.True(t, recorded[i].CreatedAt.Equal(event.CreatedAt))
+	}
+
+	unknown := entity.ContainerEvent{ContainerID: uuid.New(), Reason: entity.EventReasonRescheduled, CreatedAt: createdAt}
+	assert.ErrorIs(t, containers.AddEvent(ctx, &unknown), usecase.ContainerNotFoundErr)
+
+	_, err = containers.Delete(ctx, container.ID)
+	require.NoError(t, err)
+	_, err = containers.ListEvents(ctx, container.ID)
+	assert.ErrorIs(t, err, usecase.ContainerNotFoundErr)
+}
+
+func testContainerList(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	first := createNode(t, nodes, entity.Resources{})
+	second := createNode(t, nodes, entity.Resources{})
+	createContainer(t, containers, first.ID, "nginx:1.27")
+	createContainer(t, containers, first.ID, "redis:7")
+	running := createContainer(t, containers, second.ID, "nginx_mainline")
+	_, err := containers.Update(ctx, running.ID, func(c *entity.Container) error {
+		c.Status = entity.ContainerStatusCreating
+		c.RescheduleReason = "node failed"
+		return nil
+	})
+	require.NoError(t, err)
+
+	all, next, err := containers.List(ctx, entity.ListContainersOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, next)
+	assert.Len(t, all, 3)
+
+	onFirst, _, err := containers.List(ctx, entity.ListContainersOptions{NodeID: first.ID})
+	require.NoError(t, err)
+	assert.Len(t, onFirst, 2)
+
+	creating, _, err := containers.List(ctx, entity.ListContainersOptions{Status: entity.ContainerStatusCreating})
+	require.NoError(t, err)
+	require.Len(t, creating, 1)
+	assert.Equal(t, running.ID, creating[0].ID)
+
+	rescheduling, _, err := containers.List(ctx, entity.ListContainersOptions{Rescheduling: true})
+	require.NoError(t, err)
+	require.Len(t, rescheduling, 1)
+	assert.Equal(t, running.ID, rescheduling[0].ID)
+
+	nginx, _, err := containers.List(ctx, entity.ListContainersOptions{ImagePrefix: "nginx"})
+	require.NoError(t, err)
+	assert.Len(t, nginx, 2)
+
+	literal, _, err := containers.List(ctx, entity.ListContainersOptions{ImagePrefix: "nginx_"})
+	require.NoError(t, err)
+	require.Len(t, literal, 1, "LIKE wildcards in the prefix match literally")
+	assert.Equal(t, running.ID, literal[0].ID)
+
+	byImage, _, err := containers.List(ctx, entity.ListContainersOptions{
+		ListOptions: entity.ListOptions{SortBy: "image"},
+	})
+	require.NoError(t, err)
+	require.Len(t, byImage, 3)
+	assert.Equal(t, "nginx:1.27", byImage[0].Image)
+	assert.Equal(t, "redis:7", byImage[2].Image)
+}
+
+func testContainerListPagination(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{})
+	images := []string{"a", "b", "b", "c", "c", "c", "d"}
+	for _, image := range images {
+		createContainer(t, containers, node.ID, image)
+	}
+
+	var listed []string
+	seen := map[uuid.UUID]bool{}
+	opts := entity.ListContainersOptions{ListOptions: entity.ListOptions{Limit: 3, SortBy: "image"}}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3)
+		page, next, err := containers.List(ctx, opts)
+		require.NoError(t, err)
+		for _, container := range page {
+			assert.False(t, seen[container.ID], "container listed twice")
+			seen[container.ID] = true
+			listed = append(listed, container.Image)
+		}
+		if next == "" {
+			break
+		}
+		opts.Continue = next
+	}
+	assert.Equal(t, images, listed)
+
+	opts.Continue = ""
+	_, next, err := containers.List(ctx, opts)
+	require.NoError(t, err)
+	opts.SortBy = "status"
+	opts.Continue = next
+	_, _, err = containers.List(ctx, opts)
+	assert.ErrorIs(t, err, usecase.InvalidContinueTokenErr)
+}
+
+func testContainerListDescendingAll(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{})
+	for i := 0; i < 4; i++ {
+		createContainer(t, containers, node.ID, "nginx")
+	}
+
+	var ids []string
+	opts := entity.ListContainersOptions{ListOptions: entity.ListOptions{Limit: 1, Order: entity.SortDescending}}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 4)
+		page, next, err := containers.List(ctx, opts)
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		ids = append(ids, page[0].ID.String())
+		if next == "" {
+			break
+		}
+		opts.Continue = next
+	}
+	require.Len(t, ids, 4)
+	for i := 1; i < len(ids); i++ {
+		assert.Greater(t, ids[i-1], ids[i])
+	}
+}
+
+func testNodeListLabelSelector(t *testing.T, nodes usecase.NodeRepository, _ usecase.ContainerRepository) {
+	ctx := context.Background()
+	labels := []entity.Labels{
+		{"zone": "eu", "disk": "ssd"},
+		{"zone": "us", "disk": "hdd"},
+		{"zone": "ap"},
+		{},
+	}
+	ids := make([]uuid.UUID, len(labels))
+	for i, l := range labels {
+		node := entity.NewNode(entity.Resources{})
+		node.Labels = l
+		require.NoError(t, nodes.Create(ctx, node))
+		ids[i] = node.ID
+	}
+
+	cases := map[string][]uuid.UUID{
+		"zone=eu":             {ids[0]},
+		"zone==us":            {ids[1]},
+		"zone!=eu":            {ids[1], ids[2], ids[3]},
+		"zone in (eu,ap)":     {ids[0], ids[2]},
+		"zone notin (eu, ap)": {ids[1], ids[3]},
+		"disk":                {ids[0], ids[1]},
+		"!disk":               {ids[2], ids[3]},
+		"zone,disk!=ssd":      {ids[1], ids[2]},
+		"":                    ids,
+	}
+	for selector, expected := range cases {
+		listed, _, err := nodes.List(ctx, entity.ListNodesOptions{
+			ListOptions: entity.ListOptions{LabelSelector: mustParseSelector(t, selector)},
+		})
+		require.NoError(t, err, selector)
+		var got []uuid.UUID
+		for _, node := range listed {
+			got = append(got, node.ID)
+		}
+		assert.ElementsMatch(t, expected, got, selector)
+	}
+}
+
+func testContainerListLabelSelector(t *testing.T, nodes usecase.NodeRepository, containers usecase.ContainerRepository) {
+	ctx := context.Background()
+	node := createNode(t, nodes, entity.Resources{})
+
+	web := entity.NewContainer(node.ID, "nginx")
+	web.Labels = entity.Labels{"app": "web", "team": "core"}
+	require.NoError(t, containers.Create(ctx, web))
+	worker := entity.NewContainer(node.ID, "worker")
+	worker.Labels = entity.Labels{"app": "worker"}
+	require.NoError(t, containers.Create(ctx, worker))
+
+	got, err := containers.Get(ctx, web.ID)
+	require.NoError(t, err)
+	assert.Equal(t, web.Labels, got.Labels)
+
+	listed, _, err := containers.List(ctx, entity.ListContainersOptions{
+		ListOptions: entity.ListOptions{LabelSelector: mustParseSelector(t, "app in (web,api),team=core")},
+	})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, web.ID, listed[0].ID)
+
+	_, err = containers.Update(ctx, worker.ID, func(c *entity.Container) error {
+		c.Labels["team"] = "core"
+		return nil
+	})
+	require.NoError(t, err)
+
+	listed, _, err = containers.List(ctx, entity.ListContainersOptions{
+		ListOptions: entity.ListOptions{LabelSelector: mustParseSelector(t, "team=core")},
+	})
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+}
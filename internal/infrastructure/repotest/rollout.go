@@ -0,0 +1,118 @@
+package repotest
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+	"time"
+)
+
+var rolloutTests = map[string]func(t *testing.T, repos Repositories){
+	"RolloutCRUD":     testRolloutCRUD,
+	"RolloutList":     testRolloutList,
+	"RolloutConflict": testRolloutConflict,
+}
+
+func newRollout(containers ...uuid.UUID) *entity.Rollout {
+	rollout := entity.NewRollout(&entity.AddRollout{FromImage: "nginx:1.26", ToImage: "nginx:1.27", BatchSize: 2}, containers)
+	rollout.CreatedAt = rollout.CreatedAt.Truncate(time.Millisecond)
+	return rollout
+}
+
+func testRolloutCRUD(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	first, second := uuid.New(), uuid.New()
+	rollout := newRollout(first, second)
+	require.NoError(t, repos.Rollouts.Create(ctx, rollout))
+
+	got, err := repos.Rollouts.Get(ctx, rollout.ID)
+	require.NoError(t, err)
+	assert.True(t, rollout.CreatedAt.Equal(got.CreatedAt))
+	got.CreatedAt = rollout.CreatedAt
+	assert.Equal(t, rollout, got)
+
+	updated, err := repos.Rollouts.Update(ctx, rollout.ID, func(r *entity.Rollout) error {
+		r.ToImage = "nginx:latest"
+		r.Phase = entity.RolloutPhaseRollingBack
+		r.Paused = true
+		r.Message = "waiting"
+		r.Updated = []uuid.UUID{second}
+		r.Reverted = []uuid.UUID{first}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "nginx:1.27", updated.ToImage, "the images cannot change")
+
+	got, err = repos.Rollouts.Get(ctx, rollout.ID)
+	require.NoError(t, err)
+	got.CreatedAt = updated.CreatedAt
+	assert.Equal(t, updated, got)
+
+	_, err = repos.Rollouts.Update(ctx, rollout.ID, func(r *entity.Rollout) error {
+		r.Paused = false
+		return usecase.InvalidRolloutActionErr
+	})
+	assert.ErrorIs(t, err, usecase.InvalidRolloutActionErr)
+	got, err = repos.Rollouts.Get(ctx, rollout.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Paused)
+
+	_, err = repos.Rollouts.Get(ctx, uuid.New())
+	assert.ErrorIs(t, err, usecase.RolloutNotFoundErr)
+	_, err = repos.Rollouts.Update(ctx, uuid.New(), func(*entity.Rollout) error { return nil })
+	assert.ErrorIs(t, err, usecase.RolloutNotFoundErr)
+}
+
+func testRolloutList(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	for _, phase := range []entity.RolloutPhase{entity.RolloutPhaseFailed, entity.RolloutPhaseCompleted} {
+		rollout := newRollout()
+		rollout.Phase = phase
+		require.NoError(t, repos.Rollouts.Create(ctx, rollout))
+	}
+
+	page, next, err := repos.Rollouts.List(ctx, entity.ListOptions{Limit: 1, SortBy: "phase"})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, entity.RolloutPhaseCompleted, page[0].Phase)
+	assert.Empty(t, page[0].Containers)
+	require.NotEmpty(t, next)
+
+	page, next, err = repos.Rollouts.List(ctx, entity.ListOptions{Limit: 1, SortBy: "phase", Continue: next})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, entity.RolloutPhaseFailed, page[0].Phase)
+	assert.Empty(t, next)
+}
+
+func testRolloutConflict(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+	active := newRollout(first, second)
+	require.NoError(t, repos.Rollouts.Create(ctx, active))
+
+	assert.ErrorIs(t, repos.Rollouts.Create(ctx, newRollout(second, third)), usecase.RolloutConflictErr)
+	_, err := repos.Rollouts.Get(ctx, active.ID)
+	require.NoError(t, err)
+	require.NoError(t, repos.Rollouts.Create(ctx, newRollout(third)))
+
+	_, err = repos.Rollouts.Update(ctx, active.ID, func(r *entity.Rollout) error {
+		r.Phase = entity.RolloutPhaseCompleted
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, repos.Rollouts.Create(ctx, newRollout(first)), "completed rollouts release their containers")
+
+	_, err = repos.Rollouts.Update(ctx, active.ID, func(r *entity.Rollout) error {
+		r.Phase = entity.RolloutPhaseRollingBack
+		return nil
+	})
+	assert.ErrorIs(t, err, usecase.RolloutConflictErr)
+	got, err := repos.Rollouts.Get(ctx, active.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.RolloutPhaseCompleted, got.Phase)
+}
@@ -0,0 +1,64 @@
+// Package servicetest builds the usecase services on top of the memory
+// repositories for the tests of the controllers.
+package servicetest
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/internal/usecase/node"
+	"github.com/wensiet/morchy-api/internal/usecase/scheduler"
+	"github.com/wensiet/morchy-api/internal/usecase/watch"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+)
+
+// Services share one memory store and one watch bus.
+type Services struct {
+	Store      *memory.Store
+	Bus        *watch.Bus
+	Nodes      *node.Service
+	Containers *container.Service
+}
+
+// New returns services backed by an empty store.
+func New() Services {
+	store := memory.NewStore()
+	bus := watch.NewBus(10)
+	nodeService := node.NewService(memory.NewNodeRepository(store), bus)
+	return Services{
+		Store: store,
+		Bus:   bus,
+		Nodes: nodeService,
+		Containers: container.NewService(
+			memory.NewContainerRepository(store),
+			nodeService,
+			&scheduler.LeastLoaded{},
+			bus,
+		),
+	}
+}
+
+// RunningNode adds a node with capacity that containers can be scheduled on.
+func (s Services) RunningNode(t *testing.T, capacity entity.Resources) *entity.Node {
+	t.Helper()
+	ctx := context.Background()
+	target, err := s.Nodes.AddNode(ctx, &entity.AddNode{Capacity: capacity})
+	require.NoError(t, err)
+	target.Status = entity.RunningNodeStatus
+	require.NoError(t, s.Nodes.UpdateNode(ctx, target))
+	return target
+}
+
+// Owned lists the containers owned by the resource with id owner.
+func (s Services) Owned(t *testing.T, owner uuid.UUID) []entity.Container {
+	t.Helper()
+	containers, _, err := s.Containers.ListContainers(
+		context.Background(),
+		entity.ListContainersOptions{OwnerID: owner},
+	)
+	require.NoError(t, err)
+	return containers
+}
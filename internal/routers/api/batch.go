@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// errUnknownAction is reported for custom methods of a collection that do not exist.
+var errUnknownAction = errors.New("unknown action")
+
+// BatchItem godoc
+// api.BatchItem struct
+type BatchItem struct {
+	// Index is the position of the item in the request
+	Index int `json:"index" example:"0"`
+	// Status is the response status the item would have had as a single request
+	Status int `json:"status" example:"201"`
+	// ID is the id of a deleted item
+	ID *uuid.UUID `json:"id,omitempty"`
+	// Problem describes why the item failed
+	Problem *Problem `json:"problem,omitempty"`
+}
+
+// ContainerBatchItem godoc
+// api.ContainerBatchItem struct
+type ContainerBatchItem struct {
+	BatchItem
+	Container *entity.Container `json:"container,omitempty"`
+}
+
+// NodeBatchItem godoc
+// api.NodeBatchItem struct
+type NodeBatchItem struct {
+	BatchItem
+	Node *entity.Node `json:"node,omitempty"`
+}
+
+// ContainerBatchResult godoc
+// api.ContainerBatchResult struct
+type ContainerBatchResult struct {
+	Results []ContainerBatchItem `json:"results"`
+}
+
+// NodeBatchResult godoc
+// api.NodeBatchResult struct
+type NodeBatchResult struct {
+	Results []NodeBatchItem `json:"results"`
+}
+
+// BatchDeleteResult godoc
+// api.BatchDeleteResult struct
+type BatchDeleteResult struct {
+	Results []BatchItem `json:"results"`
+}
+
+// serveAction serves the custom method named after the colon of a path like
+// /api/v1/container:batchCreate, which is routed with the action parameter
+// right after the collection name.
+func serveAction(c *gin.Context, actions map[string]gin.HandlerFunc) {
+	name, found := strings.CutPrefix(c.Param("action"), ":")
+	action, ok := actions[name]
+	if !found || !ok {
+		_ = c.Error(fmt.Errorf("%w: %s", errUnknownAction, c.Request.URL.Path))
+		return
+	}
+	action(c)
+}
+
+// newBatchItems reports every item of a batch as succeeded with status or as
+// failed with the problem of its error.
+func newBatchItems(c *gin.Context, errs []error, status int) []BatchItem {
+	items := make([]BatchItem, len(errs))
+	for i, err := range errs {
+		items[i] = BatchItem{Index: i, Status: status}
+		if err != nil {
+			items[i].Problem = itemProblem(c, i, err)
+			items[i].Status = items[i].Problem.Status
+		}
+	}
+	return items
+}
+
+// deleteBatchItems reports the ids of a batch delete, deleted ones with 204.
+func deleteBatchItems(c *gin.Context, ids []uuid.UUID, errs []error) []BatchItem {
+	items := newBatchItems(c, errs, 204)
+	for i := range items {
+		items[i].ID = &ids[i]
+	}
+	return items
+}
+
+// itemProblem describes the failure of one item of a batch like writeProblem
+// describes failed requests.
+func itemProblem(c *gin.Context, index int, err error) *Problem {
+	status := errorStatus(err)
+	problem := &Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: c.Request.URL.Path,
+	}
+	if status == http.StatusInternalServerError {
+		log.Printf("api: %s %s: item %d: %v", c.Request.Method, c.Request.URL.Path, index, err)
+	} else {
+		problem.Detail = err.Error()
+	}
+	return problem
+}
+
+// writeBatch responds with the results of a batch. An atomic batch that
+// failed is reported as the problem of the item that failed it, with the
+// results of all items as the results member.
+func writeBatch(c *gin.Context, mode entity.BatchMode, errs []error, results any) {
+	if mode == entity.BatchModeAtomic {
+		for i, err := range errs {
+			if err != nil && !errors.Is(err, usecase.BatchAbortedErr) {
+				_ = c.Error(withProblemMembers(fmt.Errorf("item %d: %w", i, err), gin.H{"results": results}))
+				return
+			}
+		}
+	}
+	c.JSON(200, gin.H{"results": results})
+}
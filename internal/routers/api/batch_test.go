@@ -0,0 +1,170 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/routers/api"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/internal/usecase/node"
+	"github.com/wensiet/morchy-api/internal/usecase/scheduler"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func setupBatchRouter() *gin.Engine {
+	store := memory.NewStore()
+	nodeService := node.NewService(memory.NewNodeRepository(store), mockedBus)
+	containerService := container.NewService(
+		memory.NewContainerRepository(store),
+		nodeService,
+		&scheduler.LeastLoaded{},
+		mockedBus,
+	)
+	nr := api.NewNodeRouter(nodeService)
+	cr := api.NewContainerRouter(containerService)
+	r := gin.Default()
+	r.Use(api.ErrorHandler())
+
+	r.POST("/node:action", nr.NodeAction)
+	r.POST("/node", nr.AddNode)
+	r.GET("/node", nr.ListNodes)
+	r.POST("/container:action", cr.ContainerAction)
+	r.GET("/container", cr.ListContainers)
+	return r
+}
+
+type batchResponse struct {
+	Results []struct {
+		api.BatchItem
+		Node      json.RawMessage `json:"node"`
+		Container json.RawMessage `json:"container"`
+	} `json:"results"`
+	Status int `json:"status"`
+}
+
+func postBatch(t *testing.T, r *gin.Engine, path, body string) (int, batchResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	var resp batchResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return w.Code, resp
+}
+
+func countOf(t *testing.T, r *gin.Engine, path string) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	r.ServeHTTP(w, req)
+	var items []json.RawMessage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &items))
+	return len(items)
+}
+
+func TestBatch_Nodes(t *testing.T) {
+	r := setupBatchRouter()
+
+	code, resp := postBatch(t, r, "/node:batchCreate", `{"items": [{"capacity": {"cpu": 1000}}, {"capacity": {"cpu": -1}}]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Status)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, http.StatusConflict, resp.Results[0].Status, "the valid node was rolled back")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Results[1].Status)
+	require.NotNil(t, resp.Results[1].Problem)
+	assert.Equal(t, 1, resp.Results[1].Index)
+	assert.Equal(t, 0, countOf(t, r, "/node"))
+
+	body := `{"mode": "best_effort", "items": [{"capacity": {"cpu": 1000}}, {"capacity": {"cpu": -1}}, {}]}`
+	code, resp = postBatch(t, r, "/node:batchCreate", body)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Results, 3)
+	assert.Equal(t, http.StatusOK, resp.Results[0].Status)
+	assert.NotEmpty(t, resp.Results[0].Node)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Results[1].Status)
+	assert.Empty(t, resp.Results[1].Node)
+	assert.Equal(t, 2, countOf(t, r, "/node"))
+
+	var created struct {
+		ID              string `json:"id"`
+		ResourceVersion int64  `json:"resource_version"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Results[0].Node, &created))
+
+	code, resp = postBatch(t, r, "/node:batchUpdate", fmt.Sprintf(`{"items": [{"id": %q, "status": "running"}]}`, created.ID))
+	assert.Equal(t, http.StatusPreconditionRequired, code, "items without a resource_version are not applied")
+	assert.Equal(t, http.StatusPreconditionRequired, resp.Results[0].Status)
+	update := fmt.Sprintf(`{"items": [{"id": %q, "status": "running", "resource_version": %d}]}`, created.ID, created.ResourceVersion)
+	code, resp = postBatch(t, r, "/node:batchUpdate", update)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, http.StatusOK, resp.Results[0].Status)
+	assert.Contains(t, string(resp.Results[0].Node), `"status":"running"`)
+	code, _ = postBatch(t, r, "/node:batchUpdate", update)
+	assert.Equal(t, http.StatusConflict, code, "the resource_version is stale")
+
+	code, resp = postBatch(t, r, "/node:batchDelete", fmt.Sprintf(`{"ids": [%q]}`, created.ID))
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, http.StatusNoContent, resp.Results[0].Status)
+	require.NotNil(t, resp.Results[0].ID)
+	assert.Equal(t, created.ID, resp.Results[0].ID.String())
+	assert.Equal(t, 1, countOf(t, r, "/node"))
+
+	code, _ = postBatch(t, r, "/node:batchDelete", `{"mode": "all", "ids": []}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	code, _ = postBatch(t, r, "/node:batchMove", `{}`)
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = postBatch(t, r, "/node:batchCreate", `{"items": `)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestBatch_Containers(t *testing.T) {
+	r := setupBatchRouter()
+	_, resp := postBatch(t, r, "/node:batchCreate", `{"items": [{"capacity": {"cpu": 1000}}]}`)
+	var target struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Results[0].Node, &target))
+
+	items := fmt.Sprintf(`[
+		{"node_id": %[1]q, "image": "nginx", "resources": {"cpu": 600}},
+		{"node_id": %[1]q, "image": "redis", "resources": {"cpu": 600}}
+	]`, target.ID)
+	code, resp := postBatch(t, r, "/container:batchCreate", `{"items": `+items+`}`)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, http.StatusConflict, resp.Results[1].Status, "the first container takes the capacity")
+	assert.Equal(t, 0, countOf(t, r, "/container"))
+
+	code, resp = postBatch(t, r, "/container:batchCreate", `{"mode": "best_effort", "items": `+items+`}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, http.StatusCreated, resp.Results[0].Status)
+	assert.Equal(t, http.StatusConflict, resp.Results[1].Status)
+	assert.Equal(t, 1, countOf(t, r, "/container"))
+
+	var created struct {
+		ID              string `json:"id"`
+		ResourceVersion int64  `json:"resource_version"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Results[0].Container, &created))
+	update := fmt.Sprintf(`{"items": [{"id": %q, "image": "nginx:1.27", "status": "pending"}]}`, created.ID)
+	code, resp = postBatch(t, r, "/container:batchUpdate", update)
+	assert.Equal(t, http.StatusPreconditionRequired, code, "items without a resource_version are not applied")
+	assert.Equal(t, http.StatusPreconditionRequired, resp.Results[0].Status)
+	update = fmt.Sprintf(`{"items": [{"id": %q, "image": "nginx:1.27", "status": "pending", "resource_version": %d}]}`,
+		created.ID, created.ResourceVersion)
+	code, resp = postBatch(t, r, "/container:batchUpdate", update)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, http.StatusOK, resp.Results[0].Status)
+	assert.Contains(t, string(resp.Results[0].Container), "nginx:1.27")
+
+	code, resp = postBatch(t, r, "/container:batchDelete", fmt.Sprintf(`{"ids": [%q]}`, created.ID))
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, http.StatusNoContent, resp.Results[0].Status)
+	assert.Equal(t, 0, countOf(t, r, "/container"))
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// errNullBody is reported for a body of null where a JSON object is expected.
+var errNullBody = errors.New("the request body must be a JSON object, not null")
+
+// bindJSON binds the JSON body of the request into obj like ShouldBindJSON.
+// A body of null, which would leave obj untouched, is rejected as well. Errors
+// are invalid requests.
+func bindJSON(c *gin.Context, obj any) error {
+	if err := c.ShouldBindBodyWith(obj, binding.JSON); err != nil {
+		return invalidRequest(err)
+	}
+	if body, ok := c.Get(gin.BodyBytesKey); ok && bytes.Equal(bytes.TrimSpace(body.([]byte)), []byte("null")) {
+		return invalidRequest(errNullBody)
+	}
+	return nil
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
 	"github.com/wensiet/morchy-api/internal/usecase/container"
 	"github.com/wensiet/morchy-api/pkg/entity"
 )
@@ -12,7 +14,16 @@ type IContainerRouter interface {
 	ListContainers(c *gin.Context)
 	AddContainer(c *gin.Context)
 	UpdateContainer(c *gin.Context)
+	PatchContainer(c *gin.Context)
+	UpdateContainerStatus(c *gin.Context)
 	DeleteContainer(c *gin.Context)
+	GetContainerHistory(c *gin.Context)
+	GetContainerEvents(c *gin.Context)
+	DrainNode(c *gin.Context)
+	ContainerAction(c *gin.Context)
+	BatchAddContainers(c *gin.Context)
+	BatchUpdateContainers(c *gin.Context)
+	BatchDeleteContainers(c *gin.Context)
 }
 
 type ContainerRouter struct {
@@ -32,106 +43,268 @@ func NewContainerRouter(containerService container.IService) ContainerRouter {
 //	@Param			resource_id	path	string	true	"Container's ID"
 //	@Produce		json
 //	@Success		200	{object}	entity.Container
-//	@Failure		400	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
 //	@Router			/api/v1/container/{resource_id} [get]
 func (cr *ContainerRouter) GetContainer(c *gin.Context) {
 	idParam := c.Param("resource_id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		c.JSON(400, gin.H{
-			"message": err.Error(),
-		})
+		_ = c.Error(invalidRequest(err))
 		return
 	}
 
 	containerModel, err := cr.containerService.GetContainer(c, id)
 	if err != nil {
-		c.JSON(500, gin.H{
-			"message": err.Error(),
-			"kind":    "error",
-		})
+		_ = c.Error(err)
 		return
 	}
+	setETag(c, containerModel.ResourceVersion)
 	c.JSON(200, containerModel)
 }
 
 // ListContainers godoc
 //
 //	@Summary		List all containers
-//	@Description	Retrieves a list of all containers
+//	@Description	Retrieves a page of containers, the X-Continue-Token response header holds the token of the next page.
+//	@Description	With watch=true streams ADDED, MODIFIED and DELETED container events as Server-Sent Events instead.
 //	@Tags			Container
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{array}		entity.Container
-//	@Failure		500	{object}	map[string]string
+//	@Produce		text/event-stream
+//	@Param			limit			query		int		false	"Page size, 500 by default and at most 1000"
+//	@Param			continue		query		string	false	"Continue token of the previous page"
+//	@Param			sort			query		string	false	"Sort field"	Enums(id, image, status, node_id)
+//	@Param			order			query		string	false	"Sort order"	Enums(asc, desc)
+//	@Param			status			query		string	false	"Only containers with this status"
+//	@Param			node_id			query		string	false	"Only containers on this node"
+//	@Param			image_prefix	query		string	false	"Only containers whose image starts with this prefix"
+//	@Param			owner_id		query		string	false	"Only containers owned by this resource, e.g. a deployment"
+//	@Param			labelSelector	query		string	false	"Label selector, e.g. zone=eu,tier in (web,api),!legacy"
+//	@Param			watch			query		bool	false	"Stream container events"
+//	@Param			resourceVersion	query		int		false	"Resume the stream after this resource version"
+//	@Success		200				{array}		entity.Container
+//	@Failure		400				{object}	api.Problem
+//	@Failure		410				{object}	api.Problem
+//	@Failure		422				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
 //	@Router			/api/v1/container [get]
 func (cr *ContainerRouter) ListContainers(c *gin.Context) {
-	containers, err := cr.containerService.ListContainers(c)
+	if isWatch(c) {
+		serveWatch(c, cr.containerService.Watch)
+		return
+	}
+
+	listOptions, err := parseListOptions(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	opts := entity.ListContainersOptions{
+		ListOptions: listOptions,
+		Status:      entity.ContainerStatus(c.Query("status")),
+		ImagePrefix: c.Query("image_prefix"),
+	}
+	if nodeIDParam := c.Query("node_id"); nodeIDParam != "" {
+		opts.NodeID, err = uuid.Parse(nodeIDParam)
+		if err != nil {
+			_ = c.Error(fmt.Errorf("%w: node_id: %s", usecase.InvalidListOptionsErr, err))
+			return
+		}
+	}
+	if ownerIDParam := c.Query("owner_id"); ownerIDParam != "" {
+		opts.OwnerID, err = uuid.Parse(ownerIDParam)
+		if err != nil {
+			_ = c.Error(fmt.Errorf("%w: owner_id: %s", usecase.InvalidListOptionsErr, err))
+			return
+		}
+	}
+
+	containers, next, err := cr.containerService.ListContainers(c, opts)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	setContinueToken(c, next)
 	c.JSON(200, containers)
 }
 
 // AddContainer godoc
 //
 //	@Summary		Add a new container
-//	@Description	Creates a new container
+//	@Description	Creates a new container, scheduling it onto a running node when node_id is omitted.
+//	@Description	The node has to satisfy the placement rules, the reasons for the choice are returned in placement_reasons.
+//	@Description	Nodes with NoSchedule or NoExecute taints are only used when spec.tolerations tolerate them.
+//	@Description	Retries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.
 //	@Tags			Container
 //	@Accept			json
 //	@Produce		json
-//	@Param			container	body		entity.AddContainer	true	"New container data"
-//	@Success		201			{object}	entity.Container
-//	@Failure		400			{object}	map[string]string
-//	@Failure		500			{object}	map[string]string
+//	@Param			Idempotency-Key	header		string				false	"Key making retries of the request return the first response"
+//	@Param			container		body		entity.AddContainer	true	"New container data"
+//	@Success		201				{object}	entity.Container
+//	@Failure		400				{object}	api.Problem
+//	@Failure		404				{object}	api.Problem
+//	@Failure		409				{object}	api.Problem
+//	@Failure		413				{object}	api.Problem
+//	@Failure		422				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Failure		503				{object}	api.Problem
 //	@Router			/api/v1/container [post]
 func (cr *ContainerRouter) AddContainer(c *gin.Context) {
-	var req *entity.AddContainer
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": "Invalid input"})
+	var req entity.AddContainer
+	if err := bindJSON(c, &req); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
-	containerModel, err := cr.containerService.AddContainer(c, req.NodeID, req.Image)
+	containerModel, err := cr.containerService.AddContainer(c, &req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	setETag(c, containerModel.ResourceVersion)
 	c.JSON(201, containerModel)
 }
 
 // UpdateContainer godoc
 //
 //	@Summary		Update an existing container
-//	@Description	Updates the details of an existing container
+//	@Description	Updates the details of an existing container, labels are kept when omitted.
+//	@Description	The state reported by node agents is ignored, it is set through the status sub-resource.
+//	@Description	The update has to be based on the current resource version, sent as the ETag in If-Match or as resource_version in the body, a stale one fails with 409.
 //	@Tags			Container
 //	@Accept			json
 //	@Produce		json
+//	@Param			If-Match	header	string				false	"ETag of the container the update is based on"
 //	@Param			container	body	entity.Container	true	"Updated container data"
 //	@Success		204
-//	@Failure		422	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Header			204	{string}	ETag	"Resource version of the updated container"
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		409	{object}	api.Problem
+//	@Failure		422	{object}	api.Problem
+//	@Failure		428	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
 //	@Router			/api/v1/container [put]
 func (cr *ContainerRouter) UpdateContainer(c *gin.Context) {
-	var containerModel *entity.Container
+	var containerModel entity.Container
 
-	if err := c.ShouldBindJSON(&containerModel); err != nil {
-		c.JSON(422, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &containerModel); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	version, err := expectedVersion(c, containerModel.ResourceVersion)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
+	containerModel.ResourceVersion = version
 
-	if err := cr.containerService.UpdateContainer(c, containerModel); err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+	err = cr.containerService.UpdateContainer(c, &containerModel)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
+	setETag(c, containerModel.ResourceVersion)
 	c.JSON(204, gin.H{})
 }
 
+// PatchContainer godoc
+//
+//	@Summary		Patch a container
+//	@Description	Applies a JSON Merge Patch (application/merge-patch+json) or a JSON Patch (application/json-patch+json) to the container.
+//	@Description	Only the image, spec and labels can be changed, patches to other fields fail with 422. The status is reported through PUT /container/{resource_id}/status.
+//	@Description	An ETag in If-Match, or else the resource_version of the patched container, has to match the current one.
+//	@Description	A failed test operation of a JSON Patch fails with 412.
+//	@Tags			Container
+//	@Accept			application/merge-patch+json,application/json-patch+json
+//	@Produce		json
+//	@Param			resource_id	path		string	true	"Container's ID"
+//	@Param			If-Match	header		string	false	"ETag of the container the patch is based on"
+//	@Param			patch		body		object	true	"Patch document"
+//	@Success		200			{object}	entity.Container
+//	@Header			200			{string}	ETag	"Resource version of the patched container"
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		409			{object}	api.Problem
+//	@Failure		412			{object}	api.Problem
+//	@Failure		413			{object}	api.Problem
+//	@Failure		415			{object}	api.Problem
+//	@Failure		422			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/container/{resource_id} [patch]
+func (cr *ContainerRouter) PatchContainer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	p, err := readPatch(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	containerModel, err := cr.containerService.PatchContainer(c, id, p, version)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setETag(c, containerModel.ResourceVersion)
+	c.JSON(200, containerModel)
+}
+
+// UpdateContainerStatus godoc
+//
+//	@Summary		Report the status of a container
+//	@Description	Stores the status and state reported by the node agent without touching the container's spec.
+//	@Description	The state replaces the previous one, exit_code, signal, oom_killed and finished_at are only accepted with the stopped, exited and failed statuses.
+//	@Description	Agents report the results of the liveness and readiness probes in state.liveness and state.readiness, the Live and Ready conditions are derived from them.
+//	@Tags			Container
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource_id	path		string							true	"Container's ID"
+//	@Param			status		body		entity.UpdateContainerStatus	true	"Reported status"
+//	@Success		200			{object}	entity.Container
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		409			{object}	api.Problem
+//	@Failure		422			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/container/{resource_id}/status [put]
+func (cr *ContainerRouter) UpdateContainerStatus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	var req entity.UpdateContainerStatus
+	if err = bindJSON(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	containerModel, err := cr.containerService.UpdateStatus(c, id, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setETag(c, containerModel.ResourceVersion)
+	c.JSON(200, containerModel)
+}
+
 // DeleteContainer godoc
 //
 //	@Summary		Delete a container
@@ -141,22 +314,237 @@ func (cr *ContainerRouter) UpdateContainer(c *gin.Context) {
 //	@Produce		json
 //	@Param			resource_id	path	string	true	"Container's ID"
 //	@Success		204
-//	@Failure		400	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
 //	@Router			/api/v1/container/{resource_id} [delete]
 func (cr *ContainerRouter) DeleteContainer(c *gin.Context) {
-	idParam := c.Param("id")
+	idParam := c.Param("resource_id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		_ = c.Error(invalidRequest(err))
 		return
 	}
 
 	err = cr.containerService.RemoveContainer(c, id)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(204, gin.H{})
 }
+
+// GetContainerHistory godoc
+//
+//	@Summary		Get container status history
+//	@Description	Lists every status change of a container, oldest first
+//	@Tags			Container
+//	@Accept			json
+//	@Param			resource_id	path	string	true	"Container's ID"
+//	@Produce		json
+//	@Success		200	{array}		entity.ContainerStatusChange
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
+//	@Router			/api/v1/container/{resource_id}/history [get]
+func (cr *ContainerRouter) GetContainerHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	history, err := cr.containerService.ListStatusHistory(c, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(200, history)
+}
+
+// GetContainerEvents godoc
+//
+//	@Summary		Get container events
+//	@Description	Lists the events recorded for a container, like moves to other nodes, oldest first
+//	@Tags			Container
+//	@Accept			json
+//	@Param			resource_id	path	string	true	"Container's ID"
+//	@Produce		json
+//	@Success		200	{array}		entity.ContainerEvent
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
+//	@Router			/api/v1/container/{resource_id}/events [get]
+func (cr *ContainerRouter) GetContainerEvents(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	events, err := cr.containerService.ListEvents(c, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(200, events)
+}
+
+// DrainNode godoc
+//
+//	@Summary		Drain a node
+//	@Description	Cordons the node and moves every container on it to other nodes chosen by the scheduler.
+//	@Description	Containers that cannot be moved stay on the node and are listed in failed, the response is 409 then.
+//	@Tags			Node
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource_id	path		string	true	"Node's ID"
+//	@Success		200			{object}	entity.NodeDrain
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		409			{object}	api.Problem	"Problem with the node_id, moved and failed members of the drain"
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/node/{resource_id}/drain [post]
+func (cr *ContainerRouter) DrainNode(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	drain, err := cr.containerService.DrainNode(c, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if len(drain.Failed) > 0 {
+		err = fmt.Errorf("%w: %d of %d failed", usecase.DrainIncompleteErr, len(drain.Failed), len(drain.Failed)+len(drain.Moved))
+		_ = c.Error(withProblemMembers(err, gin.H{"node_id": drain.NodeID, "moved": drain.Moved, "failed": drain.Failed}))
+		return
+	}
+	c.JSON(200, drain)
+}
+
+// ContainerAction serves the batch methods of the container collection.
+func (cr *ContainerRouter) ContainerAction(c *gin.Context) {
+	serveAction(c, map[string]gin.HandlerFunc{
+		"batchCreate": cr.BatchAddContainers,
+		"batchUpdate": cr.BatchUpdateContainers,
+		"batchDelete": cr.BatchDeleteContainers,
+	})
+}
+
+// BatchAddContainers godoc
+//
+//	@Summary		Add containers in a batch
+//	@Description	Creates up to 500 containers like the single create does, in one transaction, scheduling each against the capacity left by the ones before it.
+//	@Description	In atomic mode, the default, a failed item fails the whole batch with its status and the results member lists every item; items that were rolled back fail with 409.
+//	@Description	In best_effort mode the items that succeed are created and the response is 200 with the result of every item.
+//	@Description	Retries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.
+//	@Tags			Container
+//	@Accept			json
+//	@Produce		json
+//	@Param			Idempotency-Key	header		string						false	"Key making retries of the request return the first response"
+//	@Param			batch			body		entity.BatchAddContainers	true	"New containers"
+//	@Success		200				{object}	api.ContainerBatchResult
+//	@Failure		400				{object}	api.Problem
+//	@Failure		404				{object}	api.Problem
+//	@Failure		409				{object}	api.Problem
+//	@Failure		413				{object}	api.Problem
+//	@Failure		422				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Failure		503				{object}	api.Problem
+//	@Router			/api/v1/container:batchCreate [post]
+func (cr *ContainerRouter) BatchAddContainers(c *gin.Context) {
+	var req entity.BatchAddContainers
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	containers, errs, err := cr.containerService.AddContainers(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	writeBatch(c, req.Mode, errs, containerBatchItems(c, containers, errs, 201))
+}
+
+// BatchUpdateContainers godoc
+//
+//	@Summary		Update containers in a batch
+//	@Description	Updates up to 500 containers like the single update does, in one transaction.
+//	@Description	Every item has to carry the resource_version it is based on, items without one fail with 428 and a stale one fails the item with 409.
+//	@Description	In atomic mode, the default, a failed item fails the whole batch with its status and the results member lists every item; items that were rolled back fail with 409.
+//	@Description	In best_effort mode the items that succeed are updated and the response is 200 with the result of every item.
+//	@Tags			Container
+//	@Accept			json
+//	@Produce		json
+//	@Param			batch	body		entity.BatchUpdateContainers	true	"Updated containers"
+//	@Success		200		{object}	api.ContainerBatchResult
+//	@Failure		400		{object}	api.Problem
+//	@Failure		404		{object}	api.Problem
+//	@Failure		409		{object}	api.Problem
+//	@Failure		422		{object}	api.Problem
+//	@Failure		428		{object}	api.Problem
+//	@Failure		500		{object}	api.Problem
+//	@Router			/api/v1/container:batchUpdate [post]
+func (cr *ContainerRouter) BatchUpdateContainers(c *gin.Context) {
+	var req entity.BatchUpdateContainers
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	containers, errs, err := cr.containerService.UpdateContainers(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	writeBatch(c, req.Mode, errs, containerBatchItems(c, containers, errs, 200))
+}
+
+// BatchDeleteContainers godoc
+//
+//	@Summary		Delete containers in a batch
+//	@Description	Deletes up to 500 containers by their IDs in one transaction.
+//	@Description	In atomic mode, the default, a failed item fails the whole batch with its status and the results member lists every item; items that were rolled back fail with 409.
+//	@Description	In best_effort mode the items that succeed are deleted and the response is 200 with the result of every item.
+//	@Tags			Container
+//	@Accept			json
+//	@Produce		json
+//	@Param			batch	body		entity.BatchDelete	true	"IDs of the containers"
+//	@Success		200		{object}	api.BatchDeleteResult
+//	@Failure		400		{object}	api.Problem
+//	@Failure		404		{object}	api.Problem
+//	@Failure		409		{object}	api.Problem
+//	@Failure		422		{object}	api.Problem
+//	@Failure		500		{object}	api.Problem
+//	@Router			/api/v1/container:batchDelete [post]
+func (cr *ContainerRouter) BatchDeleteContainers(c *gin.Context) {
+	var req entity.BatchDelete
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	errs, err := cr.containerService.RemoveContainers(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	writeBatch(c, req.Mode, errs, deleteBatchItems(c, req.IDs, errs))
+}
+
+// containerBatchItems reports the containers of a batch, created or updated
+// ones with status.
+func containerBatchItems(c *gin.Context, containers []*entity.Container, errs []error, status int) []ContainerBatchItem {
+	items := make([]ContainerBatchItem, len(errs))
+	for i, item := range newBatchItems(c, errs, status) {
+		items[i] = ContainerBatchItem{BatchItem: item, Container: containers[i]}
+	}
+	return items
+}
@@ -0,0 +1,82 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/routers/api"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/internal/usecase/node"
+	"github.com/wensiet/morchy-api/internal/usecase/scheduler"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// setupContainerRouter serves the container routes from a memory store with
+// one node, whose id is returned.
+func setupContainerRouter(t *testing.T) (*gin.Engine, *container.Service, uuid.UUID) {
+	store := memory.NewStore()
+	nodeService := node.NewService(memory.NewNodeRepository(store), mockedBus)
+	containerService := container.NewService(
+		memory.NewContainerRepository(store),
+		nodeService,
+		&scheduler.LeastLoaded{},
+		mockedBus,
+	)
+	target, err := nodeService.AddNode(context.Background(), &entity.AddNode{})
+	require.NoError(t, err)
+
+	cr := api.NewContainerRouter(containerService)
+	r := gin.Default()
+	r.Use(api.ErrorHandler())
+	r.POST("/container", cr.AddContainer)
+	r.PUT("/container", cr.UpdateContainer)
+	r.PUT("/container/:resource_id/status", cr.UpdateContainerStatus)
+	return r, containerService, target.ID
+}
+
+func sendJSON(r *gin.Engine, method, path, body string) int {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestContainerRouter_AddContainer(t *testing.T) {
+	r, _, nodeID := setupContainerRouter(t)
+
+	body := fmt.Sprintf(`{"node_id": %q, "image": "nginx", "resources": {"cpu": 100}}`, nodeID)
+	assert.Equal(t, http.StatusCreated, sendJSON(r, http.MethodPost, "/container", body))
+	body = fmt.Sprintf(`{"node_id": %q, "image": "nginx", "resources": {"cpu": -1}}`, nodeID)
+	assert.Equal(t, http.StatusUnprocessableEntity, sendJSON(r, http.MethodPost, "/container", body))
+	assert.Equal(t, http.StatusBadRequest, sendJSON(r, http.MethodPost, "/container", `null`))
+}
+
+func TestContainerRouter_UpdateContainer(t *testing.T) {
+	r, containerService, nodeID := setupContainerRouter(t)
+	created, err := containerService.AddContainer(context.Background(), &entity.AddContainer{NodeID: nodeID, Image: "nginx"})
+	require.NoError(t, err)
+
+	body := fmt.Sprintf(`{"id": %q, "image": "nginx:1.27", "status": "pending", "resource_version": %d}`, created.ID, created.ResourceVersion)
+	assert.Equal(t, http.StatusNoContent, sendJSON(r, http.MethodPut, "/container", body))
+	assert.Equal(t, http.StatusBadRequest, sendJSON(r, http.MethodPut, "/container", `null`))
+}
+
+func TestContainerRouter_UpdateContainerStatus(t *testing.T) {
+	r, containerService, nodeID := setupContainerRouter(t)
+	created, err := containerService.AddContainer(context.Background(), &entity.AddContainer{NodeID: nodeID, Image: "nginx"})
+	require.NoError(t, err)
+	path := fmt.Sprintf("/container/%s/status", created.ID)
+
+	assert.Equal(t, http.StatusOK, sendJSON(r, http.MethodPut, path, `{"status": "creating"}`))
+	assert.Equal(t, http.StatusBadRequest, sendJSON(r, http.MethodPut, path, `null`))
+	assert.Equal(t, http.StatusBadRequest, sendJSON(r, http.MethodPut, path, `{"status": `))
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase/cronjob"
+	"github.com/wensiet/morchy-api/pkg/entity"
+)
+
+type ICronJobRouter interface {
+	GetCronJob(c *gin.Context)
+	ListCronJobs(c *gin.Context)
+	AddCronJob(c *gin.Context)
+	UpdateCronJob(c *gin.Context)
+	DeleteCronJob(c *gin.Context)
+}
+
+type CronJobRouter struct {
+	cronJobService cronjob.IService
+}
+
+func NewCronJobRouter(cronJobService cronjob.IService) CronJobRouter {
+	return CronJobRouter{cronJobService: cronJobService}
+}
+
+// GetCronJob godoc
+//
+//	@Summary		Get cron job by id
+//	@Description	Allows to get a cron job by its ID
+//	@Tags			CronJob
+//	@Accept			json
+//	@Param			resource_id	path	string	true	"Cron job's ID"
+//	@Produce		json
+//	@Success		200	{object}	entity.CronJob
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
+//	@Router			/api/v1/cronjob/{resource_id} [get]
+func (cr *CronJobRouter) GetCronJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	cronJobModel, err := cr.cronJobService.GetCronJob(c, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(200, cronJobModel)
+}
+
+// ListCronJobs godoc
+//
+//	@Summary		List all cron jobs
+//	@Description	Retrieves a page of cron jobs, the X-Continue-Token response header holds the token of the next page.
+//	@Tags			CronJob
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit		query		int		false	"Page size, 500 by default and at most 1000"
+//	@Param			continue	query		string	false	"Continue token of the previous page"
+//	@Param			sort		query		string	false	"Sort field"	Enums(id, name)
+//	@Param			order		query		string	false	"Sort order"	Enums(asc, desc)
+//	@Success		200			{array}		entity.CronJob
+//	@Failure		400			{object}	api.Problem
+//	@Failure		422			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/cronjob [get]
+func (cr *CronJobRouter) ListCronJobs(c *gin.Context) {
+	opts, err := parseListOptions(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	cronJobs, next, err := cr.cronJobService.ListCronJobs(c, opts)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setContinueToken(c, next)
+	c.JSON(200, cronJobs)
+}
+
+// AddCronJob godoc
+//
+//	@Summary		Add a new cron job
+//	@Description	Creates a new cron job, the controller then creates a job from job_template whenever the schedule is due.
+//	@Description	The schedule is a standard five field cron expression in UTC or one of the @hourly, @daily, @weekly, @monthly and @yearly macros.
+//	@Description	concurrency_policy decides what happens when the previous job is still active: Allow runs both, Forbid skips the new run and Replace deletes the active job.
+//	@Description	Retries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.
+//	@Tags			CronJob
+//	@Accept			json
+//	@Produce		json
+//	@Param			Idempotency-Key	header		string				false	"Key making retries of the request return the first response"
+//	@Param			cronjob			body		entity.AddCronJob	true	"New cron job data"
+//	@Success		201				{object}	entity.CronJob
+//	@Failure		400				{object}	api.Problem
+//	@Failure		409				{object}	api.Problem
+//	@Failure		413				{object}	api.Problem
+//	@Failure		422				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Router			/api/v1/cronjob [post]
+func (cr *CronJobRouter) AddCronJob(c *gin.Context) {
+	var req entity.AddCronJob
+	if err := bindJSON(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	cronJobModel, err := cr.cronJobService.AddCronJob(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(201, cronJobModel)
+}
+
+// UpdateCronJob godoc
+//
+//	@Summary		Update an existing cron job
+//	@Description	Updates the schedule, concurrency policy, suspension, history limits and job template of a cron job.
+//	@Description	The name and status cannot be changed, jobs created already keep their template.
+//	@Tags			CronJob
+//	@Accept			json
+//	@Produce		json
+//	@Param			cronjob	body		entity.CronJob	true	"Updated cron job data"
+//	@Success		200		{object}	entity.CronJob
+//	@Failure		400		{object}	api.Problem
+//	@Failure		404		{object}	api.Problem
+//	@Failure		422		{object}	api.Problem
+//	@Failure		500		{object}	api.Problem
+//	@Router			/api/v1/cronjob [put]
+func (cr *CronJobRouter) UpdateCronJob(c *gin.Context) {
+	var cronJobModel entity.CronJob
+	if err := bindJSON(c, &cronJobModel); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	updated, err := cr.cronJobService.UpdateCronJob(c, &cronJobModel)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(200, updated)
+}
+
+// DeleteCronJob godoc
+//
+//	@Summary		Delete a cron job
+//	@Description	Deletes a cron job by its ID together with its jobs and their containers
+//	@Tags			CronJob
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource_id	path	string	true	"Cron job's ID"
+//	@Success		204
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
+//	@Router			/api/v1/cronjob/{resource_id} [delete]
+func (cr *CronJobRouter) DeleteCronJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	err = cr.cronJobService.DeleteCronJob(c, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(204, gin.H{})
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase/deployment"
+	"github.com/wensiet/morchy-api/pkg/entity"
+)
+
+type IDeploymentRouter interface {
+	GetDeployment(c *gin.Context)
+	ListDeployments(c *gin.Context)
+	AddDeployment(c *gin.Context)
+	UpdateDeployment(c *gin.Context)
+	DeleteDeployment(c *gin.Context)
+}
+
+type DeploymentRouter struct {
+	deploymentService deployment.IService
+}
+
+func NewDeploymentRouter(deploymentService deployment.IService) DeploymentRouter {
+	return DeploymentRouter{deploymentService: deploymentService}
+}
+
+// GetDeployment godoc
+//
+//	@Summary		Get deployment by id
+//	@Description	Allows to get a deployment by its ID
+//	@Tags			Deployment
+//	@Accept			json
+//	@Param			resource_id	path	string	true	"Deployment's ID"
+//	@Produce		json
+//	@Success		200	{object}	entity.Deployment
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
+//	@Router			/api/v1/deployment/{resource_id} [get]
+func (dr *DeploymentRouter) GetDeployment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	deploymentModel, err := dr.deploymentService.GetDeployment(c, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(200, deploymentModel)
+}
+
+// ListDeployments godoc
+//
+//	@Summary		List all deployments
+//	@Description	Retrieves a page of deployments, the X-Continue-Token response header holds the token of the next page.
+//	@Tags			Deployment
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit		query		int		false	"Page size, 500 by default and at most 1000"
+//	@Param			continue	query		string	false	"Continue token of the previous page"
+//	@Param			sort		query		string	false	"Sort field"	Enums(id, name)
+//	@Param			order		query		string	false	"Sort order"	Enums(asc, desc)
+//	@Success		200			{array}		entity.Deployment
+//	@Failure		400			{object}	api.Problem
+//	@Failure		422			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/deployment [get]
+func (dr *DeploymentRouter) ListDeployments(c *gin.Context) {
+	opts, err := parseListOptions(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	deployments, next, err := dr.deploymentService.ListDeployments(c, opts)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setContinueToken(c, next)
+	c.JSON(200, deployments)
+}
+
+// AddDeployment godoc
+//
+//	@Summary		Add a new deployment
+//	@Description	Creates a new deployment, the controller then creates replicas containers from the template.
+//	@Description	The containers are owned by the deployment and removed together with it.
+//	@Description	Retries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.
+//	@Tags			Deployment
+//	@Accept			json
+//	@Produce		json
+//	@Param			Idempotency-Key	header		string					false	"Key making retries of the request return the first response"
+//	@Param			deployment		body		entity.AddDeployment	true	"New deployment data"
+//	@Success		201				{object}	entity.Deployment
+//	@Failure		400				{object}	api.Problem
+//	@Failure		409				{object}	api.Problem
+//	@Failure		413				{object}	api.Problem
+//	@Failure		422				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Router			/api/v1/deployment [post]
+func (dr *DeploymentRouter) AddDeployment(c *gin.Context) {
+	var req entity.AddDeployment
+	if err := bindJSON(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	deploymentModel, err := dr.deploymentService.AddDeployment(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(201, deploymentModel)
+}
+
+// UpdateDeployment godoc
+//
+//	@Summary		Update an existing deployment
+//	@Description	Updates the replica count and template of a deployment, the name and status cannot be changed.
+//	@Description	Template changes apply to containers created afterwards.
+//	@Tags			Deployment
+//	@Accept			json
+//	@Produce		json
+//	@Param			deployment	body		entity.Deployment	true	"Updated deployment data"
+//	@Success		200			{object}	entity.Deployment
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		422			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/deployment [put]
+func (dr *DeploymentRouter) UpdateDeployment(c *gin.Context) {
+	var deploymentModel entity.Deployment
+	if err := bindJSON(c, &deploymentModel); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	updated, err := dr.deploymentService.UpdateDeployment(c, &deploymentModel)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(200, updated)
+}
+
+// DeleteDeployment godoc
+//
+//	@Summary		Delete a deployment
+//	@Description	Deletes a deployment by its ID together with its containers
+//	@Tags			Deployment
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource_id	path	string	true	"Deployment's ID"
+//	@Success		204
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
+//	@Router			/api/v1/deployment/{resource_id} [delete]
+func (dr *DeploymentRouter) DeleteDeployment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	err = dr.deploymentService.DeleteDeployment(c, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(204, gin.H{})
+}
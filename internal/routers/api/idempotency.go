@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/wensiet/morchy-api/internal/usecase/idempotency"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+)
+
+const (
+	// IdempotencyKeyHeader carries the key clients choose to make a POST request safe to retry.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks responses that were stored for an earlier request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotentBodySize limits the bodies of requests with an
+	// Idempotency-Key, they are held in memory to be hashed. Larger ones fail with 413.
+	maxIdempotentBodySize = 1 << 20
+)
+
+// errRequestTooLarge is reported for request bodies larger than they may be.
+var errRequestTooLarge = errors.New("request body is too large")
+
+// Idempotency answers retries of POST requests sent with an Idempotency-Key
+// header with the stored response of the first request instead of processing
+// them again. Responses with a 5xx status are not stored, so that retries are
+// processed again. It has to wrap ErrorHandler to store problem responses too.
+// The bodies of the streaming routes, given as full paths like
+// /api/v1/container/:resource_id/logs, are not read and their requests are
+// passed through as they are.
+func Idempotency(service idempotency.IService, streaming ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" || slices.Contains(streaming, c.FullPath()) {
+			c.Next()
+			return
+		}
+		if len(key) > entity.MaxIdempotencyKeyLength {
+			abortWithProblem(c, invalidRequest(fmt.Errorf("%s must be at most %d characters", IdempotencyKeyHeader, entity.MaxIdempotencyKeyLength)))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			abortWithProblem(c, fmt.Errorf("%w: larger than %d bytes", errRequestTooLarge, tooLarge.Limit))
+			return
+		}
+		if err != nil {
+			abortWithProblem(c, invalidRequest(err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		stored, err := service.Begin(c, key, requestHash(c.Request, body))
+		if err != nil {
+			abortWithProblem(c, err)
+			return
+		}
+		if stored != nil {
+			for name, values := range stored.Header {
+				c.Writer.Header()[name] = values
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(stored.Status, stored.Header.Get("Content-Type"), stored.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// The outcome is stored even when the client has gone away meanwhile.
+		ctx := context.WithoutCancel(c.Request.Context())
+		if recorder.Status() >= http.StatusInternalServerError {
+			err = service.Abandon(ctx, key)
+		} else {
+			err = service.Complete(ctx, key, &entity.IdempotentResponse{
+				Status: recorder.Status(),
+				Header: recorder.Header().Clone(),
+				Body:   recorder.body.Bytes(),
+			})
+		}
+		if err != nil {
+			log.Printf("api: idempotency key %q: %v", key, err)
+		}
+	}
+}
+
+// requestHash identifies a request by its method, URI and body.
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.RequestURI())
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func abortWithProblem(c *gin.Context, err error) {
+	c.Abort()
+	writeProblem(c, err)
+}
+
+// responseRecorder keeps a copy of the response body written through it.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,113 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/routers/api"
+	"github.com/wensiet/morchy-api/internal/usecase/idempotency"
+	"github.com/wensiet/morchy-api/internal/usecase/node"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setupIdempotencyRouter(failures *int) *gin.Engine {
+	store := memory.NewStore()
+	nr := api.NewNodeRouter(node.NewService(memory.NewNodeRepository(store), mockedBus))
+	r := gin.Default()
+	r.Use(api.Idempotency(idempotency.NewService(memory.NewIdempotencyRepository(store), time.Minute), "/stream"))
+	r.Use(api.ErrorHandler())
+
+	r.POST("/node", nr.AddNode)
+	r.GET("/nodes", nr.ListNodes)
+	r.POST("/fail", func(c *gin.Context) {
+		*failures++
+		_ = c.Error(errors.New("database is down"))
+	})
+	r.POST("/stream", func(c *gin.Context) {
+		n, _ := io.Copy(io.Discard, c.Request.Body)
+		c.String(http.StatusOK, "%d", n)
+	})
+	return r
+}
+
+func postWithKey(r *gin.Engine, path, key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(api.IdempotencyKeyHeader, key)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysRetries(t *testing.T) {
+	var failures int
+	r := setupIdempotencyRouter(&failures)
+	body := `{"capacity": {"cpu": 1000}}`
+
+	first := postWithKey(r, "/node", "agent-1", body)
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Empty(t, first.Header().Get(api.IdempotentReplayedHeader))
+	var created entity.Node
+	require.NoError(t, json.Unmarshal(first.Body.Bytes(), &created))
+
+	retry := postWithKey(r, "/node", "agent-1", body)
+	require.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(api.IdempotentReplayedHeader))
+	assert.Equal(t, first.Header().Get("ETag"), retry.Header().Get("ETag"))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+
+	mismatch := postWithKey(r, "/node", "agent-1", `{"capacity": {"cpu": 2000}}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, mismatch.Code)
+	assert.Equal(t, api.ProblemContentType, mismatch.Header().Get("Content-Type"))
+
+	assert.Equal(t, http.StatusOK, postWithKey(r, "/node", "agent-2", body).Code)
+	assert.Equal(t, http.StatusOK, postWithKey(r, "/node", "", body).Code)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/nodes", nil)
+	r.ServeHTTP(w, req)
+	var nodes []entity.Node
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &nodes))
+	assert.Len(t, nodes, 3, "the retry did not create another node")
+}
+
+func TestIdempotency_RetriesServerErrors(t *testing.T) {
+	var failures int
+	r := setupIdempotencyRouter(&failures)
+
+	assert.Equal(t, http.StatusInternalServerError, postWithKey(r, "/fail", "key", "").Code)
+	assert.Equal(t, http.StatusInternalServerError, postWithKey(r, "/fail", "key", "").Code)
+	assert.Equal(t, 2, failures, "server errors are not stored")
+
+	long := strings.Repeat("k", entity.MaxIdempotencyKeyLength+1)
+	assert.Equal(t, http.StatusBadRequest, postWithKey(r, "/fail", long, "").Code)
+	assert.Equal(t, 2, failures)
+}
+
+func TestIdempotency_LimitsBodies(t *testing.T) {
+	var failures int
+	r := setupIdempotencyRouter(&failures)
+	large := strings.Repeat(" ", 2<<20)
+
+	w := postWithKey(r, "/node", "large", `{"capacity": {"cpu": 1000}}`+large)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, api.ProblemContentType, w.Header().Get("Content-Type"))
+
+	w = postWithKey(r, "/stream", "stream", large)
+	require.Equal(t, http.StatusOK, w.Code, "streaming routes are passed through")
+	assert.Equal(t, fmt.Sprint(len(large)), w.Body.String())
+	w = postWithKey(r, "/stream", "stream", large)
+	assert.Empty(t, w.Header().Get(api.IdempotentReplayedHeader))
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/job"
+	"github.com/wensiet/morchy-api/pkg/entity"
+)
+
+type IJobRouter interface {
+	GetJob(c *gin.Context)
+	ListJobs(c *gin.Context)
+	AddJob(c *gin.Context)
+	DeleteJob(c *gin.Context)
+}
+
+type JobRouter struct {
+	jobService job.IService
+}
+
+func NewJobRouter(jobService job.IService) JobRouter {
+	return JobRouter{jobService: jobService}
+}
+
+// GetJob godoc
+//
+//	@Summary		Get job by id
+//	@Description	Allows to get a job by its ID, status.runs holds the exit codes of its finished containers
+//	@Tags			Job
+//	@Accept			json
+//	@Param			resource_id	path	string	true	"Job's ID"
+//	@Produce		json
+//	@Success		200	{object}	entity.Job
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
+//	@Router			/api/v1/job/{resource_id} [get]
+func (jr *JobRouter) GetJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	jobModel, err := jr.jobService.GetJob(c, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(200, jobModel)
+}
+
+// ListJobs godoc
+//
+//	@Summary		List all jobs
+//	@Description	Retrieves a page of jobs, the X-Continue-Token response header holds the token of the next page.
+//	@Tags			Job
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit		query		int		false	"Page size, 500 by default and at most 1000"
+//	@Param			continue	query		string	false	"Continue token of the previous page"
+//	@Param			sort		query		string	false	"Sort field"	Enums(id, name)
+//	@Param			order		query		string	false	"Sort order"	Enums(asc, desc)
+//	@Param			owner_id	query		string	false	"Only jobs owned by this cron job"
+//	@Success		200			{array}		entity.Job
+//	@Failure		400			{object}	api.Problem
+//	@Failure		422			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/job [get]
+func (jr *JobRouter) ListJobs(c *gin.Context) {
+	listOptions, err := parseListOptions(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	opts := entity.ListJobsOptions{ListOptions: listOptions}
+	if ownerIDParam := c.Query("owner_id"); ownerIDParam != "" {
+		opts.OwnerID, err = uuid.Parse(ownerIDParam)
+		if err != nil {
+			_ = c.Error(fmt.Errorf("%w: owner_id: %s", usecase.InvalidListOptionsErr, err))
+			return
+		}
+	}
+
+	jobs, next, err := jr.jobService.ListJobs(c, opts)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setContinueToken(c, next)
+	c.JSON(200, jobs)
+}
+
+// AddJob godoc
+//
+//	@Summary		Add a new job
+//	@Description	Creates a new job, the controller then runs containers from the template until completions of them exit successfully.
+//	@Description	Failed containers are retried with an exponential backoff until more than backoff_limit of them failed.
+//	@Description	The restart policy of the template defaults to never, other policies are rejected.
+//	@Description	Retries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.
+//	@Tags			Job
+//	@Accept			json
+//	@Produce		json
+//	@Param			Idempotency-Key	header		string			false	"Key making retries of the request return the first response"
+//	@Param			job				body		entity.AddJob	true	"New job data"
+//	@Success		201				{object}	entity.Job
+//	@Failure		400				{object}	api.Problem
+//	@Failure		409				{object}	api.Problem
+//	@Failure		413				{object}	api.Problem
+//	@Failure		422				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Router			/api/v1/job [post]
+func (jr *JobRouter) AddJob(c *gin.Context) {
+	var req entity.AddJob
+	if err := bindJSON(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	jobModel, err := jr.jobService.AddJob(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(201, jobModel)
+}
+
+// DeleteJob godoc
+//
+//	@Summary		Delete a job
+//	@Description	Deletes a job by its ID together with its containers
+//	@Tags			Job
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource_id	path	string	true	"Job's ID"
+//	@Success		204
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
+//	@Router			/api/v1/job/{resource_id} [delete]
+func (jr *JobRouter) DeleteJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	err = jr.jobService.DeleteJob(c, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(204, gin.H{})
+}
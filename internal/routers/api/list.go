@@ -0,0 +1,46 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"strconv"
+)
+
+const (
+	DefaultListLimit = 500
+	MaxListLimit     = 1000
+
+	// ContinueTokenHeader carries the token of the next page on list responses.
+	ContinueTokenHeader = "X-Continue-Token"
+)
+
+// parseListOptions reads the limit, continue, sort, order and labelSelector query parameters.
+func parseListOptions(c *gin.Context) (entity.ListOptions, error) {
+	opts := entity.ListOptions{
+		Limit:    DefaultListLimit,
+		Continue: c.Query("continue"),
+		SortBy:   c.Query("sort"),
+		Order:    entity.SortOrder(c.Query("order")),
+	}
+	if limitParam := c.Query("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 {
+			return opts, fmt.Errorf("%w: invalid limit %q", usecase.InvalidListOptionsErr, limitParam)
+		}
+		opts.Limit = min(limit, MaxListLimit)
+	}
+	selector, err := entity.ParseLabelSelector(c.Query("labelSelector"))
+	if err != nil {
+		return opts, fmt.Errorf("%w: %s", usecase.InvalidListOptionsErr, err)
+	}
+	opts.LabelSelector = selector
+	return opts, nil
+}
+
+func setContinueToken(c *gin.Context, token string) {
+	if token != "" {
+		c.Header(ContinueTokenHeader, token)
+	}
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/logs"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	// logBatchSize is how many ingested entries are stored at once at most.
+	logBatchSize = 100
+	// maxLogRecordSize bounds one NDJSON record, leaving room for escaping the line.
+	maxLogRecordSize = 8 * entity.MaxLogLineLength
+)
+
+type ILogRouter interface {
+	AppendLogs(c *gin.Context)
+	GetLogs(c *gin.Context)
+}
+
+type LogRouter struct {
+	logService logs.IService
+}
+
+func NewLogRouter(logService logs.IService) LogRouter {
+	return LogRouter{logService: logService}
+}
+
+// AppendLogs godoc
+//
+//	@Summary		Ingest container logs
+//	@Description	Stores log entries sent by the node agent of a container as newline delimited JSON, one entry per line.
+//	@Description	The body may be streamed with chunked transfer encoding, entries are stored as they arrive in batches of up to 100.
+//	@Description	The stream defaults to stdout and the timestamp to the time of arrival, seq is assigned by the API.
+//	@Tags			Container
+//	@Accept			application/x-ndjson
+//	@Produce		json
+//	@Param			resource_id	path		string			true	"Container's ID"
+//	@Param			entries		body		entity.LogEntry	true	"Log entries, one JSON object per line"
+//	@Success		200			{object}	map[string]int
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		422			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/container/{resource_id}/logs [post]
+func (lr *LogRouter) AppendLogs(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	appended := 0
+	flush := func(batch []entity.LogEntry) error {
+		if err := lr.logService.AppendLogs(c, id, batch); err != nil {
+			return err
+		}
+		appended += len(batch)
+		return nil
+	}
+
+	err = readLogEntries(c.Request.Body, flush)
+	if err != nil {
+		_ = c.Error(withProblemMembers(err, gin.H{"appended": appended}))
+		return
+	}
+
+	c.JSON(200, gin.H{"appended": appended})
+}
+
+// GetLogs godoc
+//
+//	@Summary		Get container logs
+//	@Description	Returns the stored log entries of a container oldest first.
+//	@Description	With follow=true the entries and every new one are streamed as Server-Sent Events instead, until the container terminates or is removed.
+//	@Description	Followers resume with the Last-Event-ID header holding the seq of the last entry they received.
+//	@Tags			Container
+//	@Accept			json
+//	@Produce		json
+//	@Produce		text/event-stream
+//	@Param			resource_id	path		string	true	"Container's ID"
+//	@Param			tail		query		int		false	"Only the last entries"
+//	@Param			since		query		string	false	"Only entries logged at or after this RFC 3339 time"
+//	@Param			follow		query		bool	false	"Stream new entries"
+//	@Success		200			{array}		entity.LogEntry
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		422			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/container/{resource_id}/logs [get]
+func (lr *LogRouter) GetLogs(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+	opts, err := parseLogOptions(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	follow, _ := strconv.ParseBool(c.Query("follow"))
+	if follow {
+		lr.followLogs(c, id, opts)
+		return
+	}
+
+	entries, err := lr.logService.GetLogs(c, id, opts)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(200, entries)
+}
+
+// followLogs streams log entries as Server-Sent Events. Errors are reported
+// as JSON as long as nothing was streamed yet, afterwards the stream ends.
+func (lr *LogRouter) followLogs(c *gin.Context, id uuid.UUID, opts entity.LogOptions) {
+	streaming := false
+	err := lr.logService.FollowLogs(c.Request.Context(), id, opts, func(entries []entity.LogEntry) error {
+		if !streaming {
+			startEventStream(c)
+			streaming = true
+		}
+		for _, entry := range entries {
+			c.Render(-1, sse.Event{
+				Id:    strconv.FormatInt(entry.Seq, 10),
+				Event: "log",
+				Data:  entry,
+			})
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if streaming {
+		return
+	}
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	// Nothing was logged before the container terminated.
+	startEventStream(c)
+}
+
+// parseLogOptions reads the tail and since query parameters and the
+// Last-Event-ID header of resuming followers.
+func parseLogOptions(c *gin.Context) (entity.LogOptions, error) {
+	var opts entity.LogOptions
+	if tailParam := c.Query("tail"); tailParam != "" {
+		tail, err := strconv.Atoi(tailParam)
+		if err != nil || tail < 0 {
+			return opts, fmt.Errorf("%w: invalid tail %q", usecase.InvalidListOptionsErr, tailParam)
+		}
+		opts.Tail = tail
+	}
+	if sinceParam := c.Query("since"); sinceParam != "" {
+		since, err := time.Parse(time.RFC3339Nano, sinceParam)
+		if err != nil {
+			return opts, fmt.Errorf("%w: invalid since %q, expected an RFC 3339 time", usecase.InvalidListOptionsErr, sinceParam)
+		}
+		opts.Since = since
+	}
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		afterSeq, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			return opts, fmt.Errorf("%w: invalid Last-Event-ID %q", usecase.InvalidListOptionsErr, lastEventID)
+		}
+		opts.AfterSeq = afterSeq
+	}
+	return opts, nil
+}
+
+var errInvalidLogRecord = errors.New("invalid log record")
+
+// readLogEntries decodes newline delimited log entries from body and passes
+// them to flush in batches. A batch is flushed once it is full or no more
+// input is buffered, so that entries streamed slowly are stored right away.
+func readLogEntries(body io.Reader, flush func(batch []entity.LogEntry) error) error {
+	reader := bufio.NewReaderSize(body, maxLogRecordSize)
+	var batch []entity.LogEntry
+	for {
+		record, readErr := reader.ReadSlice('\n')
+		if errors.Is(readErr, bufio.ErrBufferFull) {
+			return fmt.Errorf("%w: records must be at most %d bytes", errInvalidLogRecord, maxLogRecordSize)
+		}
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return readErr
+		}
+
+		if record = bytes.TrimSpace(record); len(record) > 0 {
+			var entry entity.LogEntry
+			if err := json.Unmarshal(record, &entry); err != nil {
+				return fmt.Errorf("%w: %s", errInvalidLogRecord, err)
+			}
+			entry.Seq = 0
+			batch = append(batch, entry)
+		}
+
+		if len(batch) > 0 && (len(batch) >= logBatchSize || reader.Buffered() == 0 || readErr != nil) {
+			if err := flush(batch); err != nil {
+				return err
+			}
+			batch = nil
+		}
+		if readErr != nil {
+			return nil
+		}
+	}
+}
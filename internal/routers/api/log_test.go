@@ -0,0 +1,92 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/routers/api"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/internal/usecase/logs"
+	"github.com/wensiet/morchy-api/internal/usecase/node"
+	"github.com/wensiet/morchy-api/internal/usecase/scheduler"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setupLogRouter(t *testing.T) (*gin.Engine, uuid.UUID) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	nodeService := node.NewService(memory.NewNodeRepository(store), mockedBus)
+	containerService := container.NewService(
+		memory.NewContainerRepository(store),
+		nodeService,
+		&scheduler.LeastLoaded{},
+		mockedBus,
+	)
+	target, err := nodeService.AddNode(ctx, &entity.AddNode{})
+	require.NoError(t, err)
+	created, err := containerService.AddContainer(ctx, &entity.AddContainer{NodeID: target.ID, Image: "nginx"})
+	require.NoError(t, err)
+
+	logRouter := api.NewLogRouter(logs.NewService(memory.NewContainerLogRepository(store), containerService, time.Second))
+	r := gin.Default()
+	r.Use(api.ErrorHandler())
+	r.POST("/api/v1/container/:resource_id/logs", logRouter.AppendLogs)
+	r.GET("/api/v1/container/:resource_id/logs", logRouter.GetLogs)
+	return r, created.ID
+}
+
+func TestLogIngestionAndRetrieval(t *testing.T) {
+	router, id := setupLogRouter(t)
+	path := "/api/v1/container/" + id.String() + "/logs"
+
+	body := strings.Join([]string{
+		`{"line": "starting"}`,
+		`{"stream": "stderr", "line": "warning", "timestamp": "2026-01-02T03:04:05Z"}`,
+		``,
+		`{"line": "ready"}`,
+	}, "\n")
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"appended": 3}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, path+"?tail=2", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	var entries []entity.LogEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, entity.LogStreamStderr, entries[0].Stream)
+	assert.Equal(t, "warning", entries[0].Line)
+	assert.Equal(t, "ready", entries[1].Line)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, path, strings.NewReader("{\"line\": \"ok\"}\nnot json\n"))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+	var problem map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.EqualValues(t, 0, problem["appended"], "the batch with the invalid record is not stored")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, path+"?since=yesterday", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 422, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/container/"+uuid.NewString()+"/logs", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 404, w.Code)
+}
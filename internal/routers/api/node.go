@@ -1,10 +1,12 @@
 package api
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/wensiet/morchy-api/internal/usecase/node"
 	"github.com/wensiet/morchy-api/pkg/entity"
+	"strconv"
 )
 
 type INodeRouter interface {
@@ -12,7 +14,16 @@ type INodeRouter interface {
 	ListNodes(c *gin.Context)
 	AddNode(c *gin.Context)
 	UpdateNode(c *gin.Context)
+	PatchNode(c *gin.Context)
 	DeleteNode(c *gin.Context)
+	Cordon(c *gin.Context)
+	Uncordon(c *gin.Context)
+	Heartbeat(c *gin.Context)
+	GetDesiredState(c *gin.Context)
+	NodeAction(c *gin.Context)
+	BatchAddNodes(c *gin.Context)
+	BatchUpdateNodes(c *gin.Context)
+	BatchDeleteNodes(c *gin.Context)
 }
 
 type NodeRouter struct {
@@ -34,135 +45,492 @@ func NewNodeRouter(nodeService node.IService) NodeRouter {
 //	@Param			resource_id	path	string	true	"Node's ID"
 //	@Produce		json
 //	@Success		200
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
 //	@Router			/api/v1/node/{resource_id} [get]
 func (nr *NodeRouter) GetNode(c *gin.Context) {
 	ctx := c.Request.Context()
 	id, err := uuid.Parse(c.Param("resource_id"))
 	if err != nil {
-		c.JSON(422, gin.H{
-			"message": err.Error(),
-		})
+		_ = c.Error(invalidRequest(err))
 		return
 	}
 
 	nodeModel, err := nr.nodeService.GetNode(ctx, id)
 	if err != nil {
-		c.JSON(500, gin.H{
-			"kind":   "ERROR",
-			"reason": err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
+	setETag(c, nodeModel.ResourceVersion)
 	c.JSON(200, nodeModel)
 }
 
 // ListNodes godoc
 //
 //	@Summary		List all nodes
-//	@Description	Retrieves a list of all nodes
+//	@Description	Retrieves a page of nodes, the X-Continue-Token response header holds the token of the next page.
+//	@Description	With watch=true streams ADDED, MODIFIED and DELETED node events as Server-Sent Events instead.
 //	@Tags			Node
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{array}		entity.Node
-//	@Failure		500	{object}	map[string]string
+//	@Produce		text/event-stream
+//	@Param			limit			query		int		false	"Page size, 500 by default and at most 1000"
+//	@Param			continue		query		string	false	"Continue token of the previous page"
+//	@Param			sort			query		string	false	"Sort field"	Enums(id, status)
+//	@Param			order			query		string	false	"Sort order"	Enums(asc, desc)
+//	@Param			status			query		string	false	"Only nodes with this status"
+//	@Param			labelSelector	query		string	false	"Label selector, e.g. zone=eu,tier in (web,api),!legacy"
+//	@Param			watch			query		bool	false	"Stream node events"
+//	@Param			resourceVersion	query		int		false	"Resume the stream after this resource version"
+//	@Success		200				{array}		entity.Node
+//	@Failure		410				{object}	api.Problem
+//	@Failure		422				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
 //	@Router			/api/v1/node [get]
 func (nr *NodeRouter) ListNodes(c *gin.Context) {
 	ctx := c.Request.Context()
+	if isWatch(c) {
+		serveWatch(c, nr.nodeService.Watch)
+		return
+	}
 
-	nodes, err := nr.nodeService.ListNodes(ctx)
+	listOptions, err := parseListOptions(c)
 	if err != nil {
-		c.JSON(500, gin.H{
-			"message": err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
+	opts := entity.ListNodesOptions{
+		ListOptions: listOptions,
+		Status:      entity.NodeStatus(c.Query("status")),
+	}
+
+	nodes, next, err := nr.nodeService.ListNodes(ctx, opts)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	setContinueToken(c, next)
 	c.JSON(200, nodes)
 }
 
 // AddNode godoc
 //
 //	@Summary		Add a new node
-//	@Description	Creates a new node
+//	@Description	Creates a new node, optionally reporting its capacity, labels and taints
+//	@Description	Retries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.
 //	@Tags			Node
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	entity.Node
-//	@Failure		500	{object}	map[string]string
+//	@Param			Idempotency-Key	header		string			false	"Key making retries of the request return the first response"
+//	@Param			node			body		entity.AddNode	false	"New node data"
+//	@Success		200				{object}	entity.Node
+//	@Failure		400				{object}	api.Problem
+//	@Failure		409				{object}	api.Problem
+//	@Failure		413				{object}	api.Problem
+//	@Failure		422				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
 //	@Router			/api/v1/node [post]
 func (nr *NodeRouter) AddNode(c *gin.Context) {
 	ctx := c.Request.Context()
-	nodeModel, err := nr.nodeService.AddNode(ctx)
+	var req entity.AddNode
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			_ = c.Error(invalidRequest(err))
+			return
+		}
+	}
+
+	nodeModel, err := nr.nodeService.AddNode(ctx, &req)
 	if err != nil {
-		c.JSON(500, gin.H{
-			"message": err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
+	setETag(c, nodeModel.ResourceVersion)
 	c.JSON(200, nodeModel)
 }
 
 // UpdateNode godoc
 //
 //	@Summary		Update a node
-//	@Description	Updates an existing node
+//	@Description	Updates the status, labels and taints of an existing node, labels and taints are kept when omitted. Containers that do not tolerate a NoExecute taint are moved to other nodes
+//	@Description	The update has to be based on the current resource version, sent as the ETag in If-Match or as resource_version in the body, a stale one fails with 409.
 //	@Tags			Node
 //	@Accept			json
 //	@Produce		json
-//	@Param			node	body	entity.Node	true	"Updated Node Data"
+//	@Param			If-Match	header	string		false	"ETag of the node the update is based on"
+//	@Param			node		body	entity.Node	true	"Updated Node Data"
 //	@Success		204
-//	@Failure		422	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Header			204	{string}	ETag	"Resource version of the updated node"
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		409	{object}	api.Problem
+//	@Failure		422	{object}	api.Problem
+//	@Failure		428	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
 //	@Router			/api/v1/node [put]
 func (nr *NodeRouter) UpdateNode(c *gin.Context) {
 	ctx := c.Request.Context()
 	var nodeModel entity.Node
 
-	err := c.BindJSON(&nodeModel)
+	err := c.ShouldBindJSON(&nodeModel)
 	if err != nil {
-		c.JSON(422, gin.H{
-			"message": err.Error(),
-		})
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+	nodeModel.ResourceVersion, err = expectedVersion(c, nodeModel.ResourceVersion)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 	err = nr.nodeService.UpdateNode(ctx, &nodeModel)
 	if err != nil {
-		c.JSON(500, gin.H{
-			"message": err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
+	setETag(c, nodeModel.ResourceVersion)
 	c.JSON(204, gin.H{})
 }
 
+// PatchNode godoc
+//
+//	@Summary		Patch a node
+//	@Description	Applies a JSON Merge Patch (application/merge-patch+json) or a JSON Patch (application/json-patch+json) to the node.
+//	@Description	Only the status, labels and taints can be changed, patches to other fields fail with 422.
+//	@Description	An ETag in If-Match, or else the resource_version of the patched node, has to match the current one.
+//	@Description	A failed test operation of a JSON Patch fails with 412.
+//	@Tags			Node
+//	@Accept			application/merge-patch+json,application/json-patch+json
+//	@Produce		json
+//	@Param			resource_id	path		string	true	"Node's ID"
+//	@Param			If-Match	header		string	false	"ETag of the node the patch is based on"
+//	@Param			patch		body		object	true	"Patch document"
+//	@Success		200			{object}	entity.Node
+//	@Header			200			{string}	ETag	"Resource version of the patched node"
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		409			{object}	api.Problem
+//	@Failure		412			{object}	api.Problem
+//	@Failure		413			{object}	api.Problem
+//	@Failure		415			{object}	api.Problem
+//	@Failure		422			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/node/{resource_id} [patch]
+func (nr *NodeRouter) PatchNode(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	p, err := readPatch(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	nodeModel, err := nr.nodeService.PatchNode(ctx, id, p, version)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	setETag(c, nodeModel.ResourceVersion)
+	c.JSON(200, nodeModel)
+}
+
 // DeleteNode godoc
 //
 //	@Summary		Delete a node
-//	@Description	Deletes a node by its ID
+//	@Description	Deletes a node by its ID, a node with containers assigned to it cannot be deleted, drain it first
 //	@Tags			Node
 //	@Accept			json
 //	@Produce		json
 //	@Param			resource_id	path	string	true	"Node's ID"
 //	@Success		204
-//	@Failure		422	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		409	{object}	api.Problem
+//	@Failure		422	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
 //	@Router			/api/v1/node/{resource_id} [delete]
 func (nr *NodeRouter) DeleteNode(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	id, err := uuid.Parse(c.Param("resource_id"))
 	if err != nil {
-		c.JSON(422, gin.H{
-			"message": err.Error(),
-		})
+		_ = c.Error(invalidRequest(err))
 		return
 	}
 	err = nr.nodeService.DeleteNode(ctx, id)
 	if err != nil {
-		c.JSON(500, gin.H{
-			"message": err.Error(),
-		})
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(204, gin.H{})
+}
+
+// Cordon godoc
+//
+//	@Summary		Cordon a node
+//	@Description	Marks the node unschedulable, no new containers are placed on it while the ones already there keep running
+//	@Tags			Node
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource_id	path		string	true	"Node's ID"
+//	@Success		200			{object}	entity.Node
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		422			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/node/{resource_id}/cordon [post]
+func (nr *NodeRouter) Cordon(c *gin.Context) {
+	nr.setCordoned(c, nr.nodeService.Cordon)
+}
+
+// Uncordon godoc
+//
+//	@Summary		Uncordon a node
+//	@Description	Makes a cordoned node schedulable again
+//	@Tags			Node
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource_id	path		string	true	"Node's ID"
+//	@Success		200			{object}	entity.Node
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		422			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/node/{resource_id}/uncordon [post]
+func (nr *NodeRouter) Uncordon(c *gin.Context) {
+	nr.setCordoned(c, nr.nodeService.Uncordon)
+}
+
+func (nr *NodeRouter) setCordoned(c *gin.Context, set func(ctx context.Context, id uuid.UUID) (*entity.Node, error)) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+	nodeModel, err := set(ctx, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	setETag(c, nodeModel.ResourceVersion)
+	c.JSON(200, nodeModel)
+}
+
+// Heartbeat godoc
+//
+//	@Summary		Send node heartbeat
+//	@Description	Records that the node is alive, used to detect failed nodes, optionally updating its capacity
+//	@Tags			Node
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource_id	path	string					true	"Node's ID"
+//	@Param			heartbeat	body	entity.NodeHeartbeat	false	"Heartbeat data"
+//	@Success		204
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		422	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
+//	@Router			/api/v1/node/{resource_id}/heartbeat [post]
+func (nr *NodeRouter) Heartbeat(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+	var req entity.NodeHeartbeat
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			_ = c.Error(invalidRequest(err))
+			return
+		}
+	}
+
+	err = nr.nodeService.Heartbeat(ctx, id, req.Capacity)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 	c.JSON(204, gin.H{})
 }
+
+// GetDesiredState godoc
+//
+//	@Summary		Get node desired state
+//	@Description	Returns every container assigned to the node with the node revision.
+//	@Description	When the revision query parameter equals the current revision nothing is returned.
+//	@Tags			Node
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource_id	path		string	true	"Node's ID"
+//	@Param			revision	query		int		false	"Revision the agent already has"
+//	@Success		200			{object}	entity.NodeDesiredState
+//	@Success		304
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		422	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
+//	@Router			/api/v1/node/{resource_id}/desired-state [get]
+func (nr *NodeRouter) GetDesiredState(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+	knownRevision := int64(-1)
+	if revisionParam, ok := c.GetQuery("revision"); ok {
+		knownRevision, err = strconv.ParseInt(revisionParam, 10, 64)
+		if err != nil {
+			_ = c.Error(invalidRequest(err))
+			return
+		}
+	}
+
+	state, err := nr.nodeService.GetDesiredState(ctx, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if state.Revision == knownRevision {
+		c.Status(304)
+		return
+	}
+	c.JSON(200, state)
+}
+
+// NodeAction serves the batch methods of the node collection.
+func (nr *NodeRouter) NodeAction(c *gin.Context) {
+	serveAction(c, map[string]gin.HandlerFunc{
+		"batchCreate": nr.BatchAddNodes,
+		"batchUpdate": nr.BatchUpdateNodes,
+		"batchDelete": nr.BatchDeleteNodes,
+	})
+}
+
+// BatchAddNodes godoc
+//
+//	@Summary		Add nodes in a batch
+//	@Description	Creates up to 500 nodes like the single create does, in one transaction.
+//	@Description	In atomic mode, the default, a failed item fails the whole batch with its status and the results member lists every item; items that were rolled back fail with 409.
+//	@Description	In best_effort mode the items that succeed are created and the response is 200 with the result of every item.
+//	@Description	Retries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.
+//	@Tags			Node
+//	@Accept			json
+//	@Produce		json
+//	@Param			Idempotency-Key	header		string					false	"Key making retries of the request return the first response"
+//	@Param			batch			body		entity.BatchAddNodes	true	"New nodes"
+//	@Success		200				{object}	api.NodeBatchResult
+//	@Failure		400				{object}	api.Problem
+//	@Failure		409				{object}	api.Problem
+//	@Failure		413				{object}	api.Problem
+//	@Failure		422				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Router			/api/v1/node:batchCreate [post]
+func (nr *NodeRouter) BatchAddNodes(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req entity.BatchAddNodes
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+	nodes, errs, err := nr.nodeService.AddNodes(ctx, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	writeBatch(c, req.Mode, errs, nodeBatchItems(c, nodes, errs))
+}
+
+// BatchUpdateNodes godoc
+//
+//	@Summary		Update nodes in a batch
+//	@Description	Updates the status, labels and taints of up to 500 nodes like the single update does, in one transaction.
+//	@Description	Every item has to carry the resource_version it is based on, items without one fail with 428 and a stale one fails the item with 409.
+//	@Description	In atomic mode, the default, a failed item fails the whole batch with its status and the results member lists every item; items that were rolled back fail with 409.
+//	@Description	In best_effort mode the items that succeed are updated and the response is 200 with the result of every item.
+//	@Tags			Node
+//	@Accept			json
+//	@Produce		json
+//	@Param			batch	body		entity.BatchUpdateNodes	true	"Updated nodes"
+//	@Success		200		{object}	api.NodeBatchResult
+//	@Failure		400		{object}	api.Problem
+//	@Failure		404		{object}	api.Problem
+//	@Failure		409		{object}	api.Problem
+//	@Failure		422		{object}	api.Problem
+//	@Failure		428		{object}	api.Problem
+//	@Failure		500		{object}	api.Problem
+//	@Router			/api/v1/node:batchUpdate [post]
+func (nr *NodeRouter) BatchUpdateNodes(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req entity.BatchUpdateNodes
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+	nodes, errs, err := nr.nodeService.UpdateNodes(ctx, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	writeBatch(c, req.Mode, errs, nodeBatchItems(c, nodes, errs))
+}
+
+// BatchDeleteNodes godoc
+//
+//	@Summary		Delete nodes in a batch
+//	@Description	Deletes up to 500 nodes by their IDs in one transaction, nodes with containers assigned to them cannot be deleted.
+//	@Description	In atomic mode, the default, a failed item fails the whole batch with its status and the results member lists every item; items that were rolled back fail with 409.
+//	@Description	In best_effort mode the items that succeed are deleted and the response is 200 with the result of every item.
+//	@Tags			Node
+//	@Accept			json
+//	@Produce		json
+//	@Param			batch	body		entity.BatchDelete	true	"IDs of the nodes"
+//	@Success		200		{object}	api.BatchDeleteResult
+//	@Failure		400		{object}	api.Problem
+//	@Failure		404		{object}	api.Problem
+//	@Failure		409		{object}	api.Problem
+//	@Failure		422		{object}	api.Problem
+//	@Failure		500		{object}	api.Problem
+//	@Router			/api/v1/node:batchDelete [post]
+func (nr *NodeRouter) BatchDeleteNodes(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req entity.BatchDelete
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+	errs, err := nr.nodeService.DeleteNodes(ctx, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	writeBatch(c, req.Mode, errs, deleteBatchItems(c, req.IDs, errs))
+}
+
+// nodeBatchItems reports the nodes of a batch, created or updated ones with 200.
+func nodeBatchItems(c *gin.Context, nodes []*entity.Node, errs []error) []NodeBatchItem {
+	items := make([]NodeBatchItem, len(errs))
+	for i, item := range newBatchItems(c, errs, 200) {
+		items[i] = NodeBatchItem{BatchItem: item, Node: nodes[i]}
+	}
+	return items
+}
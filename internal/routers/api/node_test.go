@@ -4,33 +4,44 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/wensiet/morchy-api/internal/routers/api"
 	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/patch"
+	"github.com/wensiet/morchy-api/internal/usecase/watch"
 	"github.com/wensiet/morchy-api/pkg/entity"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 var mockedNodes = []*entity.Node{
 	{
-		ID:     uuid.MustParse("96222e5e-159e-46fd-8d43-32346436758c"),
-		Status: entity.NewNodeStatus,
+		ID:              uuid.MustParse("96222e5e-159e-46fd-8d43-32346436758c"),
+		Status:          entity.NewNodeStatus,
+		ResourceVersion: 1,
 	},
 	{
-		ID:     uuid.MustParse("e3f50db7-5d0f-425e-98ac-0d575950033c"),
-		Status: entity.RunningNodeStatus,
+		ID:              uuid.MustParse("e3f50db7-5d0f-425e-98ac-0d575950033c"),
+		Status:          entity.RunningNodeStatus,
+		ResourceVersion: 1,
 	},
 	{
-		ID:     uuid.MustParse("09a183f9-dde0-47f4-94f0-f5220d1d10bf"),
-		Status: entity.FailedNodeStatus,
+		ID:              uuid.MustParse("09a183f9-dde0-47f4-94f0-f5220d1d10bf"),
+		Status:          entity.FailedNodeStatus,
+		ResourceVersion: 1,
 	},
 }
 
+var mockedBus = watch.NewBus(10)
+
 type mockService struct {
 	dbPool *pgxpool.Pool
 }
@@ -50,12 +61,30 @@ func (m mockService) GetNode(_ context.Context, id uuid.UUID) (*entity.Node, err
 	return nil, usecase.NodeNotFoundErr
 }
 
-func (m mockService) ListNodes(_ context.Context) ([]*entity.Node, error) {
-	return mockedNodes, nil
+func (m mockService) ListNodes(_ context.Context, opts entity.ListNodesOptions) ([]*entity.Node, string, error) {
+	var nodes []*entity.Node
+	for _, node := range mockedNodes {
+		if opts.Status == "" || node.Status == opts.Status {
+			nodes = append(nodes, node)
+		}
+	}
+	if opts.Limit > 0 && len(nodes) > opts.Limit {
+		return nodes[:opts.Limit], "next", nil
+	}
+	return nodes, "", nil
 }
 
-func (m mockService) AddNode(_ context.Context) (*entity.Node, error) {
-	newNode := entity.NewNode()
+func (m mockService) AddNode(_ context.Context, req *entity.AddNode) (*entity.Node, error) {
+	if err := req.Capacity.Validate(); err != nil {
+		return nil, err
+	}
+	if err := req.Labels.Validate(); err != nil {
+		return nil, err
+	}
+	newNode := entity.NewNode(req.Capacity)
+	if req.Labels != nil {
+		newNode.Labels = req.Labels
+	}
 	mockedNodes = append(mockedNodes, newNode)
 	return newNode, nil
 }
@@ -67,12 +96,38 @@ func (m mockService) UpdateNode(_ context.Context, nodeModel *entity.Node) error
 			if err != nil {
 				return err
 			}
+			if nodeModel.ResourceVersion != 0 && nodeModel.ResourceVersion != node.ResourceVersion {
+				return usecase.ResourceVersionConflictErr
+			}
 			node.Status = nodeModel.Status
+			node.ResourceVersion++
+			nodeModel.ResourceVersion = node.ResourceVersion
 		}
 	}
 	return nil
 }
 
+func (m mockService) PatchNode(_ context.Context, id uuid.UUID, p patch.Patch, resourceVersion int64) (*entity.Node, error) {
+	for _, node := range mockedNodes {
+		if node.ID == id {
+			var patched entity.Node
+			if err := p.Apply(node, &patched, "status", "resource_version"); err != nil {
+				return nil, err
+			}
+			if err := patched.Status.Validate(); err != nil {
+				return nil, err
+			}
+			if resourceVersion != 0 && resourceVersion != node.ResourceVersion {
+				return nil, usecase.ResourceVersionConflictErr
+			}
+			node.Status = patched.Status
+			node.ResourceVersion++
+			return node, nil
+		}
+	}
+	return nil, usecase.NodeNotFoundErr
+}
+
 func (m mockService) DeleteNode(_ context.Context, id uuid.UUID) error {
 	var index *int
 	for idx, node := range mockedNodes {
@@ -87,9 +142,92 @@ func (m mockService) DeleteNode(_ context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (m mockService) AddNodes(ctx context.Context, req *entity.BatchAddNodes) ([]*entity.Node, []error, error) {
+	nodes := make([]*entity.Node, len(req.Items))
+	errs := make([]error, len(req.Items))
+	for i := range req.Items {
+		nodes[i], errs[i] = m.AddNode(ctx, &req.Items[i])
+	}
+	return nodes, errs, nil
+}
+
+func (m mockService) UpdateNodes(ctx context.Context, req *entity.BatchUpdateNodes) ([]*entity.Node, []error, error) {
+	nodes := make([]*entity.Node, len(req.Items))
+	errs := make([]error, len(req.Items))
+	for i := range req.Items {
+		if errs[i] = m.UpdateNode(ctx, &req.Items[i]); errs[i] == nil {
+			nodes[i] = &req.Items[i]
+		}
+	}
+	return nodes, errs, nil
+}
+
+func (m mockService) DeleteNodes(ctx context.Context, req *entity.BatchDelete) ([]error, error) {
+	errs := make([]error, len(req.IDs))
+	for i, id := range req.IDs {
+		errs[i] = m.DeleteNode(ctx, id)
+	}
+	return errs, nil
+}
+
+func (m mockService) Cordon(_ context.Context, id uuid.UUID) (*entity.Node, error) {
+	return m.setUnschedulable(id, true)
+}
+
+func (m mockService) Uncordon(_ context.Context, id uuid.UUID) (*entity.Node, error) {
+	return m.setUnschedulable(id, false)
+}
+
+func (m mockService) setUnschedulable(id uuid.UUID, unschedulable bool) (*entity.Node, error) {
+	for _, node := range mockedNodes {
+		if node.ID == id {
+			node.Unschedulable = unschedulable
+			return node, nil
+		}
+	}
+	return nil, usecase.NodeNotFoundErr
+}
+
+func (m mockService) Heartbeat(_ context.Context, id uuid.UUID, capacity *entity.Resources) error {
+	if capacity != nil {
+		if err := capacity.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, node := range mockedNodes {
+		if node.ID == id {
+			now := time.Now()
+			node.LastSeen = &now
+			if capacity != nil {
+				node.Capacity = *capacity
+			}
+			return nil
+		}
+	}
+	return usecase.NodeNotFoundErr
+}
+
+func (m mockService) GetDesiredState(_ context.Context, id uuid.UUID) (*entity.NodeDesiredState, error) {
+	for _, node := range mockedNodes {
+		if node.ID == id {
+			return &entity.NodeDesiredState{
+				NodeID:     node.ID,
+				Revision:   node.Revision,
+				Containers: node.Containers,
+			}, nil
+		}
+	}
+	return nil, usecase.NodeNotFoundErr
+}
+
+func (m mockService) Watch(ctx context.Context, resourceVersion uint64) (*watch.Subscription, error) {
+	return mockedBus.Subscribe(ctx, watch.NodeKind, resourceVersion)
+}
+
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(api.ErrorHandler())
 	mockService := newMockService(nil)
 	nr := api.NewNodeRouter(mockService)
 
@@ -97,7 +235,12 @@ func setupRouter() *gin.Engine {
 	r.GET("/nodes", nr.ListNodes)
 	r.POST("/node", nr.AddNode)
 	r.PUT("/node", nr.UpdateNode)
+	r.PATCH("/node/:resource_id", nr.PatchNode)
 	r.DELETE("/node/:resource_id", nr.DeleteNode)
+	r.POST("/node/:resource_id/cordon", nr.Cordon)
+	r.POST("/node/:resource_id/uncordon", nr.Uncordon)
+	r.POST("/node/:resource_id/heartbeat", nr.Heartbeat)
+	r.GET("/node/:resource_id/desired-state", nr.GetDesiredState)
 
 	return r
 }
@@ -118,6 +261,32 @@ func TestNodeRouter_GetNode(t *testing.T) {
 	assert.Equal(t, testNode.Status, responseNode.Status)
 }
 
+func TestNodeRouter_GetNodeProblem(t *testing.T) {
+	r := setupRouter()
+	path := "/node/" + uuid.NewString()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, api.ProblemContentType, w.Header().Get("Content-Type"))
+	var problem api.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, api.Problem{
+		Type:     "about:blank",
+		Title:    "Not Found",
+		Status:   http.StatusNotFound,
+		Detail:   "node not found",
+		Instance: path,
+	}, problem)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/node/not-a-uuid", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, api.ProblemContentType, w.Header().Get("Content-Type"))
+}
+
 func TestNodeRouter_ListNodes(t *testing.T) {
 	r := setupRouter()
 	w := httptest.NewRecorder()
@@ -125,6 +294,22 @@ func TestNodeRouter_ListNodes(t *testing.T) {
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(api.ContinueTokenHeader))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/nodes?limit=1", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var nodes []entity.Node
+	err := json.Unmarshal(w.Body.Bytes(), &nodes)
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 1)
+	assert.Equal(t, "next", w.Header().Get(api.ContinueTokenHeader))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/nodes?limit=0", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
 }
 
 func TestNodeRouter_AddNode(t *testing.T) {
@@ -148,20 +333,109 @@ func TestNodeRouter_AddNode(t *testing.T) {
 	assert.True(t, gotNode)
 }
 
+func TestNodeRouter_AddNodeWithCapacity(t *testing.T) {
+	r := setupRouter()
+	body, err := json.Marshal(entity.AddNode{
+		Capacity: entity.Resources{CPU: 4000, Memory: 8 << 30, Disk: 100 << 30},
+	})
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/node", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var responseNode entity.Node
+	err = json.Unmarshal(w.Body.Bytes(), &responseNode)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4000), responseNode.Capacity.CPU)
+
+	body, err = json.Marshal(entity.AddNode{Capacity: entity.Resources{CPU: -1}})
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/node", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
 func TestNodeRouter_UpdateNode(t *testing.T) {
 	r := setupRouter()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/node/"+mockedNodes[0].ID.String(), nil)
+	r.ServeHTTP(w, req)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+
 	testNodeUpdate := entity.Node{
 		ID:     mockedNodes[0].ID,
 		Status: entity.FailedNodeStatus,
 	}
-	w := httptest.NewRecorder()
 	updateNodeBody, err := json.Marshal(testNodeUpdate)
-	req, _ := http.NewRequest("PUT", "/node", bytes.NewBuffer(updateNodeBody))
-	req.Header.Set("Content-Type", "application/json")
-	r.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusNoContent, w.Code)
 	assert.NoError(t, err)
+	put := func(ifMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/node", bytes.NewBuffer(updateNodeBody))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusPreconditionRequired, put("").Code)
+	assert.Equal(t, http.StatusBadRequest, put("version-1").Code)
+
+	w = put(etag)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
 	assert.Equal(t, mockedNodes[0].Status, entity.FailedNodeStatus)
+
+	assert.Equal(t, http.StatusConflict, put(etag).Code, "the node changed since the ETag was read")
+
+	testNodeUpdate.ResourceVersion = 2
+	updateNodeBody, err = json.Marshal(testNodeUpdate)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, put("").Code)
+}
+
+func TestNodeRouter_PatchNode(t *testing.T) {
+	r := setupRouter()
+	testNode := mockedNodes[1]
+	patchNode := func(contentType, ifMatch, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PATCH", "/node/"+testNode.ID.String(), strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patchNode("application/merge-patch+json", "", `{"status": "failed"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, entity.FailedNodeStatus, testNode.Status)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, fmt.Sprintf("%q", fmt.Sprint(testNode.ResourceVersion)), etag)
+
+	w = patchNode("application/json-patch+json", etag, `[{"op": "replace", "path": "/status", "value": "running"}]`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, entity.RunningNodeStatus, testNode.Status)
+
+	w = patchNode("application/json-patch+json", etag, `[{"op": "replace", "path": "/status", "value": "failed"}]`)
+	assert.Equal(t, http.StatusConflict, w.Code, "the ETag is stale")
+	w = patchNode("application/json-patch+json", "", `[{"op": "test", "path": "/status", "value": "failed"}]`)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	w = patchNode("application/merge-patch+json", "", `{"status": "gone"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = patchNode("application/merge-patch+json", "", `{"statuss": "failed"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "unknown fields are rejected")
+	w = patchNode("application/merge-patch+json", "", `{"capacity": {"cpu": 1}}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "immutable fields are rejected")
+	w = patchNode("application/json", "", `{"status": "failed"}`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, entity.RunningNodeStatus, testNode.Status)
 }
 
 func TestNodeRouter_DeleteNode(t *testing.T) {
@@ -177,3 +451,91 @@ func TestNodeRouter_DeleteNode(t *testing.T) {
 		assert.NotEqual(t, testNode.ID, nodeObj.ID)
 	}
 }
+
+func TestNodeRouter_Cordon(t *testing.T) {
+	r := setupRouter()
+	testNode := mockedNodes[0]
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/node/"+testNode.ID.String()+"/cordon", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, testNode.Unschedulable)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/node/"+testNode.ID.String()+"/uncordon", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, testNode.Unschedulable)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/node/"+uuid.NewString()+"/cordon", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestNodeRouter_Heartbeat(t *testing.T) {
+	r := setupRouter()
+	testNode := mockedNodes[0]
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/node/"+testNode.ID.String()+"/heartbeat", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.NotNil(t, testNode.LastSeen)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/node/"+uuid.NewString()+"/heartbeat", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestNodeRouter_GetDesiredState(t *testing.T) {
+	r := setupRouter()
+	testNode := mockedNodes[0]
+	testNode.Revision = 3
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/node/"+testNode.ID.String()+"/desired-state", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var state entity.NodeDesiredState
+	err := json.Unmarshal(w.Body.Bytes(), &state)
+	assert.NoError(t, err)
+	assert.Equal(t, testNode.ID, state.NodeID)
+	assert.Equal(t, int64(3), state.Revision)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/node/"+testNode.ID.String()+"/desired-state?revision=3", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/node/"+testNode.ID.String()+"/desired-state?revision=2", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/node/"+testNode.ID.String()+"/desired-state?revision=latest", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestNodeRouter_WatchNodes(t *testing.T) {
+	r := setupRouter()
+	mockedBus.Publish(watch.NodeKind, entity.WatchEventAdded, mockedNodes[0])
+	from := mockedBus.Version()
+	mockedBus.Publish(watch.NodeKind, entity.WatchEventAdded, mockedNodes[0])
+	mockedBus.Publish(watch.ContainerKind, entity.WatchEventAdded, entity.Container{})
+	mockedBus.Publish(watch.NodeKind, entity.WatchEventDeleted, mockedNodes[0])
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("/nodes?watch=true&resourceVersion=%d", from), nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/event-stream")
+	body := w.Body.String()
+	assert.Contains(t, body, fmt.Sprintf("id:%d\nevent:ADDED\n", from+1))
+	assert.Contains(t, body, fmt.Sprintf("id:%d\nevent:DELETED\n", from+3))
+	assert.NotContains(t, body, fmt.Sprintf("id:%d\n", from+2))
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/wensiet/morchy-api/internal/usecase/patch"
+	"io"
+)
+
+// maxPatchSize limits the size of patch documents.
+const maxPatchSize = 1 << 20
+
+var (
+	errUnsupportedPatchType = errors.New("unsupported patch content type")
+	errPatchTooLarge        = errors.New("patch is too large")
+)
+
+// readPatch reads the patch document of the request, its type is taken from the Content-Type header.
+func readPatch(c *gin.Context) (patch.Patch, error) {
+	patchType := patch.Type(c.ContentType())
+	if patchType != patch.MergePatchType && patchType != patch.JSONPatchType {
+		return patch.Patch{}, fmt.Errorf("%w %q, use %s or %s",
+			errUnsupportedPatchType, patchType, patch.MergePatchType, patch.JSONPatchType)
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPatchSize+1))
+	if err != nil {
+		return patch.Patch{}, err
+	}
+	if len(data) > maxPatchSize {
+		return patch.Patch{}, fmt.Errorf("%w: larger than %d bytes", errPatchTooLarge, maxPatchSize)
+	}
+	return patch.Patch{Type: patchType, Data: data}, nil
+}
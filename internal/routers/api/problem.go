@@ -0,0 +1,123 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"log"
+	"net/http"
+)
+
+// ProblemContentType is the media type of error responses, see RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// Problem godoc
+// api.Problem struct
+type Problem struct {
+	// Type is a URI reference identifying the problem type, about:blank when the status explains it
+	Type   string `json:"type" example:"about:blank"`
+	Title  string `json:"title" example:"Not Found"`
+	Status int    `json:"status" example:"404"`
+	Detail string `json:"detail,omitempty" example:"node not found"`
+	// Instance is the path of the request that failed
+	Instance string `json:"instance" example:"/api/v1/node/96222e5e-159e-46fd-8d43-32346436758c"`
+}
+
+// errInvalidRequest is wrapped by errors of requests that cannot be parsed,
+// like malformed ids, query parameters or bodies.
+var errInvalidRequest = errors.New("invalid request")
+
+// requestErrorStatus is the response status of errors detected while reading requests.
+var requestErrorStatus = map[error]int{
+	errInvalidRequest:       http.StatusBadRequest,
+	errInvalidIfMatch:       http.StatusBadRequest,
+	errInvalidLogRecord:     http.StatusBadRequest,
+	errUnknownAction:        http.StatusNotFound,
+	errPatchTooLarge:        http.StatusRequestEntityTooLarge,
+	errRequestTooLarge:      http.StatusRequestEntityTooLarge,
+	errUnsupportedPatchType: http.StatusUnsupportedMediaType,
+	errPreconditionRequired: http.StatusPreconditionRequired,
+}
+
+// kindStatus is the response status of every kind of usecase error.
+var kindStatus = map[usecase.Kind]int{
+	usecase.NotFoundKind:             http.StatusNotFound,
+	usecase.ConflictKind:             http.StatusConflict,
+	usecase.ValidationKind:           http.StatusUnprocessableEntity,
+	usecase.PreconditionFailedKind:   http.StatusPreconditionFailed,
+	usecase.PreconditionRequiredKind: http.StatusPreconditionRequired,
+	usecase.GoneKind:                 http.StatusGone,
+	usecase.UnavailableKind:          http.StatusServiceUnavailable,
+}
+
+// invalidRequest marks err as an error of a request that cannot be parsed.
+func invalidRequest(err error) error {
+	return fmt.Errorf("%w: %s", errInvalidRequest, err)
+}
+
+// problemMembers attaches extension members to the problem reported for an error.
+type problemMembers struct {
+	error
+	members gin.H
+}
+
+func withProblemMembers(err error, members gin.H) error {
+	return problemMembers{error: err, members: members}
+}
+
+func (e problemMembers) Unwrap() error {
+	return e.error
+}
+
+// ErrorHandler reports the last error added to the context by a handler as
+// problem details, unless the handler has written a response already.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		writeProblem(c, c.Errors.Last().Err)
+	}
+}
+
+// writeProblem responds with the problem details of err. Errors outside of
+// the catalogue are logged and reported without details.
+func writeProblem(c *gin.Context, err error) {
+	status := errorStatus(err)
+	problem := gin.H{
+		"type":     "about:blank",
+		"title":    http.StatusText(status),
+		"status":   status,
+		"instance": c.Request.URL.Path,
+	}
+	if status == http.StatusInternalServerError {
+		log.Printf("api: %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	} else {
+		problem["detail"] = err.Error()
+	}
+	var extended problemMembers
+	if errors.As(err, &extended) {
+		for name, value := range extended.members {
+			problem[name] = value
+		}
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(status, problem)
+}
+
+// errorStatus returns the response status of err, 500 for unknown errors.
+func errorStatus(err error) int {
+	for requestErr, status := range requestErrorStatus {
+		if errors.Is(err, requestErr) {
+			return status
+		}
+	}
+	if status, ok := kindStatus[usecase.KindOf(err)]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase/rollout"
+	"github.com/wensiet/morchy-api/pkg/entity"
+)
+
+type IRolloutRouter interface {
+	GetRollout(c *gin.Context)
+	ListRollouts(c *gin.Context)
+	AddRollout(c *gin.Context)
+	Pause(c *gin.Context)
+	Resume(c *gin.Context)
+	Rollback(c *gin.Context)
+}
+
+type RolloutRouter struct {
+	rolloutService rollout.IService
+}
+
+func NewRolloutRouter(rolloutService rollout.IService) RolloutRouter {
+	return RolloutRouter{rolloutService: rolloutService}
+}
+
+// GetRollout godoc
+//
+//	@Summary		Get rollout by id
+//	@Description	Allows to get a rollout by its ID
+//	@Tags			Rollout
+//	@Accept			json
+//	@Param			resource_id	path	string	true	"Rollout's ID"
+//	@Produce		json
+//	@Success		200	{object}	entity.Rollout
+//	@Failure		400	{object}	api.Problem
+//	@Failure		404	{object}	api.Problem
+//	@Failure		500	{object}	api.Problem
+//	@Router			/api/v1/rollout/{resource_id} [get]
+func (rr *RolloutRouter) GetRollout(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	rolloutModel, err := rr.rolloutService.GetRollout(c, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(200, rolloutModel)
+}
+
+// ListRollouts godoc
+//
+//	@Summary		List all rollouts
+//	@Description	Retrieves a page of rollouts, the X-Continue-Token response header holds the token of the next page.
+//	@Tags			Rollout
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit		query		int		false	"Page size, 500 by default and at most 1000"
+//	@Param			continue	query		string	false	"Continue token of the previous page"
+//	@Param			sort		query		string	false	"Sort field"	Enums(id, phase)
+//	@Param			order		query		string	false	"Sort order"	Enums(asc, desc)
+//	@Success		200			{array}		entity.Rollout
+//	@Failure		400			{object}	api.Problem
+//	@Failure		422			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/rollout [get]
+func (rr *RolloutRouter) ListRollouts(c *gin.Context) {
+	opts, err := parseListOptions(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	rollouts, next, err := rr.rolloutService.ListRollouts(c, opts)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setContinueToken(c, next)
+	c.JSON(200, rollouts)
+}
+
+// AddRollout godoc
+//
+//	@Summary		Start a rollout
+//	@Description	Switches every container running from_image to to_image, batch_size containers at a time.
+//	@Description	Each batch has to become ready before the next one starts, and at most max_unavailable of the containers may be not ready at once.
+//	@Description	A switched container that fails, exits or stops halts the rollout.
+//	@Description	Retries sent with the same Idempotency-Key return the stored response, reusing the key for a different request fails with 422.
+//	@Tags			Rollout
+//	@Accept			json
+//	@Produce		json
+//	@Param			Idempotency-Key	header		string				false	"Key making retries of the request return the first response"
+//	@Param			rollout			body		entity.AddRollout	true	"New rollout data"
+//	@Success		201				{object}	entity.Rollout
+//	@Failure		400				{object}	api.Problem
+//	@Failure		409				{object}	api.Problem
+//	@Failure		413				{object}	api.Problem
+//	@Failure		422				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Router			/api/v1/rollout [post]
+func (rr *RolloutRouter) AddRollout(c *gin.Context) {
+	var req entity.AddRollout
+	if err := bindJSON(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	rolloutModel, err := rr.rolloutService.AddRollout(c, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(201, rolloutModel)
+}
+
+// Pause godoc
+//
+//	@Summary		Pause a rollout
+//	@Description	Stops an active rollout from switching further containers
+//	@Tags			Rollout
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource_id	path		string	true	"Rollout's ID"
+//	@Success		200			{object}	entity.Rollout
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		409			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/rollout/{resource_id}/pause [post]
+func (rr *RolloutRouter) Pause(c *gin.Context) {
+	rr.act(c, rr.rolloutService.Pause)
+}
+
+// Resume godoc
+//
+//	@Summary		Resume a rollout
+//	@Description	Continues a paused rollout
+//	@Tags			Rollout
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource_id	path		string	true	"Rollout's ID"
+//	@Success		200			{object}	entity.Rollout
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		409			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/rollout/{resource_id}/resume [post]
+func (rr *RolloutRouter) Resume(c *gin.Context) {
+	rr.act(c, rr.rolloutService.Resume)
+}
+
+// Rollback godoc
+//
+//	@Summary		Roll back a rollout
+//	@Description	Switches every container the rollout updated back to from_image, batch_size containers at a time
+//	@Tags			Rollout
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource_id	path		string	true	"Rollout's ID"
+//	@Success		200			{object}	entity.Rollout
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		409			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
+//	@Router			/api/v1/rollout/{resource_id}/rollback [post]
+func (rr *RolloutRouter) Rollback(c *gin.Context) {
+	rr.act(c, rr.rolloutService.Rollback)
+}
+
+// act applies one of the rollout actions to the rollout in the path.
+func (rr *RolloutRouter) act(c *gin.Context, action func(ctx context.Context, id uuid.UUID) (*entity.Rollout, error)) {
+	id, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		_ = c.Error(invalidRequest(err))
+		return
+	}
+
+	rolloutModel, err := action(c, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(200, rolloutModel)
+}
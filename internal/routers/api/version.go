@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+)
+
+var (
+	errPreconditionRequired = errors.New("an If-Match header or a resource_version is required")
+	errInvalidIfMatch       = errors.New("invalid If-Match header")
+)
+
+// setETag sends the resource version of the returned object as a strong entity tag.
+func setETag(c *gin.Context, resourceVersion int64) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(resourceVersion, 10)))
+}
+
+// expectedVersion returns the resource version an update is based on, read
+// from the If-Match header or else from the version sent in the body.
+func expectedVersion(c *gin.Context, bodyVersion int64) (int64, error) {
+	if strings.TrimSpace(c.GetHeader("If-Match")) == "" {
+		if bodyVersion == 0 {
+			return 0, errPreconditionRequired
+		}
+		return bodyVersion, nil
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return 0, err
+	}
+	if version != 0 && bodyVersion != 0 && bodyVersion != version {
+		return 0, fmt.Errorf("%w: %s does not match resource_version %d", errInvalidIfMatch, c.GetHeader("If-Match"), bodyVersion)
+	}
+	return version, nil
+}
+
+// ifMatchVersion returns the resource version of the If-Match header, zero
+// when there is none. The "*" tag matches any version and yields zero too,
+// which skips the check.
+func ifMatchVersion(c *gin.Context) (int64, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, nil
+	}
+	tag, err := strconv.Unquote(strings.TrimPrefix(ifMatch, "W/"))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", errInvalidIfMatch, ifMatch)
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("%w: %s", errInvalidIfMatch, ifMatch)
+	}
+	return version, nil
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/watch"
+	"strconv"
+)
+
+type watchFunc func(ctx context.Context, resourceVersion uint64) (*watch.Subscription, error)
+
+// isWatch reports whether the list request asks for a stream of events instead.
+func isWatch(c *gin.Context) bool {
+	watchParam, _ := strconv.ParseBool(c.Query("watch"))
+	return watchParam
+}
+
+// serveWatch streams events as Server-Sent Events until the client goes away.
+// Watchers resume with the resourceVersion query parameter or the standard
+// Last-Event-ID header, both holding the last resource version they received.
+func serveWatch(c *gin.Context, watchFn watchFunc) {
+	ctx := c.Request.Context()
+
+	versionParam := c.Query("resourceVersion")
+	if versionParam == "" {
+		versionParam = c.GetHeader("Last-Event-ID")
+	}
+	var resourceVersion uint64
+	if versionParam != "" {
+		var err error
+		resourceVersion, err = strconv.ParseUint(versionParam, 10, 64)
+		if err != nil {
+			_ = c.Error(fmt.Errorf("%w: invalid resourceVersion %q", usecase.InvalidListOptionsErr, versionParam))
+			return
+		}
+	}
+
+	subscription, err := watchFn(ctx, resourceVersion)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	startEventStream(c)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			c.Render(-1, sse.Event{
+				Id:    strconv.FormatUint(event.ResourceVersion, 10),
+				Event: string(event.Type),
+				Data:  event,
+			})
+			c.Writer.Flush()
+		}
+	}
+}
+
+// startEventStream sends the headers of a Server-Sent Events response.
+func startEventStream(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(200)
+	c.Writer.Flush()
+}
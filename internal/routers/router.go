@@ -6,13 +6,30 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/wensiet/morchy-api/internal/routers/api"
 	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/internal/usecase/cronjob"
+	"github.com/wensiet/morchy-api/internal/usecase/deployment"
+	"github.com/wensiet/morchy-api/internal/usecase/idempotency"
+	"github.com/wensiet/morchy-api/internal/usecase/job"
+	"github.com/wensiet/morchy-api/internal/usecase/logs"
 	"github.com/wensiet/morchy-api/internal/usecase/node"
+	"github.com/wensiet/morchy-api/internal/usecase/rollout"
 )
 
-func InitRouter(nodeService node.IService, containerService container.IService) *gin.Engine {
+func InitRouter(
+	nodeService node.IService,
+	containerService container.IService,
+	deploymentService deployment.IService,
+	rolloutService rollout.IService,
+	jobService job.IService,
+	cronJobService cronjob.IService,
+	logService logs.IService,
+	idempotencyService idempotency.IService,
+) *gin.Engine {
 	r := gin.New()
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
+	r.Use(api.Idempotency(idempotencyService, "/api/v1/container/:resource_id/logs"))
+	r.Use(api.ErrorHandler())
 
 	nodeRoutes := api.NewNodeRouter(
 		nodeService,
@@ -20,16 +37,41 @@ func InitRouter(nodeService node.IService, containerService container.IService)
 	containerRoutes := api.NewContainerRouter(
 		containerService,
 	)
+	deploymentRoutes := api.NewDeploymentRouter(
+		deploymentService,
+	)
+	rolloutRoutes := api.NewRolloutRouter(
+		rolloutService,
+	)
+	jobRoutes := api.NewJobRouter(
+		jobService,
+	)
+	cronJobRoutes := api.NewCronJobRouter(
+		cronJobService,
+	)
+	logRoutes := api.NewLogRouter(
+		logService,
+	)
 
 	apiv1 := r.Group("/api/v1")
 	{
+		// Batch methods are routed as /node:batchCreate and the like, the
+		// action parameter starts at the colon.
+		apiv1.POST("/node:action", nodeRoutes.NodeAction)
+		apiv1.POST("/container:action", containerRoutes.ContainerAction)
 		nodeRouter := apiv1.Group("/node")
 		{
 			nodeRouter.GET("/:resource_id", nodeRoutes.GetNode)
 			nodeRouter.GET("", nodeRoutes.ListNodes)
 			nodeRouter.POST("", nodeRoutes.AddNode)
 			nodeRouter.PUT("", nodeRoutes.UpdateNode)
+			nodeRouter.PATCH("/:resource_id", nodeRoutes.PatchNode)
 			nodeRouter.DELETE("/:resource_id", nodeRoutes.DeleteNode)
+			nodeRouter.POST("/:resource_id/cordon", nodeRoutes.Cordon)
+			nodeRouter.POST("/:resource_id/uncordon", nodeRoutes.Uncordon)
+			nodeRouter.POST("/:resource_id/drain", containerRoutes.DrainNode)
+			nodeRouter.POST("/:resource_id/heartbeat", nodeRoutes.Heartbeat)
+			nodeRouter.GET("/:resource_id/desired-state", nodeRoutes.GetDesiredState)
 		}
 		containerRouter := apiv1.Group("/container")
 		{
@@ -37,7 +79,45 @@ func InitRouter(nodeService node.IService, containerService container.IService)
 			containerRouter.GET("", containerRoutes.ListContainers)
 			containerRouter.POST("", containerRoutes.AddContainer)
 			containerRouter.PUT("", containerRoutes.UpdateContainer)
+			containerRouter.PATCH("/:resource_id", containerRoutes.PatchContainer)
 			containerRouter.DELETE("/:resource_id", containerRoutes.DeleteContainer)
+			containerRouter.PUT("/:resource_id/status", containerRoutes.UpdateContainerStatus)
+			containerRouter.GET("/:resource_id/history", containerRoutes.GetContainerHistory)
+			containerRouter.GET("/:resource_id/events", containerRoutes.GetContainerEvents)
+			containerRouter.POST("/:resource_id/logs", logRoutes.AppendLogs)
+			containerRouter.GET("/:resource_id/logs", logRoutes.GetLogs)
+		}
+		deploymentRouter := apiv1.Group("/deployment")
+		{
+			deploymentRouter.GET("/:resource_id", deploymentRoutes.GetDeployment)
+			deploymentRouter.GET("", deploymentRoutes.ListDeployments)
+			deploymentRouter.POST("", deploymentRoutes.AddDeployment)
+			deploymentRouter.PUT("", deploymentRoutes.UpdateDeployment)
+			deploymentRouter.DELETE("/:resource_id", deploymentRoutes.DeleteDeployment)
+		}
+		rolloutRouter := apiv1.Group("/rollout")
+		{
+			rolloutRouter.GET("/:resource_id", rolloutRoutes.GetRollout)
+			rolloutRouter.GET("", rolloutRoutes.ListRollouts)
+			rolloutRouter.POST("", rolloutRoutes.AddRollout)
+			rolloutRouter.POST("/:resource_id/pause", rolloutRoutes.Pause)
+			rolloutRouter.POST("/:resource_id/resume", rolloutRoutes.Resume)
+			rolloutRouter.POST("/:resource_id/rollback", rolloutRoutes.Rollback)
+		}
+		jobRouter := apiv1.Group("/job")
+		{
+			jobRouter.GET("/:resource_id", jobRoutes.GetJob)
+			jobRouter.GET("", jobRoutes.ListJobs)
+			jobRouter.POST("", jobRoutes.AddJob)
+			jobRouter.DELETE("/:resource_id", jobRoutes.DeleteJob)
+		}
+		cronJobRouter := apiv1.Group("/cronjob")
+		{
+			cronJobRouter.GET("/:resource_id", cronJobRoutes.GetCronJob)
+			cronJobRouter.GET("", cronJobRoutes.ListCronJobs)
+			cronJobRouter.POST("", cronJobRoutes.AddCronJob)
+			cronJobRouter.PUT("", cronJobRoutes.UpdateCronJob)
+			cronJobRouter.DELETE("/:resource_id", cronJobRoutes.DeleteCronJob)
 		}
 	}
 
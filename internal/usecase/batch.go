@@ -0,0 +1,42 @@
+package usecase
+
+// AbortBatch fails every item of an atomic batch with BatchAbortedErr except
+// the failed one, which keeps its own error.
+func AbortBatch(errs []error, failed int) []error {
+	for i := range errs {
+		if i != failed {
+			errs[i] = BatchAbortedErr
+		}
+	}
+	return errs
+}
+
+// CreateValid stores the items of a batch that passed validation with
+// create, the others already have their error in errs. The errors returned
+// by create are merged into errs and the items that were not stored are set
+// to nil.
+func CreateValid[T any](items []*T, errs []error, create func(items []*T) ([]error, error)) error {
+	var valid []*T
+	var indexes []int
+	for i, item := range items {
+		if errs[i] == nil {
+			valid = append(valid, item)
+			indexes = append(indexes, i)
+		}
+	}
+	if len(valid) > 0 {
+		createErrs, err := create(valid)
+		if err != nil {
+			return err
+		}
+		for j, i := range indexes {
+			errs[i] = createErrs[j]
+		}
+	}
+	for i := range items {
+		if errs[i] != nil {
+			items[i] = nil
+		}
+	}
+	return nil
+}
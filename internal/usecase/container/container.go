@@ -2,111 +2,648 @@ package container
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/node"
+	"github.com/wensiet/morchy-api/internal/usecase/patch"
+	"github.com/wensiet/morchy-api/internal/usecase/scheduler"
+	"github.com/wensiet/morchy-api/internal/usecase/watch"
 	"github.com/wensiet/morchy-api/pkg/entity"
+	"log"
+	"slices"
+	"time"
 )
 
-const (
-	GetContainerQuery    = "SELECT id, node_id, image, status FROM container WHERE id = $1"
-	ListContainersQuery  = "SELECT id, node_id, image, status FROM container"
-	AddContainerQuery    = "INSERT INTO container (id, node_id, image, status) VALUES ($1, $2, $3, $4)"
-	UpdateContainerQuery = "UPDATE container SET image = $1, status = $2 WHERE id = $3"
-	DeleteContainerQuery = "DELETE FROM container WHERE id = $1"
+var (
+	// errContainerMoved aborts a reschedule of a container that has left the node in the meantime.
+	errContainerMoved = errors.New("container was moved concurrently")
+	// errImageChanged aborts an image replacement of a container whose image was changed in the meantime.
+	errImageChanged = errors.New("container image was changed concurrently")
 )
 
 type IService interface {
 	GetContainer(ctx context.Context, id uuid.UUID) (*entity.Container, error)
-	ListContainers(ctx context.Context) ([]entity.Container, error)
-	AddContainer(ctx context.Context, nodeID uuid.UUID, image string) (*entity.Container, error)
+	ListContainers(ctx context.Context, opts entity.ListContainersOptions) ([]entity.Container, string, error)
+	AddContainer(ctx context.Context, req *entity.AddContainer) (*entity.Container, error)
 	UpdateContainer(ctx context.Context, container *entity.Container) error
+	PatchContainer(ctx context.Context, id uuid.UUID, p patch.Patch, resourceVersion int64) (*entity.Container, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, req *entity.UpdateContainerStatus) (*entity.Container, error)
 	RemoveContainer(ctx context.Context, id uuid.UUID) error
+	AddContainers(ctx context.Context, req *entity.BatchAddContainers) ([]*entity.Container, []error, error)
+	UpdateContainers(ctx context.Context, req *entity.BatchUpdateContainers) ([]*entity.Container, []error, error)
+	RemoveContainers(ctx context.Context, req *entity.BatchDelete) ([]error, error)
+	DrainNode(ctx context.Context, id uuid.UUID) (*entity.NodeDrain, error)
+	ReplaceImage(ctx context.Context, id uuid.UUID, from, to string) (*entity.Container, error)
+	ListStatusHistory(ctx context.Context, id uuid.UUID) ([]entity.ContainerStatusChange, error)
+	ListEvents(ctx context.Context, id uuid.UUID) ([]entity.ContainerEvent, error)
+	Watch(ctx context.Context, resourceVersion uint64) (*watch.Subscription, error)
 }
 
 type Service struct {
-	dbPool *pgxpool.Pool
+	repo        usecase.ContainerRepository
+	nodeService node.IService
+	scheduler   scheduler.Scheduler
+	bus         *watch.Bus
 }
 
-func NewService(dbPool *pgxpool.Pool) *Service {
-	return &Service{dbPool: dbPool}
+func NewService(
+	repo usecase.ContainerRepository,
+	nodeService node.IService,
+	scheduler scheduler.Scheduler,
+	bus *watch.Bus,
+) *Service {
+	return &Service{
+		repo:        repo,
+		nodeService: nodeService,
+		scheduler:   scheduler,
+		bus:         bus,
+	}
 }
 
 func (s *Service) GetContainer(ctx context.Context, id uuid.UUID) (*entity.Container, error) {
-	conn, err := s.dbPool.Acquire(ctx)
+	return s.repo.Get(ctx, id)
+}
+
+// ListContainers returns one page of containers matching opts together with
+// the continue token for the next page, which is empty on the last page.
+func (s *Service) ListContainers(ctx context.Context, opts entity.ListContainersOptions) ([]entity.Container, string, error) {
+	if opts.Status != "" {
+		if err := opts.Status.Validate(); err != nil {
+			return nil, "", fmt.Errorf("%w: %s", usecase.InvalidListOptionsErr, err)
+		}
+	}
+	return s.repo.List(ctx, opts)
+}
+
+// AddContainer creates a container on the requested node, or on a node chosen
+// by the scheduler when no node is requested. A requested node without enough
+// free capacity for the container is rejected with InsufficientCapacityErr,
+// which the repository checks again with the node locked, and
+// one that is cordoned, violates the placement rules or carries a NoSchedule
+// or NoExecute taint the container does not tolerate with
+// PlacementConstraintErr.
+func (s *Service) AddContainer(ctx context.Context, req *entity.AddContainer) (*entity.Container, error) {
+	container, constraints, err := newContainer(req)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Release()
 
-	var container entity.Container
+	if req.NodeID == uuid.Nil {
+		nodes, _, err := s.nodeService.ListNodes(ctx, entity.ListNodesOptions{Status: entity.RunningNodeStatus})
+		if err != nil {
+			return nil, err
+		}
+		if _, err = s.schedule(container, constraints, nodes); err != nil {
+			return nil, err
+		}
+	} else {
+		target, err := s.nodeService.GetNode(ctx, req.NodeID)
+		if err != nil {
+			return nil, err
+		}
+		if err = placeOn(target, container, constraints); err != nil {
+			return nil, err
+		}
+	}
 
-	err = conn.QueryRow(ctx, GetContainerQuery, id).Scan(&container.ID, &container.NodeID, &container.Image, &container.Status)
-	if err != nil {
+	if err = s.repo.Create(ctx, container); err != nil {
 		return nil, err
 	}
+	s.bus.Publish(watch.ContainerKind, entity.WatchEventAdded, container)
+	return container, nil
+}
+
+// AddContainers creates the containers of the batch like AddContainer does in
+// a single transaction. The nodes are listed once for the whole batch and
+// every container placed counts against the capacity of its node for the
+// containers that follow. It returns the created container or the error of
+// every item, in atomic mode either all items are created or none.
+func (s *Service) AddContainers(
+	ctx context.Context,
+	req *entity.BatchAddContainers,
+) ([]*entity.Container, []error, error) {
+	if err := entity.ValidateBatch(&req.Mode, len(req.Items)); err != nil {
+		return nil, nil, err
+	}
+	atomic := req.Mode == entity.BatchModeAtomic
 
-	return &container, nil
+	running, _, err := s.nodeService.ListNodes(ctx, entity.ListNodesOptions{Status: entity.RunningNodeStatus})
+	if err != nil {
+		return nil, nil, err
+	}
+	known := make(map[uuid.UUID]*entity.Node, len(running))
+	for _, node := range running {
+		known[node.ID] = node
+	}
+
+	containers := make([]*entity.Container, len(req.Items))
+	errs := make([]error, len(req.Items))
+	for i := range req.Items {
+		containers[i], errs[i] = s.placeInBatch(ctx, &req.Items[i], running, known)
+		if errs[i] != nil && atomic {
+			return make([]*entity.Container, len(containers)), usecase.AbortBatch(errs, i), nil
+		}
+	}
+
+	err = usecase.CreateValid(containers, errs, func(valid []*entity.Container) ([]error, error) {
+		return s.repo.CreateBatch(ctx, valid, atomic)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, container := range containers {
+		if container != nil {
+			s.bus.Publish(watch.ContainerKind, entity.WatchEventAdded, container)
+		}
+	}
+	return containers, errs, nil
 }
 
-func (s *Service) ListContainers(ctx context.Context) ([]entity.Container, error) {
-	conn, err := s.dbPool.Acquire(ctx)
+// placeInBatch makes the container of req and places it like AddContainer
+// does, on the nodes listed for the batch. The container is added to the
+// containers of its node so that the next items of the batch account for it.
+func (s *Service) placeInBatch(
+	ctx context.Context,
+	req *entity.AddContainer,
+	running []*entity.Node,
+	known map[uuid.UUID]*entity.Node,
+) (*entity.Container, error) {
+	container, constraints, err := newContainer(req)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Release()
 
-	var containers []entity.Container
-	rows, err := conn.Query(ctx, ListContainersQuery)
+	var target *entity.Node
+	if req.NodeID == uuid.Nil {
+		target, err = s.schedule(container, constraints, running)
+	} else {
+		if target = known[req.NodeID]; target == nil {
+			if target, err = s.nodeService.GetNode(ctx, req.NodeID); err != nil {
+				return nil, err
+			}
+			known[target.ID] = target
+		}
+		err = placeOn(target, container, constraints)
+	}
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	target.Containers = append(target.Containers, *container)
+	return container, nil
+}
 
-	for rows.Next() {
-		var container entity.Container
-		err = rows.Scan(&container.ID, &container.NodeID, &container.Image, &container.Status)
-		if err != nil {
-			return nil, err
-		}
-		containers = append(containers, container)
+// newContainer validates req and makes the container it asks for, without a
+// node yet unless one is requested.
+func newContainer(req *entity.AddContainer) (*entity.Container, *scheduler.Constraints, error) {
+	constraints, err := validate(req.Image, req.Resources, &req.Spec, req.Labels, req.Placement)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return containers, nil
+	container := entity.NewContainer(req.NodeID, req.Image)
+	container.Resources = req.Resources
+	container.Spec = req.Spec
+	container.Placement = req.Placement
+	container.Owner = req.Owner
+	if req.Labels != nil {
+		container.Labels = req.Labels
+	}
+	container.RefreshConditions(time.Now().UTC())
+	return container, constraints, nil
 }
 
-func (s *Service) AddContainer(ctx context.Context, nodeID uuid.UUID, image string) (*entity.Container, error) {
-	conn, err := s.dbPool.Acquire(ctx)
+// schedule places the container on the node among nodes chosen by the
+// scheduler and returns that node.
+func (s *Service) schedule(
+	container *entity.Container,
+	constraints *scheduler.Constraints,
+	nodes []*entity.Node,
+) (*entity.Node, error) {
+	target, reasons, err := scheduler.Schedule(s.scheduler, nodes, container, constraints)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Release()
+	container.NodeID = target.ID
+	container.PlacementReasons = reasons
+	return target, nil
+}
 
-	container := entity.NewContainer(nodeID, image)
-	_, err = conn.Exec(ctx, AddContainerQuery, container.ID, container.NodeID, container.Image, container.Status)
+// placeOn checks that the explicitly requested node target can take the container.
+func placeOn(target *entity.Node, container *entity.Container, constraints *scheduler.Constraints) error {
+	if target.Unschedulable {
+		return fmt.Errorf("%w: node %s is cordoned", usecase.PlacementConstraintErr, target.ID)
+	}
+	if !target.CanFit(container.Resources) {
+		return usecase.InsufficientCapacityErr
+	}
+	reasons, err := constraints.Check(target)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return container, nil
+	taintReasons, err := scheduler.CheckTaints(target, container)
+	if err != nil {
+		return err
+	}
+	container.PlacementReasons = append([]string{"node requested explicitly", "node has enough free capacity"}, reasons...)
+	container.PlacementReasons = append(container.PlacementReasons, taintReasons...)
+	return nil
 }
 
 func (s *Service) RemoveContainer(ctx context.Context, id uuid.UUID) error {
-	conn, err := s.dbPool.Acquire(ctx)
+	container, err := s.repo.Delete(ctx, id)
 	if err != nil {
 		return err
 	}
-	defer conn.Release()
+	s.bus.Publish(watch.ContainerKind, entity.WatchEventDeleted, container)
+	return nil
+}
 
-	_, err = conn.Exec(ctx, DeleteContainerQuery, id)
-	return err
+// RemoveContainers deletes the containers of the batch in a single
+// transaction and returns the error of every item, in atomic mode either all
+// items are deleted or none.
+func (s *Service) RemoveContainers(ctx context.Context, req *entity.BatchDelete) ([]error, error) {
+	if err := entity.ValidateBatch(&req.Mode, len(req.IDs)); err != nil {
+		return nil, err
+	}
+	deleted, errs, err := s.repo.DeleteBatch(ctx, req.IDs, req.Mode == entity.BatchModeAtomic)
+	if err != nil {
+		return nil, err
+	}
+	for _, container := range deleted {
+		if container != nil {
+			s.bus.Publish(watch.ContainerKind, entity.WatchEventDeleted, container)
+		}
+	}
+	return errs, nil
 }
 
+// UpdateContainer saves the container, rejecting status changes that are not
+// allowed by the container status graph and recording allowed ones in history.
+// Labels are kept when they are omitted. The state reported by the node agent
+// is only changed through UpdateStatus. A non-zero resource version has to
+// match the stored one, else the update fails with ResourceVersionConflictErr.
+// The resource version of container is set to the new one on success.
 func (s *Service) UpdateContainer(ctx context.Context, container *entity.Container) error {
-	conn, err := s.dbPool.Acquire(ctx)
+	if err := validateUpdate(container); err != nil {
+		return err
+	}
+
+	updated, err := s.update(ctx, container.ID, func(current *entity.Container) error {
+		return applyUpdate(current, container)
+	})
+	if err != nil {
+		return err
+	}
+	container.ResourceVersion = updated.ResourceVersion
+	s.bus.Publish(watch.ContainerKind, entity.WatchEventModified, updated)
+	return nil
+}
+
+// UpdateContainers saves the containers of the batch like UpdateContainer
+// does in a single transaction. Every item has to carry the resource version
+// it is based on, items without one fail with ResourceVersionRequiredErr. It
+// returns the updated container or the error of every item, in atomic mode
+// either all items are updated or none.
+func (s *Service) UpdateContainers(
+	ctx context.Context,
+	req *entity.BatchUpdateContainers,
+) ([]*entity.Container, []error, error) {
+	if err := entity.ValidateBatch(&req.Mode, len(req.Items)); err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]uuid.UUID, len(req.Items))
+	for i := range req.Items {
+		ids[i] = req.Items[i].ID
+	}
+	updated, errs, err := s.repo.UpdateBatch(ctx, ids, func(i int, current *entity.Container) error {
+		if req.Items[i].ResourceVersion == 0 {
+			return usecase.ResourceVersionRequiredErr
+		}
+		if err := validateUpdate(&req.Items[i]); err != nil {
+			return err
+		}
+		if err := applyUpdate(current, &req.Items[i]); err != nil {
+			return err
+		}
+		current.RefreshConditions(time.Now().UTC())
+		return nil
+	}, req.Mode == entity.BatchModeAtomic)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, container := range updated {
+		if container != nil {
+			s.bus.Publish(watch.ContainerKind, entity.WatchEventModified, container)
+		}
+	}
+	return updated, errs, nil
+}
+
+// PatchContainer applies a JSON Merge Patch or JSON Patch to the current
+// container and saves the result like UpdateContainer does. Only the image,
+// spec and labels can be changed, patches to other fields fail with
+// InvalidPatchErr. The status is left out as well: node agents report it
+// through UpdateStatus, which records its history. The resource version of
+// the patched container has to match the stored one, a non-zero
+// resourceVersion takes its place.
+func (s *Service) PatchContainer(
+	ctx context.Context,
+	id uuid.UUID,
+	p patch.Patch,
+	resourceVersion int64,
+) (*entity.Container, error) {
+	updated, err := s.update(ctx, id, func(current *entity.Container) error {
+		var patched entity.Container
+		if err := p.Apply(current, &patched, patchableFields...); err != nil {
+			return err
+		}
+		if resourceVersion != 0 {
+			patched.ResourceVersion = resourceVersion
+		}
+		if err := validateUpdate(&patched); err != nil {
+			return err
+		}
+		return applyUpdate(current, &patched)
+	})
 	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(watch.ContainerKind, entity.WatchEventModified, updated)
+	return updated, nil
+}
+
+// patchableFields are the members of a container that PatchContainer changes.
+var patchableFields = []string{"image", "spec", "labels", "resource_version"}
+
+// validateUpdate checks the fields of container that updates change.
+func validateUpdate(container *entity.Container) error {
+	if err := container.Status.Validate(); err != nil {
 		return err
 	}
-	defer conn.Release()
+	if err := validateSpec(container.Image, &container.Spec); err != nil {
+		return err
+	}
+	return container.Labels.Validate()
+}
+
+// applyUpdate copies the image, status, spec and labels of container to
+// current after checking the resource version and the status transition.
+func applyUpdate(current, container *entity.Container) error {
+	if container.ResourceVersion != 0 && container.ResourceVersion != current.ResourceVersion {
+		return fmt.Errorf("%w: got %d, current is %d",
+			usecase.ResourceVersionConflictErr, container.ResourceVersion, current.ResourceVersion)
+	}
+	if !current.Status.CanTransitionTo(container.Status) {
+		return fmt.Errorf("%w: %s -> %s", usecase.InvalidStatusTransitionErr, current.Status, container.Status)
+	}
+	current.Image = container.Image
+	current.Status = container.Status
+	current.Spec = container.Spec
+	if container.Labels != nil {
+		current.Labels = container.Labels
+	}
+	return nil
+}
+
+// UpdateStatus stores the status and state reported by the node agent of the
+// container, leaving its spec alone. The status has to be allowed by the
+// container status graph and the state replaces the previous one as a whole.
+func (s *Service) UpdateStatus(ctx context.Context, id uuid.UUID, req *entity.UpdateContainerStatus) (*entity.Container, error) {
+	if err := req.Status.Validate(); err != nil {
+		return nil, err
+	}
+	if err := req.State.Validate(req.Status); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.update(ctx, id, func(current *entity.Container) error {
+		if !current.Status.CanTransitionTo(req.Status) {
+			return fmt.Errorf("%w: %s -> %s", usecase.InvalidStatusTransitionErr, current.Status, req.Status)
+		}
+		current.Status = req.Status
+		current.State = req.State
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(watch.ContainerKind, entity.WatchEventModified, updated)
+	return updated, nil
+}
+
+// ReplaceImage switches the container from image from to image to and puts it
+// back to pending, so that its node recreates it with the new image. A
+// container that does not run image from anymore, or is terminating, is
+// returned unchanged.
+func (s *Service) ReplaceImage(ctx context.Context, id uuid.UUID, from, to string) (*entity.Container, error) {
+	updated, err := s.update(ctx, id, func(current *entity.Container) error {
+		if current.Image != from || current.Status == entity.ContainerStatusTerminating {
+			return errImageChanged
+		}
+		current.Image = to
+		current.Status = entity.ContainerStatusPending
+		current.State = entity.ContainerState{}
+		return nil
+	})
+	if errors.Is(err, errImageChanged) {
+		return s.repo.Get(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(watch.ContainerKind, entity.WatchEventModified, updated)
+	s.recordEvent(ctx, updated.ID, entity.EventReasonImageReplaced, fmt.Sprintf("image changed from %s to %s", from, to))
+	return updated, nil
+}
+
+// EvictUntolerated reschedules every container on the node that does not
+// tolerate one of its NoExecute taints and returns how many were evicted.
+func (s *Service) EvictUntolerated(ctx context.Context, node *entity.Node) (int, error) {
+	containers, _, err := s.repo.List(ctx, entity.ListContainersOptions{NodeID: node.ID})
+	if err != nil {
+		return 0, err
+	}
+
+	evicted := 0
+	for i := range containers {
+		container := &containers[i]
+		// Containers marked for rescheduling are retried by the Rescheduler.
+		if container.Status == entity.ContainerStatusTerminating || container.RescheduleReason != "" {
+			continue
+		}
+		untolerated := entity.UntoleratedTaints(node.Taints, container.Spec.Tolerations, entity.TaintEffectNoExecute)
+		if len(untolerated) == 0 {
+			continue
+		}
+		reason := fmt.Sprintf("node %s has taint %s the container does not tolerate", node.ID, untolerated[0])
+		if _, err = s.Reschedule(ctx, container, reason); err != nil {
+			return evicted, err
+		}
+		evicted++
+	}
+	return evicted, nil
+}
+
+// DrainNode cordons the node and moves every container on it to other nodes
+// chosen by the scheduler. Containers that cannot be moved are left on the
+// node and reported in Failed.
+func (s *Service) DrainNode(ctx context.Context, id uuid.UUID) (*entity.NodeDrain, error) {
+	if _, err := s.nodeService.Cordon(ctx, id); err != nil {
+		return nil, err
+	}
+	containers, _, err := s.repo.List(ctx, entity.ListContainersOptions{NodeID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	drain := &entity.NodeDrain{
+		NodeID: id,
+		Moved:  []entity.ContainerMove{},
+		Failed: []entity.ContainerMoveFailure{},
+	}
+	for i := range containers {
+		container := &containers[i]
+		if container.Status == entity.ContainerStatusTerminating {
+			continue
+		}
+		moved, err := s.moveContainer(ctx, container, fmt.Sprintf("node %s is drained", id))
+		if errors.Is(err, usecase.NoEligibleNodeErr) || errors.Is(err, usecase.InvalidPlacementErr) {
+			drain.Failed = append(drain.Failed, entity.ContainerMoveFailure{ContainerID: container.ID, Reason: err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if moved.NodeID != id {
+			drain.Moved = append(drain.Moved, entity.ContainerMove{ContainerID: moved.ID, NodeID: moved.NodeID})
+		}
+	}
+	return drain, nil
+}
+
+// Reschedule moves the container off its node like moveContainer. When no
+// other node can take it the container is marked failed with
+// RescheduleReason set, so that it can be retried later.
+func (s *Service) Reschedule(ctx context.Context, container *entity.Container, reason string) (*entity.Container, error) {
+	moved, err := s.moveContainer(ctx, container, reason)
+	if !errors.Is(err, usecase.NoEligibleNodeErr) {
+		return moved, err
+	}
+
+	scheduleErr := err
+	updated, err := s.update(ctx, container.ID, func(current *entity.Container) error {
+		if current.NodeID != container.NodeID {
+			return errContainerMoved
+		}
+		current.Status = entity.ContainerStatusFailed
+		current.RescheduleReason = reason
+		return nil
+	})
+	if errors.Is(err, errContainerMoved) {
+		return s.repo.Get(ctx, container.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(watch.ContainerKind, entity.WatchEventModified, updated)
+	s.recordEvent(ctx, updated.ID, entity.EventReasonRescheduleFailed, reason+": "+scheduleErr.Error())
+	return updated, nil
+}
+
+// moveContainer moves the container off its node to another one chosen by
+// the scheduler, putting it back to pending there. It fails with
+// NoEligibleNodeErr when no other node can take the container, also when
+// the chosen node was filled up by someone else before the move. A container
+// moved by someone else in the meantime is returned as it is.
+func (s *Service) moveContainer(ctx context.Context, container *entity.Container, reason string) (*entity.Container, error) {
+	constraints, err := scheduler.NewConstraints(container.Placement)
+	if err != nil {
+		return nil, err
+	}
+	nodes, _, err := s.nodeService.ListNodes(ctx, entity.ListNodesOptions{Status: entity.RunningNodeStatus})
+	if err != nil {
+		return nil, err
+	}
+	candidates := slices.DeleteFunc(nodes, func(node *entity.Node) bool {
+		return node.ID == container.NodeID
+	})
+	target, reasons, err := scheduler.Schedule(s.scheduler, candidates, container, constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.update(ctx, container.ID, func(current *entity.Container) error {
+		if current.NodeID != container.NodeID {
+			return errContainerMoved
+		}
+		current.NodeID = target.ID
+		current.Status = entity.ContainerStatusPending
+		current.State = entity.ContainerState{}
+		current.PlacementReasons = append([]string{"moved from node " + container.NodeID.String() + ": " + reason}, reasons...)
+		current.RescheduleReason = ""
+		return nil
+	})
+	if errors.Is(err, errContainerMoved) {
+		return s.repo.Get(ctx, container.ID)
+	}
+	if errors.Is(err, usecase.InsufficientCapacityErr) {
+		// Another placement took the capacity of the target in the meantime.
+		return nil, fmt.Errorf("%w: %w", usecase.NoEligibleNodeErr, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(watch.ContainerKind, entity.WatchEventModified, updated)
+	s.recordEvent(ctx, updated.ID, entity.EventReasonRescheduled, fmt.Sprintf(
+		"moved from node %s to node %s: %s", container.NodeID, updated.NodeID, reason,
+	))
+	return updated, nil
+}
+
+// update applies mutate to the container like the repository does and
+// derives its conditions from the result.
+func (s *Service) update(
+	ctx context.Context,
+	id uuid.UUID,
+	mutate func(container *entity.Container) error,
+) (*entity.Container, error) {
+	return s.repo.Update(ctx, id, func(current *entity.Container) error {
+		if err := mutate(current); err != nil {
+			return err
+		}
+		current.RefreshConditions(time.Now().UTC())
+		return nil
+	})
+}
+
+// recordEvent stores an event of the container. Events only explain changes
+// that already happened, so failing to store one is logged instead of
+// failing the change.
+func (s *Service) recordEvent(ctx context.Context, id uuid.UUID, reason, message string) {
+	err := s.repo.AddEvent(ctx, &entity.ContainerEvent{
+		ContainerID: id,
+		Reason:      reason,
+		Message:     message,
+		CreatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("recording %s event of container %s: %v", reason, id, err)
+	}
+}
+
+func (s *Service) ListStatusHistory(ctx context.Context, id uuid.UUID) ([]entity.ContainerStatusChange, error) {
+	return s.repo.ListStatusHistory(ctx, id)
+}
+
+// ListEvents returns the events recorded for the container oldest first.
+func (s *Service) ListEvents(ctx context.Context, id uuid.UUID) ([]entity.ContainerEvent, error) {
+	return s.repo.ListEvents(ctx, id)
+}
 
-	_, err = conn.Exec(ctx, UpdateContainerQuery, container.ID, container.Image, container.Status)
-	return err
+// Watch streams container events published after resourceVersion.
+func (s *Service) Watch(ctx context.Context, resourceVersion uint64) (*watch.Subscription, error) {
+	return s.bus.Subscribe(ctx, watch.ContainerKind, resourceVersion)
 }
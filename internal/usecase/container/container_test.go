@@ -0,0 +1,577 @@
+package container_test
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/internal/usecase/node"
+	"github.com/wensiet/morchy-api/internal/usecase/patch"
+	"github.com/wensiet/morchy-api/internal/usecase/scheduler"
+	"github.com/wensiet/morchy-api/internal/usecase/watch"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+	"time"
+)
+
+func newServices(t *testing.T) (*node.Service, *container.Service) {
+	store := memory.NewStore()
+	bus := watch.NewBus(10)
+	nodeService := node.NewService(memory.NewNodeRepository(store), bus)
+	containerService := container.NewService(
+		memory.NewContainerRepository(store),
+		nodeService,
+		&scheduler.LeastLoaded{},
+		bus,
+	)
+	return nodeService, containerService
+}
+
+func TestAddContainerSchedulesOnRunningNode(t *testing.T) {
+	ctx := context.Background()
+	nodeService, containerService := newServices(t)
+
+	_, err := containerService.AddContainer(ctx, &entity.AddContainer{Image: "nginx"})
+	assert.ErrorIs(t, err, usecase.NoEligibleNodeErr)
+
+	target, err := nodeService.AddNode(ctx, &entity.AddNode{Capacity: entity.Resources{CPU: 1000}})
+	require.NoError(t, err)
+	target.Status = entity.RunningNodeStatus
+	require.NoError(t, nodeService.UpdateNode(ctx, target))
+
+	created, err := containerService.AddContainer(ctx, &entity.AddContainer{
+		Image:     "nginx",
+		Resources: entity.Resources{CPU: 500},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, created.NodeID)
+
+	_, err = containerService.AddContainer(ctx, &entity.AddContainer{
+		NodeID:    target.ID,
+		Image:     "nginx",
+		Resources: entity.Resources{CPU: 600},
+	})
+	assert.ErrorIs(t, err, usecase.InsufficientCapacityErr)
+}
+
+func TestUpdateContainerChecksResourceVersion(t *testing.T) {
+	ctx := context.Background()
+	nodeService, containerService := newServices(t)
+
+	target, err := nodeService.AddNode(ctx, &entity.AddNode{})
+	require.NoError(t, err)
+	created, err := containerService.AddContainer(ctx, &entity.AddContainer{NodeID: target.ID, Image: "nginx"})
+	require.NoError(t, err)
+
+	first, second := *created, *created
+	first.Status = entity.ContainerStatusCreating
+	require.NoError(t, containerService.UpdateContainer(ctx, &first))
+	assert.Equal(t, created.ResourceVersion+1, first.ResourceVersion)
+
+	second.Image = "nginx:1.27"
+	assert.ErrorIs(t, containerService.UpdateContainer(ctx, &second), usecase.ResourceVersionConflictErr)
+	got, err := containerService.GetContainer(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "nginx", got.Image, "the stale update is not applied")
+
+	second.ResourceVersion = 0
+	second.Status = entity.ContainerStatusCreating
+	require.NoError(t, containerService.UpdateContainer(ctx, &second), "a zero version updates unconditionally")
+	assert.Equal(t, first.ResourceVersion+1, second.ResourceVersion)
+}
+
+func TestPatchContainer(t *testing.T) {
+	ctx := context.Background()
+	nodeService, containerService := newServices(t)
+
+	target, err := nodeService.AddNode(ctx, &entity.AddNode{})
+	require.NoError(t, err)
+	created, err := containerService.AddContainer(ctx, &entity.AddContainer{
+		NodeID: target.ID,
+		Image:  "nginx",
+		Labels: entity.Labels{"app": "web"},
+		Spec:   entity.ContainerSpec{Env: map[string]string{"PORT": "8080"}},
+	})
+	require.NoError(t, err)
+	reported, err := containerService.UpdateStatus(ctx, created.ID, &entity.UpdateContainerStatus{
+		Status: entity.ContainerStatusCreating,
+		State:  entity.ContainerState{Reason: "pulling image"},
+	})
+	require.NoError(t, err)
+
+	patched, err := containerService.PatchContainer(ctx, created.ID, patch.Patch{
+		Type: patch.MergePatchType,
+		Data: []byte(`{"image": "nginx:1.27", "spec": {"env": {"DEBUG": "1"}}}`),
+	}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "nginx:1.27", patched.Image)
+	assert.Equal(t, entity.ContainerStatusCreating, patched.Status, "the status is left alone")
+	assert.Equal(t, reported.State, patched.State)
+	assert.Equal(t, map[string]string{"PORT": "8080", "DEBUG": "1"}, patched.Spec.Env)
+	assert.Equal(t, entity.Labels{"app": "web"}, patched.Labels)
+	assert.Equal(t, reported.ResourceVersion+1, patched.ResourceVersion)
+
+	patched, err = containerService.PatchContainer(ctx, created.ID, patch.Patch{
+		Type: patch.JSONPatchType,
+		Data: []byte(`[{"op": "test", "path": "/image", "value": "nginx:1.27"}, {"op": "add", "path": "/labels/tier", "value": "frontend"}]`),
+	}, patched.ResourceVersion)
+	require.NoError(t, err)
+	assert.Equal(t, entity.Labels{"app": "web", "tier": "frontend"}, patched.Labels)
+
+	_, err = containerService.PatchContainer(ctx, created.ID, patch.Patch{
+		Type: patch.MergePatchType,
+		Data: []byte(`{"image": "redis"}`),
+	}, patched.ResourceVersion-1)
+	assert.ErrorIs(t, err, usecase.ResourceVersionConflictErr)
+	_, err = containerService.PatchContainer(ctx, created.ID, patch.Patch{
+		Type: patch.MergePatchType,
+		Data: []byte(`{"image": "redis", "resource_version": 1}`),
+	}, 0)
+	assert.ErrorIs(t, err, usecase.ResourceVersionConflictErr, "a resource version in the patch is checked as well")
+	_, err = containerService.PatchContainer(ctx, created.ID, patch.Patch{
+		Type: patch.MergePatchType,
+		Data: []byte(`{"status": "running"}`),
+	}, 0)
+	assert.ErrorIs(t, err, usecase.InvalidPatchErr, "the status is reported through UpdateStatus")
+	for _, data := range []string{
+		`{"state": {"reason": "patched"}}`,
+		`{"resources": {"cpu": 100}}`,
+		`{"node_id": "00000000-0000-0000-0000-000000000000"}`,
+		`{"placement": {"node_selector": {"zone": "a"}}}`,
+		`{"owner": {"kind": "job", "id": "00000000-0000-0000-0000-000000000000"}}`,
+	} {
+		_, err = containerService.PatchContainer(ctx, created.ID, patch.Patch{Type: patch.MergePatchType, Data: []byte(data)}, 0)
+		assert.ErrorIs(t, err, usecase.InvalidPatchErr, data)
+	}
+	_, err = containerService.PatchContainer(ctx, created.ID, patch.Patch{
+		Type: patch.JSONPatchType,
+		Data: []byte(`[{"op": "replace", "path": "/image", "value": ""}]`),
+	}, 0)
+	assert.ErrorIs(t, err, usecase.InvalidContainerSpecErr)
+
+	got, err := containerService.GetContainer(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, patched, got, "rejected patches are not stored")
+}
+
+func TestUpdateContainerRejectsInvalidTransition(t *testing.T) {
+	ctx := context.Background()
+	nodeService, containerService := newServices(t)
+
+	target, err := nodeService.AddNode(ctx, &entity.AddNode{})
+	require.NoError(t, err)
+	created, err := containerService.AddContainer(ctx, &entity.AddContainer{NodeID: target.ID, Image: "nginx"})
+	require.NoError(t, err)
+
+	update := *created
+	update.Status = entity.ContainerStatusRunning
+	assert.ErrorIs(t, containerService.UpdateContainer(ctx, &update), usecase.InvalidStatusTransitionErr)
+
+	update.Status = entity.ContainerStatusCreating
+	require.NoError(t, containerService.UpdateContainer(ctx, &update))
+
+	history, err := containerService.ListStatusHistory(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	assert.ErrorIs(t, nodeService.DeleteNode(ctx, target.ID), usecase.NodeHasContainersErr)
+	require.NoError(t, containerService.RemoveContainer(ctx, created.ID))
+	assert.ErrorIs(t, containerService.RemoveContainer(ctx, created.ID), usecase.ContainerNotFoundErr)
+	require.NoError(t, nodeService.DeleteNode(ctx, target.ID))
+}
+
+func TestAddContainerRecordsPlacement(t *testing.T) {
+	ctx := context.Background()
+	nodeService, containerService := newServices(t)
+
+	edge, err := nodeService.AddNode(ctx, &entity.AddNode{Labels: entity.Labels{"tier": "edge"}})
+	require.NoError(t, err)
+	core, err := nodeService.AddNode(ctx, &entity.AddNode{Labels: entity.Labels{"tier": "core"}})
+	require.NoError(t, err)
+	for _, target := range []*entity.Node{edge, core} {
+		target.Status = entity.RunningNodeStatus
+		require.NoError(t, nodeService.UpdateNode(ctx, target))
+	}
+
+	placement := entity.Placement{NodeAffinity: "tier!=edge"}
+	created, err := containerService.AddContainer(ctx, &entity.AddContainer{Image: "batch", Placement: placement})
+	require.NoError(t, err)
+	assert.Equal(t, core.ID, created.NodeID)
+	assert.Contains(t, created.PlacementReasons, `node labels match node affinity "tier!=edge"`)
+
+	stored, err := containerService.GetContainer(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.PlacementReasons, stored.PlacementReasons)
+
+	_, err = containerService.AddContainer(ctx, &entity.AddContainer{NodeID: edge.ID, Image: "batch", Placement: placement})
+	assert.ErrorIs(t, err, usecase.PlacementConstraintErr)
+
+	_, err = containerService.AddContainer(ctx, &entity.AddContainer{
+		Image:     "batch",
+		Placement: entity.Placement{NodeAffinity: "tier in edge"},
+	})
+	assert.ErrorIs(t, err, usecase.InvalidPlacementErr)
+}
+
+func TestEvictUntoleratedMovesContainers(t *testing.T) {
+	ctx := context.Background()
+	nodeService, containerService := newServices(t)
+
+	var nodes []*entity.Node
+	for range 2 {
+		target, err := nodeService.AddNode(ctx, &entity.AddNode{})
+		require.NoError(t, err)
+		target.Status = entity.RunningNodeStatus
+		require.NoError(t, nodeService.UpdateNode(ctx, target))
+		nodes = append(nodes, target)
+	}
+	source := nodes[0]
+
+	plain, err := containerService.AddContainer(ctx, &entity.AddContainer{NodeID: source.ID, Image: "nginx"})
+	require.NoError(t, err)
+	tolerant, err := containerService.AddContainer(ctx, &entity.AddContainer{
+		NodeID: source.ID,
+		Image:  "agent",
+		Spec: entity.ContainerSpec{Tolerations: []entity.Toleration{
+			{Key: "maintenance", Operator: entity.TolerationOpExists, Effect: entity.TaintEffectNoExecute},
+		}},
+	})
+	require.NoError(t, err)
+
+	source.Taints = []entity.Taint{{Key: "maintenance", Effect: entity.TaintEffectNoExecute}}
+	require.NoError(t, nodeService.UpdateNode(ctx, source))
+	_, err = containerService.AddContainer(ctx, &entity.AddContainer{NodeID: source.ID, Image: "nginx"})
+	assert.ErrorIs(t, err, usecase.PlacementConstraintErr)
+
+	source, err = nodeService.GetNode(ctx, source.ID)
+	require.NoError(t, err)
+	evicted, err := containerService.EvictUntolerated(ctx, source)
+	require.NoError(t, err)
+	assert.Equal(t, 1, evicted)
+
+	moved, err := containerService.GetContainer(ctx, plain.ID)
+	require.NoError(t, err)
+	assert.Equal(t, nodes[1].ID, moved.NodeID)
+	assert.Equal(t, entity.ContainerStatusPending, moved.Status)
+	assert.Empty(t, moved.RescheduleReason)
+
+	kept, err := containerService.GetContainer(ctx, tolerant.ID)
+	require.NoError(t, err)
+	assert.Equal(t, source.ID, kept.NodeID)
+
+	nodes[1].Taints = []entity.Taint{{Key: "maintenance", Effect: entity.TaintEffectNoExecute}}
+	require.NoError(t, nodeService.UpdateNode(ctx, nodes[1]))
+	target, err := nodeService.GetNode(ctx, nodes[1].ID)
+	require.NoError(t, err)
+	_, err = containerService.EvictUntolerated(ctx, target)
+	require.NoError(t, err)
+
+	stuck, err := containerService.GetContainer(ctx, plain.ID)
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, stuck.NodeID)
+	assert.Equal(t, entity.ContainerStatusFailed, stuck.Status)
+	assert.Contains(t, stuck.RescheduleReason, "maintenance:NoExecute")
+}
+
+func TestDrainNode(t *testing.T) {
+	ctx := context.Background()
+	nodeService, containerService := newServices(t)
+
+	var nodes []*entity.Node
+	for _, zone := range []string{"eu", "us"} {
+		target, err := nodeService.AddNode(ctx, &entity.AddNode{Labels: entity.Labels{"zone": zone}})
+		require.NoError(t, err)
+		target.Status = entity.RunningNodeStatus
+		require.NoError(t, nodeService.UpdateNode(ctx, target))
+		nodes = append(nodes, target)
+	}
+	source := nodes[0]
+
+	movable, err := containerService.AddContainer(ctx, &entity.AddContainer{NodeID: source.ID, Image: "nginx"})
+	require.NoError(t, err)
+	pinned, err := containerService.AddContainer(ctx, &entity.AddContainer{
+		NodeID:    source.ID,
+		Image:     "db",
+		Placement: entity.Placement{NodeSelector: entity.Labels{"zone": "eu"}},
+	})
+	require.NoError(t, err)
+
+	drain, err := containerService.DrainNode(ctx, source.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.ContainerMove{{ContainerID: movable.ID, NodeID: nodes[1].ID}}, drain.Moved)
+	require.Len(t, drain.Failed, 1)
+	assert.Equal(t, pinned.ID, drain.Failed[0].ContainerID)
+
+	cordoned, err := nodeService.GetNode(ctx, source.ID)
+	require.NoError(t, err)
+	assert.True(t, cordoned.Unschedulable)
+	_, err = containerService.AddContainer(ctx, &entity.AddContainer{NodeID: source.ID, Image: "nginx"})
+	assert.ErrorIs(t, err, usecase.PlacementConstraintErr)
+
+	_, err = containerService.AddContainer(ctx, &entity.AddContainer{
+		Image:     "db",
+		Placement: entity.Placement{NodeSelector: entity.Labels{"zone": "eu"}},
+	})
+	assert.ErrorIs(t, err, usecase.NoEligibleNodeErr)
+
+	_, err = nodeService.Uncordon(ctx, source.ID)
+	require.NoError(t, err)
+	_, err = containerService.DrainNode(ctx, uuid.New())
+	assert.ErrorIs(t, err, usecase.NodeNotFoundErr)
+}
+
+func TestReschedulerMovesContainersOffFailedNodes(t *testing.T) {
+	ctx := context.Background()
+	nodeService, containerService := newServices(t)
+	rescheduler := container.NewRescheduler(containerService, time.Second, time.Minute)
+
+	var nodes []*entity.Node
+	for range 2 {
+		target, err := nodeService.AddNode(ctx, &entity.AddNode{})
+		require.NoError(t, err)
+		target.Status = entity.RunningNodeStatus
+		require.NoError(t, nodeService.UpdateNode(ctx, target))
+		nodes = append(nodes, target)
+	}
+	created, err := containerService.AddContainer(ctx, &entity.AddContainer{NodeID: nodes[0].ID, Image: "nginx"})
+	require.NoError(t, err)
+
+	nodes[0].Status = entity.FailedNodeStatus
+	require.NoError(t, nodeService.UpdateNode(ctx, nodes[0]))
+
+	start := time.Now()
+	require.NoError(t, rescheduler.Reconcile(ctx, start))
+	require.NoError(t, rescheduler.Reconcile(ctx, start.Add(30*time.Second)))
+	kept, err := containerService.GetContainer(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, nodes[0].ID, kept.NodeID, "containers stay during the delay")
+
+	require.NoError(t, rescheduler.Reconcile(ctx, start.Add(time.Minute)))
+	moved, err := containerService.GetContainer(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, nodes[1].ID, moved.NodeID)
+	assert.Equal(t, entity.ContainerStatusPending, moved.Status)
+
+	events, err := containerService.ListEvents(ctx, created.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, entity.EventReasonRescheduled, events[0].Reason)
+	assert.Contains(t, events[0].Message, nodes[0].ID.String())
+	assert.Contains(t, events[0].Message, nodes[1].ID.String())
+}
+
+func TestUpdateStatusStoresReportedState(t *testing.T) {
+	ctx := context.Background()
+	nodeService, containerService := newServices(t)
+
+	target, err := nodeService.AddNode(ctx, &entity.AddNode{})
+	require.NoError(t, err)
+	created, err := containerService.AddContainer(ctx, &entity.AddContainer{NodeID: target.ID, Image: "nginx"})
+	require.NoError(t, err)
+
+	startedAt := time.Now().UTC()
+	exitCode := 137
+	_, err = containerService.UpdateStatus(ctx, created.ID, &entity.UpdateContainerStatus{
+		Status: entity.ContainerStatusCreating,
+		State:  entity.ContainerState{ExitCode: &exitCode},
+	})
+	assert.ErrorIs(t, err, entity.InvalidContainerStateErr, "exit codes need a terminated container")
+	_, err = containerService.UpdateStatus(ctx, created.ID, &entity.UpdateContainerStatus{Status: entity.ContainerStatusRunning})
+	assert.ErrorIs(t, err, usecase.InvalidStatusTransitionErr)
+
+	for _, status := range []entity.ContainerStatus{entity.ContainerStatusCreating, entity.ContainerStatusRunning} {
+		_, err = containerService.UpdateStatus(ctx, created.ID, &entity.UpdateContainerStatus{
+			Status: status,
+			State:  entity.ContainerState{StartedAt: &startedAt},
+		})
+		require.NoError(t, err)
+	}
+	finishedAt := startedAt.Add(time.Minute)
+	state := entity.ContainerState{
+		ExitCode:     &exitCode,
+		Signal:       "SIGKILL",
+		OOMKilled:    true,
+		RestartCount: 3,
+		StartedAt:    &startedAt,
+		FinishedAt:   &finishedAt,
+		Reason:       "killed after exceeding its memory limit",
+	}
+	failed, err := containerService.UpdateStatus(ctx, created.ID, &entity.UpdateContainerStatus{
+		Status: entity.ContainerStatusFailed,
+		State:  state,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, state, failed.State)
+
+	update := *failed
+	update.Spec.Env = map[string]string{"DEBUG": "1"}
+	update.State = entity.ContainerState{}
+	require.NoError(t, containerService.UpdateContainer(ctx, &update))
+	stored, err := containerService.GetContainer(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, state, stored.State, "spec updates keep the reported state")
+	assert.Equal(t, "1", stored.Spec.Env["DEBUG"])
+
+	history, err := containerService.ListStatusHistory(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 4)
+}
+
+func TestReadinessProbeDecidesReady(t *testing.T) {
+	ctx := context.Background()
+	nodeService, containerService := newServices(t)
+
+	target, err := nodeService.AddNode(ctx, &entity.AddNode{})
+	require.NoError(t, err)
+	_, err = containerService.AddContainer(ctx, &entity.AddContainer{
+		NodeID: target.ID,
+		Image:  "nginx",
+		Spec:   entity.ContainerSpec{ReadinessProbe: &entity.Probe{}},
+	})
+	assert.ErrorIs(t, err, usecase.InvalidContainerSpecErr)
+	_, err = containerService.AddContainer(ctx, &entity.AddContainer{
+		NodeID: target.ID,
+		Image:  "nginx",
+		Spec: entity.ContainerSpec{LivenessProbe: &entity.Probe{
+			TCPSocket:        &entity.TCPSocketAction{Port: 80},
+			SuccessThreshold: 2,
+		}},
+	})
+	assert.ErrorIs(t, err, usecase.InvalidContainerSpecErr)
+
+	created, err := containerService.AddContainer(ctx, &entity.AddContainer{
+		NodeID: target.ID,
+		Image:  "nginx",
+		Spec: entity.ContainerSpec{ReadinessProbe: &entity.Probe{
+			HTTPGet: &entity.HTTPGetAction{Path: "/healthz", Port: 8080},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "/healthz", created.Spec.ReadinessProbe.HTTPGet.Path)
+	assert.Equal(t, entity.DefaultProbePeriodSeconds, created.Spec.ReadinessProbe.PeriodSeconds)
+	assert.False(t, created.IsReady())
+
+	running, err := containerService.UpdateStatus(ctx, created.ID, &entity.UpdateContainerStatus{Status: entity.ContainerStatusCreating})
+	require.NoError(t, err)
+	running, err = containerService.UpdateStatus(ctx, running.ID, &entity.UpdateContainerStatus{Status: entity.ContainerStatusRunning})
+	require.NoError(t, err)
+	assert.False(t, running.IsReady(), "running but the readiness probe has not succeeded yet")
+
+	ready, err := containerService.UpdateStatus(ctx, created.ID, &entity.UpdateContainerStatus{
+		Status: entity.ContainerStatusRunning,
+		State: entity.ContainerState{Readiness: &entity.ProbeResult{
+			Succeeded:            true,
+			ConsecutiveSuccesses: 1,
+			ProbedAt:             time.Now().UTC(),
+		}},
+	})
+	require.NoError(t, err)
+	assert.True(t, ready.IsReady())
+
+	stored, err := containerService.GetContainer(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsReady())
+
+	update := *stored
+	update.Spec.ReadinessProbe = nil
+	update.Status = entity.ContainerStatusStopped
+	require.NoError(t, containerService.UpdateContainer(ctx, &update))
+	stopped, err := containerService.GetContainer(ctx, created.ID)
+	require.NoError(t, err)
+	assert.False(t, stopped.IsReady())
+	assert.Equal(t, "NotRunning", stopped.Condition(entity.ContainerConditionLive).Reason)
+}
+
+func TestAddContainersAccountsForTheBatch(t *testing.T) {
+	ctx := context.Background()
+	nodeService, containerService := newServices(t)
+
+	target, err := nodeService.AddNode(ctx, &entity.AddNode{Capacity: entity.Resources{CPU: 1000}})
+	require.NoError(t, err)
+	target.Status = entity.RunningNodeStatus
+	require.NoError(t, nodeService.UpdateNode(ctx, target))
+
+	items := []entity.AddContainer{
+		{Image: "nginx", Resources: entity.Resources{CPU: 400}},
+		{NodeID: target.ID, Image: "redis", Resources: entity.Resources{CPU: 400}},
+		{Image: "postgres", Resources: entity.Resources{CPU: 400}},
+	}
+	created, errs, err := containerService.AddContainers(ctx, &entity.BatchAddContainers{Items: items})
+	require.NoError(t, err)
+	assert.ErrorIs(t, errs[0], usecase.BatchAbortedErr)
+	assert.ErrorIs(t, errs[1], usecase.BatchAbortedErr)
+	assert.ErrorIs(t, errs[2], usecase.NoEligibleNodeErr, "the first two containers fill the node")
+	assert.Equal(t, []*entity.Container{nil, nil, nil}, created)
+	list, _, err := containerService.ListContainers(ctx, entity.ListContainersOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	created, errs, err = containerService.AddContainers(ctx, &entity.BatchAddContainers{
+		Mode:  entity.BatchModeBestEffort,
+		Items: items,
+	})
+	require.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.ErrorIs(t, errs[2], usecase.NoEligibleNodeErr)
+	require.NotNil(t, created[1])
+	assert.Equal(t, target.ID, created[1].NodeID)
+	assert.Nil(t, created[2])
+	list, _, err = containerService.ListContainers(ctx, entity.ListContainersOptions{})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	_, _, err = containerService.AddContainers(ctx, &entity.BatchAddContainers{Mode: "some"})
+	assert.ErrorIs(t, err, entity.InvalidBatchErr)
+}
+
+func TestUpdateAndRemoveContainers(t *testing.T) {
+	ctx := context.Background()
+	nodeService, containerService := newServices(t)
+
+	target, err := nodeService.AddNode(ctx, &entity.AddNode{})
+	require.NoError(t, err)
+	first, err := containerService.AddContainer(ctx, &entity.AddContainer{NodeID: target.ID, Image: "nginx"})
+	require.NoError(t, err)
+	second, err := containerService.AddContainer(ctx, &entity.AddContainer{NodeID: target.ID, Image: "redis"})
+	require.NoError(t, err)
+
+	valid, invalid := *first, *second
+	valid.Image = "nginx:1.27"
+	invalid.Status = entity.ContainerStatusRunning
+	updated, errs, err := containerService.UpdateContainers(ctx, &entity.BatchUpdateContainers{
+		Items: []entity.Container{valid, invalid},
+	})
+	require.NoError(t, err)
+	assert.ErrorIs(t, errs[0], usecase.BatchAbortedErr)
+	assert.ErrorIs(t, errs[1], usecase.InvalidStatusTransitionErr)
+	assert.Nil(t, updated[0])
+	got, err := containerService.GetContainer(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "nginx", got.Image)
+
+	updated, errs, err = containerService.UpdateContainers(ctx, &entity.BatchUpdateContainers{
+		Mode:  entity.BatchModeBestEffort,
+		Items: []entity.Container{valid, invalid},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], usecase.InvalidStatusTransitionErr)
+	require.NotNil(t, updated[0])
+	assert.Equal(t, "nginx:1.27", updated[0].Image)
+
+	errs, err = containerService.RemoveContainers(ctx, &entity.BatchDelete{
+		Mode: entity.BatchModeBestEffort,
+		IDs:  []uuid.UUID{first.ID, uuid.New(), second.ID},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], usecase.ContainerNotFoundErr)
+	assert.NoError(t, errs[2])
+	list, _, err := containerService.ListContainers(ctx, entity.ListContainersOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
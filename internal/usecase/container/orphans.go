@@ -0,0 +1,30 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+)
+
+// RemoveOrphans removes the containers owned by resources of kind for which
+// ownerExists reports false. Controllers use it to collect the containers of
+// deleted resources, ownerExists should report true when in doubt.
+func RemoveOrphans(ctx context.Context, service IService, kind string, ownerExists func(id uuid.UUID) bool) error {
+	containers, _, err := service.ListContainers(ctx, entity.ListContainersOptions{OwnerKind: kind})
+	if err != nil {
+		return fmt.Errorf("listing containers: %w", err)
+	}
+	for _, owned := range containers {
+		if owned.Owner == nil || ownerExists(owned.Owner.ID) {
+			continue
+		}
+		err = service.RemoveContainer(ctx, owned.ID)
+		if err != nil && !errors.Is(err, usecase.ContainerNotFoundErr) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"log"
+	"time"
+)
+
+// Rescheduler moves containers off nodes that have been failed for longer
+// than delay to running nodes, and retries containers that could not be moved
+// earlier. Every move is recorded as a container event.
+type Rescheduler struct {
+	service  *Service
+	interval time.Duration
+	delay    time.Duration
+	// failedSince is when each failed node was first seen failed.
+	failedSince map[uuid.UUID]time.Time
+}
+
+func NewRescheduler(service *Service, interval, delay time.Duration) *Rescheduler {
+	return &Rescheduler{
+		service:     service,
+		interval:    interval,
+		delay:       delay,
+		failedSince: make(map[uuid.UUID]time.Time),
+	}
+}
+
+// Run blocks until ctx is cancelled, reconciling every interval.
+func (r *Rescheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Reconcile(ctx, time.Now()); err != nil {
+				log.Printf("rescheduler: %v", err)
+			}
+		}
+	}
+}
+
+// Reconcile reschedules the containers of nodes failed since before now
+// minus the delay and retries containers waiting to be rescheduled.
+func (r *Rescheduler) Reconcile(ctx context.Context, now time.Time) error {
+	nodes, _, err := r.service.nodeService.ListNodes(ctx, entity.ListNodesOptions{Status: entity.FailedNodeStatus})
+	if err != nil {
+		return fmt.Errorf("listing failed nodes: %w", err)
+	}
+
+	failedSince := make(map[uuid.UUID]time.Time, len(nodes))
+	for _, node := range nodes {
+		since, ok := r.failedSince[node.ID]
+		if !ok {
+			since = now
+		}
+		failedSince[node.ID] = since
+		if now.Sub(since) < r.delay {
+			continue
+		}
+
+		reason := fmt.Sprintf("node %s has been failed since %s", node.ID, since.UTC().Format(time.RFC3339))
+		for i := range node.Containers {
+			container := &node.Containers[i]
+			if container.Status == entity.ContainerStatusTerminating || container.RescheduleReason != "" {
+				continue
+			}
+			r.reschedule(ctx, container, reason)
+		}
+	}
+	// Nodes that recovered or were deleted start over when they fail again.
+	r.failedSince = failedSince
+
+	containers, _, err := r.service.repo.List(ctx, entity.ListContainersOptions{Rescheduling: true})
+	if err != nil {
+		return fmt.Errorf("listing containers: %w", err)
+	}
+	for i := range containers {
+		r.reschedule(ctx, &containers[i], containers[i].RescheduleReason)
+	}
+	return nil
+}
+
+func (r *Rescheduler) reschedule(ctx context.Context, container *entity.Container, reason string) {
+	updated, err := r.service.Reschedule(ctx, container, reason)
+	if err != nil {
+		log.Printf("rescheduler: rescheduling container %s: %v", container.ID, err)
+		return
+	}
+	if updated.NodeID != container.NodeID {
+		log.Printf("rescheduler: moved container %s from node %s to node %s", container.ID, container.NodeID, updated.NodeID)
+	}
+}
@@ -0,0 +1,113 @@
+package container
+
+import (
+	"fmt"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/scheduler"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"strings"
+)
+
+const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+)
+
+// ValidateTemplate checks a template containers are created from by
+// controllers and fills in the spec defaults, it fails like AddContainer.
+func ValidateTemplate(template *entity.ContainerTemplate) error {
+	_, err := validate(template.Image, template.Resources, &template.Spec, template.Labels, template.Placement)
+	return err
+}
+
+// validate checks everything a new container is made of and returns its
+// parsed placement constraints.
+func validate(
+	image string,
+	resources entity.Resources,
+	spec *entity.ContainerSpec,
+	labels entity.Labels,
+	placement entity.Placement,
+) (*scheduler.Constraints, error) {
+	if err := resources.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateSpec(image, spec); err != nil {
+		return nil, err
+	}
+	if err := labels.Validate(); err != nil {
+		return nil, err
+	}
+	return scheduler.NewConstraints(placement)
+}
+
+// validateSpec checks the container spec and fills in defaults for the
+// restart policy, port protocols, toleration operators and probes.
+func validateSpec(image string, spec *entity.ContainerSpec) error {
+	if strings.TrimSpace(image) == "" {
+		return fmt.Errorf("%w: image is required", usecase.InvalidContainerSpecErr)
+	}
+
+	if spec.RestartPolicy == "" {
+		spec.RestartPolicy = entity.RestartPolicyAlways
+	}
+	if err := spec.RestartPolicy.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", usecase.InvalidContainerSpecErr, err)
+	}
+
+	if spec.WorkingDir != "" && !strings.HasPrefix(spec.WorkingDir, "/") {
+		return fmt.Errorf("%w: working dir must be an absolute path", usecase.InvalidContainerSpecErr)
+	}
+
+	for key := range spec.Env {
+		if key == "" || strings.Contains(key, "=") {
+			return fmt.Errorf("%w: invalid env variable name %q", usecase.InvalidContainerSpecErr, key)
+		}
+	}
+
+	seen := make(map[entity.ContainerPort]bool, len(spec.Ports))
+	for i := range spec.Ports {
+		port := &spec.Ports[i]
+		if port.ContainerPort < 1 || port.ContainerPort > 65535 {
+			return fmt.Errorf("%w: port %d is out of range", usecase.InvalidContainerSpecErr, port.ContainerPort)
+		}
+		port.Protocol = strings.ToLower(port.Protocol)
+		if port.Protocol == "" {
+			port.Protocol = ProtocolTCP
+		}
+		if port.Protocol != ProtocolTCP && port.Protocol != ProtocolUDP {
+			return fmt.Errorf("%w: unsupported protocol %q", usecase.InvalidContainerSpecErr, port.Protocol)
+		}
+		if seen[*port] {
+			return fmt.Errorf("%w: port %d/%s is exposed twice", usecase.InvalidContainerSpecErr, port.ContainerPort, port.Protocol)
+		}
+		seen[*port] = true
+	}
+
+	for i := range spec.Tolerations {
+		toleration := &spec.Tolerations[i]
+		if toleration.Operator == "" {
+			toleration.Operator = entity.TolerationOpEqual
+		}
+		if err := toleration.Validate(); err != nil {
+			return fmt.Errorf("%w: %s", usecase.InvalidContainerSpecErr, err)
+		}
+	}
+
+	if spec.LivenessProbe != nil {
+		if err := spec.LivenessProbe.Validate(); err != nil {
+			return fmt.Errorf("%w: liveness probe: %s", usecase.InvalidContainerSpecErr, err)
+		}
+		// A single successful liveness probe makes a container live again.
+		if spec.LivenessProbe.SuccessThreshold != 1 {
+			return fmt.Errorf("%w: liveness probe: success_threshold must be 1", usecase.InvalidContainerSpecErr)
+		}
+	}
+	if spec.ReadinessProbe != nil {
+		if err := spec.ReadinessProbe.Validate(); err != nil {
+			return fmt.Errorf("%w: readiness probe: %s", usecase.InvalidContainerSpecErr, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package container
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+)
+
+func TestValidateSpec_Defaults(t *testing.T) {
+	spec := entity.ContainerSpec{
+		Ports: []entity.ContainerPort{{ContainerPort: 8080}},
+	}
+	err := validateSpec("nginx", &spec)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.RestartPolicyAlways, spec.RestartPolicy)
+	assert.Equal(t, ProtocolTCP, spec.Ports[0].Protocol)
+}
+
+func TestValidateSpec_Invalid(t *testing.T) {
+	cases := map[string]entity.ContainerSpec{
+		"restart policy": {RestartPolicy: "sometimes"},
+		"working dir":    {WorkingDir: "relative/dir"},
+		"env name":       {Env: map[string]string{"A=B": "c"}},
+		"port range":     {Ports: []entity.ContainerPort{{ContainerPort: 70000}}},
+		"protocol":       {Ports: []entity.ContainerPort{{ContainerPort: 80, Protocol: "sctp"}}},
+		"duplicate port": {Ports: []entity.ContainerPort{{ContainerPort: 80}, {ContainerPort: 80, Protocol: "TCP"}}},
+	}
+	for name, spec := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateSpec("nginx", &spec)
+			assert.ErrorIs(t, err, usecase.InvalidContainerSpecErr)
+		})
+	}
+
+	err := validateSpec(" ", &entity.ContainerSpec{})
+	assert.ErrorIs(t, err, usecase.InvalidContainerSpecErr)
+}
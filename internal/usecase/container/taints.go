@@ -0,0 +1,97 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"log"
+	"time"
+)
+
+// TaintManager evicts containers from nodes with NoExecute taints they do not
+// tolerate. It reacts to node watch events and resyncs every interval.
+// Containers that could not be moved are retried by the Rescheduler.
+type TaintManager struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewTaintManager(service *Service, interval time.Duration) *TaintManager {
+	return &TaintManager{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (m *TaintManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	var resourceVersion uint64
+	m.resync(ctx)
+	for ctx.Err() == nil {
+		sub, err := m.service.nodeService.Watch(ctx, resourceVersion)
+		if errors.Is(err, usecase.ResourceVersionTooOldErr) {
+			resourceVersion = 0
+			m.resync(ctx)
+			continue
+		}
+		if err != nil {
+			log.Printf("taint manager: watching nodes: %v", err)
+			return
+		}
+		resourceVersion = m.watch(ctx, sub.Events(), ticker.C, resourceVersion)
+	}
+}
+
+// watch handles events until the subscription ends and returns the last resource version seen.
+func (m *TaintManager) watch(
+	ctx context.Context,
+	events <-chan entity.WatchEvent,
+	tick <-chan time.Time,
+	resourceVersion uint64,
+) uint64 {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion
+		case <-tick:
+			m.resync(ctx)
+		case event, ok := <-events:
+			if !ok {
+				return resourceVersion
+			}
+			resourceVersion = event.ResourceVersion
+			node, isNode := event.Object.(*entity.Node)
+			if isNode && event.Type != entity.WatchEventDeleted {
+				m.evict(ctx, node)
+			}
+		}
+	}
+}
+
+func (m *TaintManager) resync(ctx context.Context) {
+	nodes, _, err := m.service.nodeService.ListNodes(ctx, entity.ListNodesOptions{})
+	if err != nil {
+		log.Printf("taint manager: listing nodes: %v", err)
+		return
+	}
+	for _, node := range nodes {
+		m.evict(ctx, node)
+	}
+}
+
+func (m *TaintManager) evict(ctx context.Context, node *entity.Node) {
+	if len(entity.UntoleratedTaints(node.Taints, nil, entity.TaintEffectNoExecute)) == 0 {
+		return
+	}
+	evicted, err := m.service.EvictUntolerated(ctx, node)
+	if err != nil {
+		log.Printf("taint manager: evicting containers from node %s: %v", node.ID, err)
+	}
+	if evicted > 0 {
+		log.Printf("taint manager: evicted %d container(s) from node %s", evicted, node.ID)
+	}
+}
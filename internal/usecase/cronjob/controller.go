@@ -0,0 +1,187 @@
+package cronjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"log"
+	"slices"
+	"time"
+)
+
+// Controller creates the jobs of cron jobs when their schedule is due and
+// deletes finished jobs beyond the history limits. When several runs were
+// missed, for example while the controller was down, only the latest one is
+// started.
+type Controller struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewController(service *Service, interval time.Duration) *Controller {
+	return &Controller{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run blocks until ctx is cancelled, reconciling every interval.
+func (c *Controller) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := c.Reconcile(ctx, now.UTC()); err != nil {
+				log.Printf("cron job controller: %v", err)
+			}
+		}
+	}
+}
+
+// Reconcile starts the jobs of every cron job that are due at now.
+func (c *Controller) Reconcile(ctx context.Context, now time.Time) error {
+	cronJobs, _, err := c.service.repo.List(ctx, entity.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing cron jobs: %w", err)
+	}
+	for i := range cronJobs {
+		if err = c.reconcile(ctx, &cronJobs[i], now); err != nil {
+			log.Printf("cron job controller: cron job %s: %v", cronJobs[i].Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *Controller) reconcile(ctx context.Context, cronJob *entity.CronJob, now time.Time) error {
+	jobs, _, err := c.service.jobService.ListJobs(ctx, entity.ListJobsOptions{OwnerID: cronJob.ID})
+	if err != nil {
+		return err
+	}
+
+	status := cronJob.Status
+	if due := c.due(cronJob, now); !due.IsZero() && !cronJob.Suspend {
+		status.LastScheduleTime = &due
+		status.Message = ""
+		if jobs, err = c.start(ctx, cronJob, jobs, due); err != nil {
+			status.Message = err.Error()
+		}
+	}
+
+	if jobs, err = c.prune(ctx, cronJob, jobs); err != nil {
+		return err
+	}
+	status.Active = []uuid.UUID{}
+	for _, owned := range jobs {
+		if !owned.IsFinished() {
+			status.Active = append(status.Active, owned.ID)
+		}
+	}
+
+	_, err = c.service.repo.Update(ctx, cronJob.ID, func(current *entity.CronJob) error {
+		current.Status = status
+		return nil
+	})
+	if errors.Is(err, usecase.CronJobNotFoundErr) {
+		return nil
+	}
+	return err
+}
+
+// due returns the latest scheduled time after the last scheduled one that is
+// not after now, the zero time when nothing is due.
+func (c *Controller) due(cronJob *entity.CronJob, now time.Time) time.Time {
+	schedule, err := entity.ParseCronSchedule(cronJob.Schedule)
+	if err != nil {
+		log.Printf("cron job controller: cron job %s: %v", cronJob.Name, err)
+		return time.Time{}
+	}
+	last := cronJob.CreatedAt
+	if cronJob.Status.LastScheduleTime != nil {
+		last = *cronJob.Status.LastScheduleTime
+	}
+
+	var due time.Time
+	for next := schedule.Next(last.UTC()); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		due = next
+	}
+	return due
+}
+
+// start creates the job scheduled at due according to the concurrency
+// policy and returns the jobs of the cron job afterwards.
+func (c *Controller) start(ctx context.Context, cronJob *entity.CronJob, jobs []entity.Job, due time.Time) ([]entity.Job, error) {
+	var active []entity.Job
+	for _, owned := range jobs {
+		if !owned.IsFinished() {
+			active = append(active, owned)
+		}
+	}
+
+	if len(active) > 0 {
+		switch cronJob.ConcurrencyPolicy {
+		case entity.ConcurrencyPolicyForbid:
+			return jobs, fmt.Errorf("skipped the run at %s, job %s is still active", due.Format(time.RFC3339), active[0].Name)
+		case entity.ConcurrencyPolicyReplace:
+			for _, replaced := range active {
+				err := c.service.jobService.DeleteJob(ctx, replaced.ID)
+				if err != nil && !errors.Is(err, usecase.JobNotFoundErr) {
+					return jobs, err
+				}
+			}
+			jobs = slices.DeleteFunc(jobs, func(owned entity.Job) bool { return !owned.IsFinished() })
+		}
+	}
+
+	created, err := c.service.jobService.AddJob(ctx, &entity.AddJob{
+		Name:  fmt.Sprintf("%s-%d", cronJob.Name, due.Unix()),
+		Spec:  cronJob.JobTemplate,
+		Owner: &entity.OwnerReference{Kind: entity.OwnerKindCronJob, ID: cronJob.ID},
+	})
+	if errors.Is(err, usecase.JobExistsErr) {
+		// The job was created before the schedule time could be recorded.
+		return jobs, nil
+	}
+	if err != nil {
+		return jobs, fmt.Errorf("creating the job scheduled at %s: %s", due.Format(time.RFC3339), err)
+	}
+	return append(jobs, *created), nil
+}
+
+// prune deletes the oldest finished jobs beyond the history limits and
+// returns the jobs that are kept.
+func (c *Controller) prune(ctx context.Context, cronJob *entity.CronJob, jobs []entity.Job) ([]entity.Job, error) {
+	// Newest first, so that the jobs beyond the limits are the oldest ones.
+	slices.SortFunc(jobs, func(a, b entity.Job) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	var kept []entity.Job
+	succeeded, failed := 0, 0
+	for _, owned := range jobs {
+		keep := true
+		switch owned.Status.Phase {
+		case entity.JobPhaseSucceeded:
+			succeeded++
+			keep = succeeded <= cronJob.SuccessfulJobsHistoryLimit
+		case entity.JobPhaseFailed:
+			failed++
+			keep = failed <= cronJob.FailedJobsHistoryLimit
+		}
+		if keep {
+			kept = append(kept, owned)
+			continue
+		}
+		err := c.service.jobService.DeleteJob(ctx, owned.ID)
+		if err != nil && !errors.Is(err, usecase.JobNotFoundErr) {
+			return nil, err
+		}
+	}
+	return kept, nil
+}
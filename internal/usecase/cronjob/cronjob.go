@@ -0,0 +1,92 @@
+package cronjob
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/job"
+	"github.com/wensiet/morchy-api/pkg/entity"
+)
+
+type IService interface {
+	GetCronJob(ctx context.Context, id uuid.UUID) (*entity.CronJob, error)
+	ListCronJobs(ctx context.Context, opts entity.ListOptions) ([]entity.CronJob, string, error)
+	AddCronJob(ctx context.Context, req *entity.AddCronJob) (*entity.CronJob, error)
+	UpdateCronJob(ctx context.Context, cronJob *entity.CronJob) (*entity.CronJob, error)
+	DeleteCronJob(ctx context.Context, id uuid.UUID) error
+}
+
+type Service struct {
+	repo       usecase.CronJobRepository
+	jobService job.IService
+}
+
+func NewService(repo usecase.CronJobRepository, jobService job.IService) *Service {
+	return &Service{
+		repo:       repo,
+		jobService: jobService,
+	}
+}
+
+func (s *Service) GetCronJob(ctx context.Context, id uuid.UUID) (*entity.CronJob, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// ListCronJobs returns one page of cron jobs together with the continue token
+// for the next page, which is empty on the last page.
+func (s *Service) ListCronJobs(ctx context.Context, opts entity.ListOptions) ([]entity.CronJob, string, error) {
+	return s.repo.List(ctx, opts)
+}
+
+// AddCronJob stores a new cron job, its jobs are created by the Controller.
+func (s *Service) AddCronJob(ctx context.Context, req *entity.AddCronJob) (*entity.CronJob, error) {
+	cronJob := entity.NewCronJob(req)
+	if err := validate(cronJob); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, cronJob); err != nil {
+		return nil, err
+	}
+	return cronJob, nil
+}
+
+// UpdateCronJob saves the schedule, concurrency policy, suspension, history
+// limits and job template of the cron job. Jobs created already keep the
+// template they were created with.
+func (s *Service) UpdateCronJob(ctx context.Context, cronJob *entity.CronJob) (*entity.CronJob, error) {
+	return s.repo.Update(ctx, cronJob.ID, func(current *entity.CronJob) error {
+		current.Schedule = cronJob.Schedule
+		current.ConcurrencyPolicy = cronJob.ConcurrencyPolicy
+		current.Suspend = cronJob.Suspend
+		current.SuccessfulJobsHistoryLimit = cronJob.SuccessfulJobsHistoryLimit
+		current.FailedJobsHistoryLimit = cronJob.FailedJobsHistoryLimit
+		current.JobTemplate = cronJob.JobTemplate
+		return validate(current)
+	})
+}
+
+// DeleteCronJob removes the cron job together with its jobs.
+func (s *Service) DeleteCronJob(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	jobs, _, err := s.jobService.ListJobs(ctx, entity.ListJobsOptions{OwnerID: id})
+	if err != nil {
+		return err
+	}
+	for _, owned := range jobs {
+		err = s.jobService.DeleteJob(ctx, owned.ID)
+		if err != nil && !errors.Is(err, usecase.JobNotFoundErr) {
+			return err
+		}
+	}
+	return nil
+}
+
+func validate(cronJob *entity.CronJob) error {
+	if err := cronJob.Validate(); err != nil {
+		return err
+	}
+	return job.ValidateSpec(&cronJob.JobTemplate)
+}
@@ -0,0 +1,201 @@
+package cronjob_test
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/internal/usecase/cronjob"
+	"github.com/wensiet/morchy-api/internal/usecase/job"
+	"github.com/wensiet/morchy-api/internal/usecase/node"
+	"github.com/wensiet/morchy-api/internal/usecase/scheduler"
+	"github.com/wensiet/morchy-api/internal/usecase/watch"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+	"time"
+)
+
+type fixture struct {
+	containerService *container.Service
+	jobService       *job.Service
+	jobController    *job.Controller
+	cronJobService   *cronjob.Service
+	controller       *cronjob.Controller
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx := context.Background()
+	store := memory.NewStore()
+	bus := watch.NewBus(10)
+	nodeService := node.NewService(memory.NewNodeRepository(store), bus)
+	containerService := container.NewService(
+		memory.NewContainerRepository(store),
+		nodeService,
+		&scheduler.LeastLoaded{},
+		bus,
+	)
+
+	target, err := nodeService.AddNode(ctx, &entity.AddNode{Capacity: entity.Resources{CPU: 1000}})
+	require.NoError(t, err)
+	target.Status = entity.RunningNodeStatus
+	require.NoError(t, nodeService.UpdateNode(ctx, target))
+
+	jobService := job.NewService(memory.NewJobRepository(store), containerService)
+	cronJobService := cronjob.NewService(memory.NewCronJobRepository(store), jobService)
+	return &fixture{
+		containerService: containerService,
+		jobService:       jobService,
+		jobController:    job.NewController(jobService, time.Second),
+		cronJobService:   cronJobService,
+		controller:       cronjob.NewController(cronJobService, time.Second),
+	}
+}
+
+func (f *fixture) jobs(t *testing.T, owner *entity.CronJob) []entity.Job {
+	jobs, _, err := f.jobService.ListJobs(context.Background(), entity.ListJobsOptions{OwnerID: owner.ID})
+	require.NoError(t, err)
+	return jobs
+}
+
+// complete lets the containers of every active job exit with exitCode.
+func (f *fixture) complete(t *testing.T, now time.Time, exitCode int) {
+	ctx := context.Background()
+	require.NoError(t, f.jobController.Reconcile(ctx, now))
+	containers, _, err := f.containerService.ListContainers(ctx, entity.ListContainersOptions{})
+	require.NoError(t, err)
+	for _, owned := range containers {
+		for _, status := range []entity.ContainerStatus{entity.ContainerStatusCreating, entity.ContainerStatusRunning} {
+			owned.Status = status
+			require.NoError(t, f.containerService.UpdateContainer(ctx, &owned))
+		}
+		_, err = f.containerService.UpdateStatus(ctx, owned.ID, &entity.UpdateContainerStatus{
+			Status: entity.ContainerStatusExited,
+			State:  entity.ContainerState{ExitCode: &exitCode},
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.jobController.Reconcile(ctx, now))
+}
+
+func TestControllerSchedulesJobs(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	one := 1
+
+	report, err := f.cronJobService.AddCronJob(ctx, &entity.AddCronJob{
+		Name:                       "report",
+		Schedule:                   "*/5 * * * *",
+		SuccessfulJobsHistoryLimit: &one,
+		JobTemplate:                entity.JobSpec{Template: entity.ContainerTemplate{Image: "report"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.ConcurrencyPolicyAllow, report.ConcurrencyPolicy)
+	created := report.CreatedAt
+
+	require.NoError(t, f.controller.Reconcile(ctx, created))
+	assert.Empty(t, f.jobs(t, report), "nothing is due yet")
+
+	schedule, err := entity.ParseCronSchedule(report.Schedule)
+	require.NoError(t, err)
+	due := schedule.Next(created)
+	require.NoError(t, f.controller.Reconcile(ctx, due.Add(time.Second)))
+	jobs := f.jobs(t, report)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, &entity.OwnerReference{Kind: entity.OwnerKindCronJob, ID: report.ID}, jobs[0].Owner)
+
+	scheduled, err := f.cronJobService.GetCronJob(ctx, report.ID)
+	require.NoError(t, err)
+	assert.Equal(t, due, *scheduled.Status.LastScheduleTime)
+	assert.Equal(t, []uuid.UUID{jobs[0].ID}, scheduled.Status.Active)
+
+	require.NoError(t, f.controller.Reconcile(ctx, due.Add(time.Minute)))
+	assert.Len(t, f.jobs(t, report), 1, "every run is started once")
+
+	f.complete(t, due.Add(time.Minute), 0)
+	next := due.Add(5 * time.Minute)
+	require.NoError(t, f.controller.Reconcile(ctx, next))
+	f.complete(t, next, 0)
+	require.NoError(t, f.controller.Reconcile(ctx, next))
+	jobs = f.jobs(t, report)
+	require.Len(t, jobs, 1, "succeeded jobs beyond the history limit are deleted")
+	assert.Equal(t, entity.JobPhaseSucceeded, jobs[0].Status.Phase)
+
+	require.NoError(t, f.cronJobService.DeleteCronJob(ctx, report.ID))
+	assert.Empty(t, f.jobs(t, report))
+	_, err = f.cronJobService.GetCronJob(ctx, report.ID)
+	assert.ErrorIs(t, err, usecase.CronJobNotFoundErr)
+}
+
+func TestControllerAppliesConcurrencyPolicy(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	add := func(name string, policy entity.ConcurrencyPolicy) *entity.CronJob {
+		cronJob, err := f.cronJobService.AddCronJob(ctx, &entity.AddCronJob{
+			Name:              name,
+			Schedule:          "@hourly",
+			ConcurrencyPolicy: policy,
+			JobTemplate:       entity.JobSpec{Template: entity.ContainerTemplate{Image: name}},
+		})
+		require.NoError(t, err)
+		return cronJob
+	}
+	forbid := add("forbid", entity.ConcurrencyPolicyForbid)
+	replace := add("replace", entity.ConcurrencyPolicyReplace)
+
+	now := forbid.CreatedAt.Truncate(time.Hour).Add(time.Hour)
+	require.NoError(t, f.controller.Reconcile(ctx, now))
+	first := f.jobs(t, replace)
+	require.Len(t, first, 1)
+	require.Len(t, f.jobs(t, forbid), 1)
+
+	now = now.Add(time.Hour)
+	require.NoError(t, f.controller.Reconcile(ctx, now))
+	assert.Len(t, f.jobs(t, forbid), 1, "the run is skipped while the job is active")
+	skipped, err := f.cronJobService.GetCronJob(ctx, forbid.ID)
+	require.NoError(t, err)
+	assert.Contains(t, skipped.Status.Message, "still active")
+
+	second := f.jobs(t, replace)
+	require.Len(t, second, 1)
+	assert.NotEqual(t, first[0].ID, second[0].ID, "the active job is replaced")
+}
+
+func TestUpdateCronJobValidates(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	_, err := f.cronJobService.AddCronJob(ctx, &entity.AddCronJob{
+		Name:        "report",
+		Schedule:    "61 * * * *",
+		JobTemplate: entity.JobSpec{Template: entity.ContainerTemplate{Image: "report"}},
+	})
+	assert.ErrorIs(t, err, entity.InvalidCronJobErr)
+
+	report, err := f.cronJobService.AddCronJob(ctx, &entity.AddCronJob{
+		Name:        "report",
+		Schedule:    "@daily",
+		JobTemplate: entity.JobSpec{Template: entity.ContainerTemplate{Image: "report"}},
+	})
+	require.NoError(t, err)
+	_, err = f.cronJobService.AddCronJob(ctx, &entity.AddCronJob{
+		Name:        "report",
+		Schedule:    "@daily",
+		JobTemplate: entity.JobSpec{Template: entity.ContainerTemplate{Image: "report"}},
+	})
+	assert.ErrorIs(t, err, usecase.CronJobExistsErr)
+
+	report.ConcurrencyPolicy = "Sometimes"
+	_, err = f.cronJobService.UpdateCronJob(ctx, report)
+	assert.ErrorIs(t, err, entity.InvalidCronJobErr)
+
+	report.ConcurrencyPolicy = entity.ConcurrencyPolicyForbid
+	report.Schedule = "0 3 * * 1-5"
+	updated, err := f.cronJobService.UpdateCronJob(ctx, report)
+	require.NoError(t, err)
+	assert.Equal(t, "0 3 * * 1-5", updated.Schedule)
+	assert.Equal(t, entity.ConcurrencyPolicyForbid, updated.ConcurrencyPolicy)
+}
@@ -0,0 +1,168 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Controller converges every deployment on its replica count by creating
+// containers from the template and removing surplus ones. Containers that
+// stopped, exited or failed without waiting to be rescheduled are replaced.
+type Controller struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewController(service *Service, interval time.Duration) *Controller {
+	return &Controller{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run blocks until ctx is cancelled, reconciling every interval.
+func (c *Controller) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Reconcile(ctx); err != nil {
+				log.Printf("deployment controller: %v", err)
+			}
+		}
+	}
+}
+
+// Reconcile converges every deployment once and removes containers whose
+// deployment no longer exists.
+func (c *Controller) Reconcile(ctx context.Context) error {
+	deployments, _, err := c.service.repo.List(ctx, entity.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing deployments: %w", err)
+	}
+	for i := range deployments {
+		if err = c.reconcile(ctx, &deployments[i]); err != nil {
+			log.Printf("deployment controller: deployment %s: %v", deployments[i].Name, err)
+		}
+	}
+	return c.collectOrphans(ctx, deployments)
+}
+
+func (c *Controller) reconcile(ctx context.Context, deployment *entity.Deployment) error {
+	containers, _, err := c.service.containerService.ListContainers(ctx, entity.ListContainersOptions{OwnerID: deployment.ID})
+	if err != nil {
+		return err
+	}
+
+	var active []entity.Container
+	for _, owned := range containers {
+		switch {
+		case owned.Status == entity.ContainerStatusTerminating:
+		case owned.IsTerminated():
+			if err = c.remove(ctx, owned.ID); err != nil {
+				return err
+			}
+		default:
+			active = append(active, owned)
+		}
+	}
+
+	var problems []string
+	for len(active) < deployment.Replicas {
+		created, err := c.service.containerService.AddContainer(ctx, newContainer(deployment))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("creating a container: %s", err))
+			break
+		}
+		active = append(active, *created)
+	}
+	if surplus := len(active) - deployment.Replicas; surplus > 0 {
+		// Containers that are not ready go first, they serve nothing.
+		slices.SortStableFunc(active, func(a, b entity.Container) int {
+			return compareReadiness(&a, &b)
+		})
+		for _, removed := range active[:surplus] {
+			if err = c.remove(ctx, removed.ID); err != nil {
+				return err
+			}
+		}
+		active = active[surplus:]
+	}
+
+	status := entity.DeploymentStatus{Replicas: len(active), Message: strings.Join(problems, "; ")}
+	for _, owned := range active {
+		if owned.IsReady() {
+			status.ReadyReplicas++
+		}
+	}
+	if status == deployment.Status {
+		return nil
+	}
+	_, err = c.service.repo.Update(ctx, deployment.ID, func(current *entity.Deployment) error {
+		current.Status = status
+		return nil
+	})
+	if errors.Is(err, usecase.DeploymentNotFoundErr) {
+		return nil
+	}
+	return err
+}
+
+// collectOrphans removes containers owned by deployments that are not in deployments.
+func (c *Controller) collectOrphans(ctx context.Context, deployments []entity.Deployment) error {
+	return container.RemoveOrphans(ctx, c.service.containerService, entity.OwnerKindDeployment, func(id uuid.UUID) bool {
+		exists := slices.ContainsFunc(deployments, func(deployment entity.Deployment) bool {
+			return deployment.ID == id
+		})
+		if exists {
+			return true
+		}
+		// The deployment may have been created after it was listed.
+		_, err := c.service.repo.Get(ctx, id)
+		return !errors.Is(err, usecase.DeploymentNotFoundErr)
+	})
+}
+
+func (c *Controller) remove(ctx context.Context, id uuid.UUID) error {
+	err := c.service.containerService.RemoveContainer(ctx, id)
+	if errors.Is(err, usecase.ContainerNotFoundErr) {
+		return nil
+	}
+	return err
+}
+
+func newContainer(deployment *entity.Deployment) *entity.AddContainer {
+	return &entity.AddContainer{
+		Image:     deployment.Template.Image,
+		Resources: deployment.Template.Resources,
+		Spec:      deployment.Template.Spec,
+		Labels:    deployment.Template.Labels,
+		Placement: deployment.Template.Placement,
+		Owner:     &entity.OwnerReference{Kind: entity.OwnerKindDeployment, ID: deployment.ID},
+	}
+}
+
+func compareReadiness(a, b *entity.Container) int {
+	aReady, bReady := a.IsReady(), b.IsReady()
+	switch {
+	case aReady == bReady:
+		return strings.Compare(a.ID.String(), b.ID.String())
+	case bReady:
+		return -1
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,91 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/pkg/entity"
+)
+
+type IService interface {
+	GetDeployment(ctx context.Context, id uuid.UUID) (*entity.Deployment, error)
+	ListDeployments(ctx context.Context, opts entity.ListOptions) ([]entity.Deployment, string, error)
+	AddDeployment(ctx context.Context, req *entity.AddDeployment) (*entity.Deployment, error)
+	UpdateDeployment(ctx context.Context, deployment *entity.Deployment) (*entity.Deployment, error)
+	DeleteDeployment(ctx context.Context, id uuid.UUID) error
+}
+
+type Service struct {
+	repo             usecase.DeploymentRepository
+	containerService container.IService
+}
+
+func NewService(repo usecase.DeploymentRepository, containerService container.IService) *Service {
+	return &Service{
+		repo:             repo,
+		containerService: containerService,
+	}
+}
+
+func (s *Service) GetDeployment(ctx context.Context, id uuid.UUID) (*entity.Deployment, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// ListDeployments returns one page of deployments together with the continue
+// token for the next page, which is empty on the last page.
+func (s *Service) ListDeployments(ctx context.Context, opts entity.ListOptions) ([]entity.Deployment, string, error) {
+	return s.repo.List(ctx, opts)
+}
+
+// AddDeployment stores a new deployment, its containers are created by the Controller.
+func (s *Service) AddDeployment(ctx context.Context, req *entity.AddDeployment) (*entity.Deployment, error) {
+	deployment := entity.NewDeployment(req.Name, req.Replicas, req.Template)
+	if err := deployment.Validate(); err != nil {
+		return nil, err
+	}
+	if err := container.ValidateTemplate(&deployment.Template); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, deployment); err != nil {
+		return nil, err
+	}
+	return deployment, nil
+}
+
+// UpdateDeployment saves the replica count and template of the deployment.
+// Template changes apply to containers created afterwards, the ones already
+// running keep their image until they are replaced or rolled out.
+func (s *Service) UpdateDeployment(ctx context.Context, deployment *entity.Deployment) (*entity.Deployment, error) {
+	if deployment.Replicas < 0 {
+		return nil, fmt.Errorf("%w: replicas must not be negative", entity.InvalidDeploymentErr)
+	}
+	if err := container.ValidateTemplate(&deployment.Template); err != nil {
+		return nil, err
+	}
+	return s.repo.Update(ctx, deployment.ID, func(current *entity.Deployment) error {
+		current.Replicas = deployment.Replicas
+		current.Template = deployment.Template
+		return nil
+	})
+}
+
+// DeleteDeployment removes the deployment together with its containers.
+func (s *Service) DeleteDeployment(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	containers, _, err := s.containerService.ListContainers(ctx, entity.ListContainersOptions{OwnerID: id})
+	if err != nil {
+		return err
+	}
+	for _, owned := range containers {
+		err = s.containerService.RemoveContainer(ctx, owned.ID)
+		if err != nil && !errors.Is(err, usecase.ContainerNotFoundErr) {
+			return err
+		}
+	}
+	return nil
+}
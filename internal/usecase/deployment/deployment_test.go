@@ -0,0 +1,90 @@
+package deployment_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/infrastructure/servicetest"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/deployment"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+	"time"
+)
+
+func newServices(t *testing.T) (servicetest.Services, *deployment.Service) {
+	services := servicetest.New()
+	services.RunningNode(t, entity.Resources{CPU: 1000})
+	return services, deployment.NewService(memory.NewDeploymentRepository(services.Store), services.Containers)
+}
+
+func TestControllerConvergesOnReplicas(t *testing.T) {
+	ctx := context.Background()
+	services, deploymentService := newServices(t)
+	controller := deployment.NewController(deploymentService, time.Second)
+
+	web, err := deploymentService.AddDeployment(ctx, &entity.AddDeployment{
+		Name:     "web",
+		Replicas: 3,
+		Template: entity.ContainerTemplate{Image: "nginx", Resources: entity.Resources{CPU: 200}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, controller.Reconcile(ctx))
+	containers := services.Owned(t, web.ID)
+	require.Len(t, containers, 3)
+	for _, owned := range containers {
+		assert.Equal(t, "nginx", owned.Image)
+		assert.Equal(t, &entity.OwnerReference{Kind: entity.OwnerKindDeployment, ID: web.ID}, owned.Owner)
+	}
+
+	failed := containers[0]
+	failed.Status = entity.ContainerStatusFailed
+	require.NoError(t, services.Containers.UpdateContainer(ctx, &failed))
+	require.NoError(t, controller.Reconcile(ctx))
+	containers = services.Owned(t, web.ID)
+	require.Len(t, containers, 3)
+	_, err = services.Containers.GetContainer(ctx, failed.ID)
+	assert.ErrorIs(t, err, usecase.ContainerNotFoundErr, "failed containers are replaced")
+
+	web.Replicas = 6
+	_, err = deploymentService.UpdateDeployment(ctx, web)
+	require.NoError(t, err)
+	require.NoError(t, controller.Reconcile(ctx))
+	assert.Len(t, services.Owned(t, web.ID), 5, "the node only fits five containers")
+	stored, err := deploymentService.GetDeployment(ctx, web.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, stored.Status.Replicas)
+	assert.Contains(t, stored.Status.Message, usecase.NoEligibleNodeErr.Error())
+
+	web.Replicas = 1
+	_, err = deploymentService.UpdateDeployment(ctx, web)
+	require.NoError(t, err)
+	require.NoError(t, controller.Reconcile(ctx))
+	assert.Len(t, services.Owned(t, web.ID), 1)
+	stored, err = deploymentService.GetDeployment(ctx, web.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.DeploymentStatus{Replicas: 1}, stored.Status)
+
+	require.NoError(t, deploymentService.DeleteDeployment(ctx, web.ID))
+	assert.Empty(t, services.Owned(t, web.ID))
+	assert.ErrorIs(t, deploymentService.DeleteDeployment(ctx, web.ID), usecase.DeploymentNotFoundErr)
+}
+
+func TestAddDeploymentValidates(t *testing.T) {
+	ctx := context.Background()
+	_, deploymentService := newServices(t)
+
+	_, err := deploymentService.AddDeployment(ctx, &entity.AddDeployment{Name: "Web", Template: entity.ContainerTemplate{Image: "nginx"}})
+	assert.ErrorIs(t, err, entity.InvalidDeploymentErr)
+	_, err = deploymentService.AddDeployment(ctx, &entity.AddDeployment{Name: "web", Replicas: -1, Template: entity.ContainerTemplate{Image: "nginx"}})
+	assert.ErrorIs(t, err, entity.InvalidDeploymentErr)
+	_, err = deploymentService.AddDeployment(ctx, &entity.AddDeployment{Name: "web"})
+	assert.ErrorIs(t, err, usecase.InvalidContainerSpecErr)
+
+	_, err = deploymentService.AddDeployment(ctx, &entity.AddDeployment{Name: "web", Template: entity.ContainerTemplate{Image: "nginx"}})
+	require.NoError(t, err)
+	_, err = deploymentService.AddDeployment(ctx, &entity.AddDeployment{Name: "web", Template: entity.ContainerTemplate{Image: "nginx"}})
+	assert.ErrorIs(t, err, usecase.DeploymentExistsErr)
+}
@@ -1,7 +1,115 @@
 package usecase
 
-import "errors"
+import (
+	"errors"
+	"github.com/wensiet/morchy-api/pkg/entity"
+)
+
+// Kind classifies errors by how a client can react to them.
+type Kind int
+
+const (
+	// InternalKind is the kind of every error outside of the catalogue.
+	InternalKind Kind = iota
+	// NotFoundKind errors refer to a resource that does not exist.
+	NotFoundKind
+	// ConflictKind errors reject a change that conflicts with the current state.
+	ConflictKind
+	// ValidationKind errors reject input that is invalid on its own.
+	ValidationKind
+	// PreconditionFailedKind errors report a condition of the request that does not hold.
+	PreconditionFailedKind
+	// PreconditionRequiredKind errors reject a change that does not say which version it is based on.
+	PreconditionRequiredKind
+	// GoneKind errors refer to history that is no longer kept.
+	GoneKind
+	// UnavailableKind errors are temporary, the request may succeed when retried later.
+	UnavailableKind
+)
+
+// Error is an error of the catalogue. Errors are compared by identity with
+// errors.Is and are usually wrapped with details by fmt.Errorf and %w.
+type Error struct {
+	Kind    Kind
+	message string
+}
+
+func NewError(kind Kind, message string) *Error {
+	return &Error{Kind: kind, message: message}
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
 
 var (
-	NodeNotFoundErr = errors.New("node not found")
+	NodeNotFoundErr            = NewError(NotFoundKind, "node not found")
+	ContainerNotFoundErr       = NewError(NotFoundKind, "container not found")
+	NodeHasContainersErr       = NewError(ConflictKind, "node still has containers assigned")
+	DrainIncompleteErr         = NewError(ConflictKind, "some containers could not be moved off the node")
+	NoEligibleNodeErr          = NewError(UnavailableKind, "no eligible node to schedule container")
+	UnknownSchedulerErr        = NewError(InternalKind, "unknown scheduler strategy")
+	InsufficientCapacityErr    = NewError(ConflictKind, "node has insufficient capacity for container")
+	InvalidPlacementErr        = NewError(ValidationKind, "invalid container placement")
+	PlacementConstraintErr     = NewError(ConflictKind, "node does not satisfy container placement")
+	InvalidContainerSpecErr    = NewError(ValidationKind, "invalid container spec")
+	InvalidStatusTransitionErr = NewError(ConflictKind, "invalid container status transition")
+	ResourceVersionTooOldErr   = NewError(GoneKind, "resource version is too old")
+	ResourceVersionConflictErr = NewError(ConflictKind, "resource was modified since the given resource version")
+	ResourceVersionRequiredErr = NewError(PreconditionRequiredKind, "a resource_version is required")
+	InvalidPatchErr            = NewError(ValidationKind, "invalid patch")
+	PatchTestFailedErr         = NewError(PreconditionFailedKind, "patch test operation failed")
+	InvalidListOptionsErr      = NewError(ValidationKind, "invalid list options")
+	InvalidContinueTokenErr    = NewError(ValidationKind, "invalid continue token")
+	DeploymentNotFoundErr      = NewError(NotFoundKind, "deployment not found")
+	DeploymentExistsErr        = NewError(ConflictKind, "deployment with this name already exists")
+	RolloutNotFoundErr         = NewError(NotFoundKind, "rollout not found")
+	RolloutConflictErr         = NewError(ConflictKind, "containers are already part of an active rollout")
+	InvalidRolloutActionErr    = NewError(ConflictKind, "action is not allowed in the current rollout phase")
+	JobNotFoundErr             = NewError(NotFoundKind, "job not found")
+	JobExistsErr               = NewError(ConflictKind, "job with this name already exists")
+	CronJobNotFoundErr         = NewError(NotFoundKind, "cron job not found")
+	CronJobExistsErr           = NewError(ConflictKind, "cron job with this name already exists")
+	IdempotencyKeyNotFoundErr  = NewError(NotFoundKind, "idempotency key not found")
+	IdempotencyKeyExistsErr    = NewError(ConflictKind, "idempotency key is already used")
+	IdempotencyKeyInUseErr     = NewError(ConflictKind, "a request with this idempotency key is still being processed")
+	IdempotencyKeyReusedErr    = NewError(ValidationKind, "idempotency key was already used for a different request")
+	BatchAbortedErr            = NewError(ConflictKind, "not applied because another item of the atomic batch failed")
 )
+
+// entityValidationErrs are the validation errors of the entities, which know
+// nothing about the catalogue.
+var entityValidationErrs = []error{
+	entity.InvalidContainerStatusErr,
+	entity.InvalidContainerStateErr,
+	entity.InvalidRestartPolicyErr,
+	entity.InvalidCronScheduleErr,
+	entity.InvalidDeploymentErr,
+	entity.InvalidJobErr,
+	entity.InvalidCronJobErr,
+	entity.InvalidLabelsErr,
+	entity.InvalidLabelSelectorErr,
+	entity.InvalidLogEntryErr,
+	entity.InvalidNodeStatusErr,
+	entity.InvalidResourcesErr,
+	entity.InvalidRolloutErr,
+	entity.InvalidTaintErr,
+	entity.InvalidTolerationErr,
+	entity.InvalidBatchErr,
+}
+
+// KindOf returns the kind of the outermost catalogue error wrapped by err,
+// entity validation errors are of ValidationKind and anything else is of
+// InternalKind.
+func KindOf(err error) Kind {
+	var catalogued *Error
+	if errors.As(err, &catalogued) {
+		return catalogued.Kind
+	}
+	for _, validationErr := range entityValidationErrs {
+		if errors.Is(err, validationErr) {
+			return ValidationKind
+		}
+	}
+	return InternalKind
+}
@@ -0,0 +1,74 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"time"
+)
+
+type IService interface {
+	Begin(ctx context.Context, key, requestHash string) (*entity.IdempotentResponse, error)
+	Complete(ctx context.Context, key string, response *entity.IdempotentResponse) error
+	Abandon(ctx context.Context, key string) error
+}
+
+type Service struct {
+	repo        usecase.IdempotencyRepository
+	lockTimeout time.Duration
+}
+
+// NewService returns a service that lets a retry take over a request still
+// in progress after lockTimeout, whose API instance presumably stopped.
+func NewService(repo usecase.IdempotencyRepository, lockTimeout time.Duration) *Service {
+	return &Service{repo: repo, lockTimeout: lockTimeout}
+}
+
+// Begin records that the request identified by requestHash is processed
+// under key and returns nil, or returns the stored response when the request
+// was answered before. It fails with IdempotencyKeyReusedErr when the key
+// was used for another request and with IdempotencyKeyInUseErr while the
+// request is processed.
+func (s *Service) Begin(ctx context.Context, key, requestHash string) (*entity.IdempotentResponse, error) {
+	// A record removed between Create and Get is created again once.
+	for range 2 {
+		now := time.Now().UTC()
+		err := s.repo.Create(ctx, &entity.IdempotencyRecord{Key: key, RequestHash: requestHash, CreatedAt: now})
+		if !errors.Is(err, usecase.IdempotencyKeyExistsErr) {
+			return nil, err
+		}
+
+		record, err := s.repo.Get(ctx, key)
+		if errors.Is(err, usecase.IdempotencyKeyNotFoundErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if record.RequestHash != requestHash {
+			return nil, fmt.Errorf("%w: %s", usecase.IdempotencyKeyReusedErr, key)
+		}
+		if !record.InProgress() {
+			return record.Response, nil
+		}
+		if now.Sub(record.CreatedAt) < s.lockTimeout {
+			break
+		}
+		if err = s.repo.Delete(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", usecase.IdempotencyKeyInUseErr, key)
+}
+
+// Complete stores the response of the request begun under key.
+func (s *Service) Complete(ctx context.Context, key string, response *entity.IdempotentResponse) error {
+	return s.repo.Complete(ctx, key, response)
+}
+
+// Abandon forgets the request begun under key, so that a retry processes it again.
+func (s *Service) Abandon(ctx context.Context, key string) error {
+	return s.repo.Delete(ctx, key)
+}
@@ -0,0 +1,83 @@
+package idempotency_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/idempotency"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+	"time"
+)
+
+func TestBeginReplaysCompletedRequests(t *testing.T) {
+	ctx := context.Background()
+	service := idempotency.NewService(memory.NewIdempotencyRepository(memory.NewStore()), time.Minute)
+
+	stored, err := service.Begin(ctx, "key", "request")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	_, err = service.Begin(ctx, "key", "request")
+	assert.ErrorIs(t, err, usecase.IdempotencyKeyInUseErr)
+	_, err = service.Begin(ctx, "key", "other request")
+	assert.ErrorIs(t, err, usecase.IdempotencyKeyReusedErr)
+
+	response := &entity.IdempotentResponse{Status: 200, Body: []byte(`{}`)}
+	require.NoError(t, service.Complete(ctx, "key", response))
+	stored, err = service.Begin(ctx, "key", "request")
+	require.NoError(t, err)
+	assert.Equal(t, response, stored)
+	_, err = service.Begin(ctx, "key", "other request")
+	assert.ErrorIs(t, err, usecase.IdempotencyKeyReusedErr)
+}
+
+func TestBeginAfterAbandon(t *testing.T) {
+	ctx := context.Background()
+	service := idempotency.NewService(memory.NewIdempotencyRepository(memory.NewStore()), time.Minute)
+
+	_, err := service.Begin(ctx, "key", "request")
+	require.NoError(t, err)
+	require.NoError(t, service.Abandon(ctx, "key"))
+
+	stored, err := service.Begin(ctx, "key", "other request")
+	require.NoError(t, err)
+	assert.Nil(t, stored, "abandoned keys are free again")
+}
+
+func TestBeginTakesOverStaleRequests(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewIdempotencyRepository(memory.NewStore())
+	service := idempotency.NewService(repo, time.Minute)
+
+	stale := &entity.IdempotencyRecord{Key: "key", RequestHash: "request", CreatedAt: time.Now().UTC().Add(-2 * time.Minute)}
+	require.NoError(t, repo.Create(ctx, stale))
+
+	stored, err := service.Begin(ctx, "key", "request")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+	_, err = service.Begin(ctx, "key", "request")
+	assert.ErrorIs(t, err, usecase.IdempotencyKeyInUseErr)
+}
+
+func TestPrunerRemovesExpiredKeys(t *testing.T) {
+	ctx := context.Background()
+	service := idempotency.NewService(memory.NewIdempotencyRepository(memory.NewStore()), time.Minute)
+	pruner := idempotency.NewPruner(service, time.Minute, time.Hour)
+
+	_, err := service.Begin(ctx, "key", "request")
+	require.NoError(t, err)
+	require.NoError(t, service.Complete(ctx, "key", &entity.IdempotentResponse{Status: 200}))
+
+	require.NoError(t, pruner.Reconcile(ctx, time.Now().UTC()))
+	stored, err := service.Begin(ctx, "key", "other request")
+	require.ErrorIs(t, err, usecase.IdempotencyKeyReusedErr, "the key is kept during the retention period")
+	assert.Nil(t, stored)
+
+	require.NoError(t, pruner.Reconcile(ctx, time.Now().UTC().Add(2*time.Hour)))
+	stored, err = service.Begin(ctx, "key", "other request")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
@@ -0,0 +1,52 @@
+package idempotency
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Pruner removes the requests recorded before the retention period, retries
+// sent later are processed as new requests.
+type Pruner struct {
+	service   *Service
+	interval  time.Duration
+	retention time.Duration
+}
+
+func NewPruner(service *Service, interval, retention time.Duration) *Pruner {
+	return &Pruner{
+		service:   service,
+		interval:  interval,
+		retention: retention,
+	}
+}
+
+// Run blocks until ctx is cancelled, pruning every interval.
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := p.Reconcile(ctx, now.UTC()); err != nil {
+				log.Printf("idempotency pruner: %v", err)
+			}
+		}
+	}
+}
+
+// Reconcile removes the requests recorded before the retention period at now.
+func (p *Pruner) Reconcile(ctx context.Context, now time.Time) error {
+	removed, err := p.service.repo.Prune(ctx, now.Add(-p.retention))
+	if err != nil {
+		return err
+	}
+	if removed > 0 {
+		log.Printf("idempotency pruner: removed %d idempotency keys", removed)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"log"
+	"slices"
+	"time"
+)
+
+const (
+	// BackoffBase is the delay before the first failed container of a job is
+	// replaced, it doubles with every further failure.
+	BackoffBase = 10 * time.Second
+	// BackoffMax caps the delay between retries.
+	BackoffMax = 6 * time.Minute
+)
+
+// Controller runs every active job to completion. It records the containers
+// of the job that finished in its status and removes them from their nodes,
+// creates new containers until enough of them succeeded, and fails the job
+// once more of them failed than its backoff limit allows.
+type Controller struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewController(service *Service, interval time.Duration) *Controller {
+	return &Controller{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run blocks until ctx is cancelled, reconciling every interval.
+func (c *Controller) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := c.Reconcile(ctx, now.UTC()); err != nil {
+				log.Printf("job controller: %v", err)
+			}
+		}
+	}
+}
+
+// Reconcile advances every active job once and removes containers whose job
+// no longer exists.
+func (c *Controller) Reconcile(ctx context.Context, now time.Time) error {
+	jobs, _, err := c.service.repo.List(ctx, entity.ListJobsOptions{})
+	if err != nil {
+		return fmt.Errorf("listing jobs: %w", err)
+	}
+	for i := range jobs {
+		if jobs[i].IsFinished() {
+			continue
+		}
+		if err = c.reconcile(ctx, &jobs[i], now); err != nil {
+			log.Printf("job controller: job %s: %v", jobs[i].Name, err)
+		}
+	}
+	return c.collectOrphans(ctx)
+}
+
+func (c *Controller) reconcile(ctx context.Context, job *entity.Job, now time.Time) error {
+	containers, _, err := c.service.containerService.ListContainers(ctx, entity.ListContainersOptions{OwnerID: job.ID})
+	if err != nil {
+		return err
+	}
+
+	var running, finished []entity.Container
+	var runs []entity.JobRun
+	for _, owned := range containers {
+		switch {
+		case owned.Status == entity.ContainerStatusTerminating:
+		case owned.IsTerminated():
+			finished = append(finished, owned)
+			recorded := slices.ContainsFunc(job.Status.Runs, func(run entity.JobRun) bool {
+				return run.ContainerID == owned.ID
+			})
+			if !recorded {
+				runs = append(runs, newRun(&owned, now))
+			}
+		default:
+			running = append(running, owned)
+		}
+	}
+
+	status := job.Status
+	status.Runs = append(slices.Clone(job.Status.Runs), runs...)
+	status.Succeeded, status.Failed = countRuns(status.Runs)
+	status.Message = ""
+	switch {
+	case status.Succeeded >= job.Spec.Completions:
+		status.Phase = entity.JobPhaseSucceeded
+	case status.Failed > job.Spec.BackoffLimit:
+		status.Phase = entity.JobPhaseFailed
+		status.Message = fmt.Sprintf("%d container(s) failed, the backoff limit is %d", status.Failed, job.Spec.BackoffLimit)
+	}
+
+	status.Active = len(running)
+	if status.Phase == entity.JobPhaseActive {
+		wanted := min(job.Spec.Parallelism, job.Spec.Completions-status.Succeeded) - len(running)
+		if retryAt := retryTime(status.Runs, status.Failed); wanted > 0 && now.Before(retryAt) {
+			status.Message = fmt.Sprintf("backing off until %s after %d failed container(s)", retryAt.Format(time.RFC3339), status.Failed)
+			wanted = 0
+		}
+		for range wanted {
+			if _, err = c.service.containerService.AddContainer(ctx, newContainer(job)); err != nil {
+				status.Message = fmt.Sprintf("creating a container: %s", err)
+				break
+			}
+			status.Active++
+		}
+	} else {
+		if status.CompletedAt == nil {
+			status.CompletedAt = &now
+		}
+		// Containers still running are not needed anymore.
+		finished = append(finished, running...)
+		status.Active = 0
+	}
+
+	if len(runs) > 0 || status.Phase != job.Status.Phase || status.Active != job.Status.Active ||
+		status.Message != job.Status.Message {
+		_, err = c.service.repo.Update(ctx, job.ID, func(current *entity.Job) error {
+			current.Status = status
+			return nil
+		})
+		if errors.Is(err, usecase.JobNotFoundErr) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Finished containers are removed only once their runs are recorded.
+	for _, done := range finished {
+		if err = c.remove(ctx, done.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectOrphans removes containers owned by jobs that do not exist anymore.
+func (c *Controller) collectOrphans(ctx context.Context) error {
+	return container.RemoveOrphans(ctx, c.service.containerService, entity.OwnerKindJob, func(id uuid.UUID) bool {
+		_, err := c.service.repo.Get(ctx, id)
+		return !errors.Is(err, usecase.JobNotFoundErr)
+	})
+}
+
+func (c *Controller) remove(ctx context.Context, id uuid.UUID) error {
+	err := c.service.containerService.RemoveContainer(ctx, id)
+	if errors.Is(err, usecase.ContainerNotFoundErr) {
+		return nil
+	}
+	return err
+}
+
+func newContainer(job *entity.Job) *entity.AddContainer {
+	return &entity.AddContainer{
+		Image:     job.Spec.Template.Image,
+		Resources: job.Spec.Template.Resources,
+		Spec:      job.Spec.Template.Spec,
+		Labels:    job.Spec.Template.Labels,
+		Placement: job.Spec.Template.Placement,
+		Owner:     &entity.OwnerReference{Kind: entity.OwnerKindJob, ID: job.ID},
+	}
+}
+
+// newRun records a finished container together with the state reported by
+// its node agent. Exited containers succeed unless they report a non-zero
+// exit code.
+func newRun(container *entity.Container, now time.Time) entity.JobRun {
+	exitCode := container.State.ExitCode
+	return entity.JobRun{
+		ContainerID: container.ID,
+		NodeID:      container.NodeID,
+		ExitCode:    exitCode,
+		Succeeded:   container.Status == entity.ContainerStatusExited && (exitCode == nil || *exitCode == 0),
+		Reason:      container.Status,
+		Message:     container.State.Reason,
+		FinishedAt:  now,
+	}
+}
+
+func countRuns(runs []entity.JobRun) (succeeded, failed int) {
+	for _, run := range runs {
+		if run.Succeeded {
+			succeeded++
+		} else {
+			failed++
+		}
+	}
+	return succeeded, failed
+}
+
+// retryTime returns when a new container may be started after failed
+// failures, the zero time when there were none.
+func retryTime(runs []entity.JobRun, failed int) time.Time {
+	var lastFailure time.Time
+	for _, run := range runs {
+		if !run.Succeeded && run.FinishedAt.After(lastFailure) {
+			lastFailure = run.FinishedAt
+		}
+	}
+	if failed == 0 {
+		return lastFailure
+	}
+	delay := BackoffBase
+	for i := 1; i < failed && delay < BackoffMax; i++ {
+		delay *= 2
+	}
+	return lastFailure.Add(min(delay, BackoffMax))
+}
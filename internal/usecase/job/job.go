@@ -0,0 +1,90 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/pkg/entity"
+)
+
+type IService interface {
+	GetJob(ctx context.Context, id uuid.UUID) (*entity.Job, error)
+	ListJobs(ctx context.Context, opts entity.ListJobsOptions) ([]entity.Job, string, error)
+	AddJob(ctx context.Context, req *entity.AddJob) (*entity.Job, error)
+	DeleteJob(ctx context.Context, id uuid.UUID) error
+}
+
+type Service struct {
+	repo             usecase.JobRepository
+	containerService container.IService
+}
+
+func NewService(repo usecase.JobRepository, containerService container.IService) *Service {
+	return &Service{
+		repo:             repo,
+		containerService: containerService,
+	}
+}
+
+func (s *Service) GetJob(ctx context.Context, id uuid.UUID) (*entity.Job, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// ListJobs returns one page of jobs matching opts together with the continue
+// token for the next page, which is empty on the last page.
+func (s *Service) ListJobs(ctx context.Context, opts entity.ListJobsOptions) ([]entity.Job, string, error) {
+	return s.repo.List(ctx, opts)
+}
+
+// AddJob stores a new job, its containers are created by the Controller.
+func (s *Service) AddJob(ctx context.Context, req *entity.AddJob) (*entity.Job, error) {
+	job := entity.NewJob(req.Name, req.Spec)
+	job.Owner = req.Owner
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ValidateSpec(&job.Spec); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// DeleteJob removes the job together with its containers.
+func (s *Service) DeleteJob(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	containers, _, err := s.containerService.ListContainers(ctx, entity.ListContainersOptions{OwnerID: id})
+	if err != nil {
+		return err
+	}
+	for _, owned := range containers {
+		err = s.containerService.RemoveContainer(ctx, owned.ID)
+		if err != nil && !errors.Is(err, usecase.ContainerNotFoundErr) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateSpec checks the job spec and its container template. Containers
+// of jobs are never restarted in place, failed ones are replaced by the
+// Controller, so the restart policy defaults to never and cannot be changed.
+func ValidateSpec(spec *entity.JobSpec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	if spec.Template.Spec.RestartPolicy == "" {
+		spec.Template.Spec.RestartPolicy = entity.RestartPolicyNever
+	}
+	if spec.Template.Spec.RestartPolicy != entity.RestartPolicyNever {
+		return fmt.Errorf("%w: the restart policy of job containers must be %s", entity.InvalidJobErr, entity.RestartPolicyNever)
+	}
+	return container.ValidateTemplate(&spec.Template)
+}
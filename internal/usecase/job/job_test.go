@@ -0,0 +1,157 @@
+package job_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/infrastructure/servicetest"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/internal/usecase/job"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+	"time"
+)
+
+func newServices(t *testing.T) (servicetest.Services, *job.Service) {
+	services := servicetest.New()
+	services.RunningNode(t, entity.Resources{CPU: 1000})
+	return services, job.NewService(memory.NewJobRepository(services.Store), services.Containers)
+}
+
+// finish runs the container and lets it exit with exitCode.
+func finish(t *testing.T, containerService *container.Service, owned entity.Container, exitCode int) {
+	ctx := context.Background()
+	for _, status := range []entity.ContainerStatus{entity.ContainerStatusCreating, entity.ContainerStatusRunning} {
+		owned.Status = status
+		require.NoError(t, containerService.UpdateContainer(ctx, &owned))
+	}
+	_, err := containerService.UpdateStatus(ctx, owned.ID, &entity.UpdateContainerStatus{
+		Status: entity.ContainerStatusExited,
+		State:  entity.ContainerState{ExitCode: &exitCode},
+	})
+	require.NoError(t, err)
+}
+
+func TestControllerRunsJobToCompletion(t *testing.T) {
+	ctx := context.Background()
+	services, jobService := newServices(t)
+	controller := job.NewController(jobService, time.Second)
+	now := time.Now().UTC()
+
+	migrate, err := jobService.AddJob(ctx, &entity.AddJob{
+		Name: "migrate",
+		Spec: entity.JobSpec{
+			Template:    entity.ContainerTemplate{Image: "migrate", Resources: entity.Resources{CPU: 100}},
+			Completions: 3,
+			Parallelism: 2,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.RestartPolicyNever, migrate.Spec.Template.Spec.RestartPolicy)
+
+	require.NoError(t, controller.Reconcile(ctx, now))
+	containers := services.Owned(t, migrate.ID)
+	require.Len(t, containers, 2)
+	assert.Equal(t, &entity.OwnerReference{Kind: entity.OwnerKindJob, ID: migrate.ID}, containers[0].Owner)
+
+	for _, owned := range containers {
+		finish(t, services.Containers, owned, 0)
+	}
+	require.NoError(t, controller.Reconcile(ctx, now))
+	containers = services.Owned(t, migrate.ID)
+	require.Len(t, containers, 1, "only one completion is left")
+
+	finish(t, services.Containers, containers[0], 0)
+	require.NoError(t, controller.Reconcile(ctx, now))
+	assert.Empty(t, services.Owned(t, migrate.ID), "finished containers are removed")
+
+	done, err := jobService.GetJob(ctx, migrate.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.JobPhaseSucceeded, done.Status.Phase)
+	assert.Equal(t, 3, done.Status.Succeeded)
+	assert.Zero(t, done.Status.Active)
+	assert.NotNil(t, done.Status.CompletedAt)
+	require.Len(t, done.Status.Runs, 3)
+	assert.Equal(t, 0, *done.Status.Runs[0].ExitCode)
+}
+
+func TestControllerRetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	services, jobService := newServices(t)
+	controller := job.NewController(jobService, time.Second)
+	now := time.Now().UTC()
+
+	flaky, err := jobService.AddJob(ctx, &entity.AddJob{
+		Name: "flaky",
+		Spec: entity.JobSpec{
+			Template:     entity.ContainerTemplate{Image: "flaky"},
+			BackoffLimit: 1,
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, controller.Reconcile(ctx, now))
+	containers := services.Owned(t, flaky.ID)
+	require.Len(t, containers, 1)
+	finish(t, services.Containers, containers[0], 1)
+
+	require.NoError(t, controller.Reconcile(ctx, now))
+	assert.Empty(t, services.Owned(t, flaky.ID), "no retry during the backoff")
+	backingOff, err := jobService.GetJob(ctx, flaky.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.JobPhaseActive, backingOff.Status.Phase)
+	assert.Equal(t, 1, backingOff.Status.Failed)
+	assert.Equal(t, 1, *backingOff.Status.Runs[0].ExitCode)
+	assert.Contains(t, backingOff.Status.Message, "backing off")
+
+	now = now.Add(job.BackoffBase)
+	require.NoError(t, controller.Reconcile(ctx, now))
+	containers = services.Owned(t, flaky.ID)
+	require.Len(t, containers, 1)
+	finish(t, services.Containers, containers[0], 2)
+
+	require.NoError(t, controller.Reconcile(ctx, now))
+	assert.Empty(t, services.Owned(t, flaky.ID))
+	failed, err := jobService.GetJob(ctx, flaky.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.JobPhaseFailed, failed.Status.Phase)
+	assert.Equal(t, 2, failed.Status.Failed)
+	assert.Contains(t, failed.Status.Message, "backoff limit")
+
+	require.NoError(t, jobService.DeleteJob(ctx, flaky.ID))
+	_, err = jobService.GetJob(ctx, flaky.ID)
+	assert.ErrorIs(t, err, usecase.JobNotFoundErr)
+}
+
+func TestAddJobValidates(t *testing.T) {
+	ctx := context.Background()
+	_, jobService := newServices(t)
+
+	_, err := jobService.AddJob(ctx, &entity.AddJob{
+		Name: "restart",
+		Spec: entity.JobSpec{Template: entity.ContainerTemplate{
+			Image: "migrate",
+			Spec:  entity.ContainerSpec{RestartPolicy: entity.RestartPolicyAlways},
+		}},
+	})
+	assert.ErrorIs(t, err, entity.InvalidJobErr)
+
+	_, err = jobService.AddJob(ctx, &entity.AddJob{
+		Name: "negative",
+		Spec: entity.JobSpec{Template: entity.ContainerTemplate{Image: "migrate"}, BackoffLimit: -1},
+	})
+	assert.ErrorIs(t, err, entity.InvalidJobErr)
+
+	_, err = jobService.AddJob(ctx, &entity.AddJob{
+		Name: "migrate",
+		Spec: entity.JobSpec{Template: entity.ContainerTemplate{Image: "migrate"}},
+	})
+	require.NoError(t, err)
+	_, err = jobService.AddJob(ctx, &entity.AddJob{
+		Name: "migrate",
+		Spec: entity.JobSpec{Template: entity.ContainerTemplate{Image: "migrate"}},
+	})
+	assert.ErrorIs(t, err, usecase.JobExistsErr)
+}
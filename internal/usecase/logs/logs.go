@@ -0,0 +1,115 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"time"
+)
+
+type IService interface {
+	AppendLogs(ctx context.Context, id uuid.UUID, entries []entity.LogEntry) error
+	GetLogs(ctx context.Context, id uuid.UUID, opts entity.LogOptions) ([]entity.LogEntry, error)
+	FollowLogs(ctx context.Context, id uuid.UUID, opts entity.LogOptions, send func(entries []entity.LogEntry) error) error
+}
+
+type Service struct {
+	repo             usecase.ContainerLogRepository
+	containerService container.IService
+	followInterval   time.Duration
+}
+
+// NewService returns a service polling for new entries every followInterval
+// while logs are followed. Polling the repository instead of notifying
+// followers in process lets followers see entries appended through other API
+// instances.
+func NewService(
+	repo usecase.ContainerLogRepository,
+	containerService container.IService,
+	followInterval time.Duration,
+) *Service {
+	return &Service{
+		repo:             repo,
+		containerService: containerService,
+		followInterval:   followInterval,
+	}
+}
+
+// AppendLogs stores entries sent by the node agent of the container.
+func (s *Service) AppendLogs(ctx context.Context, id uuid.UUID, entries []entity.LogEntry) error {
+	now := time.Now().UTC()
+	for i := range entries {
+		if err := entries[i].Validate(now); err != nil {
+			return err
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return s.repo.Append(ctx, id, entries)
+}
+
+func (s *Service) GetLogs(ctx context.Context, id uuid.UUID, opts entity.LogOptions) ([]entity.LogEntry, error) {
+	if opts.Tail < 0 {
+		return nil, fmt.Errorf("%w: tail must not be negative", usecase.InvalidListOptionsErr)
+	}
+	return s.repo.List(ctx, id, opts)
+}
+
+// FollowLogs sends the entries selected by opts and then every new entry
+// until ctx is done, the container is removed, or it has terminated and no
+// entries are left. Errors returned by send stop following and are returned.
+func (s *Service) FollowLogs(
+	ctx context.Context,
+	id uuid.UUID,
+	opts entity.LogOptions,
+	send func(entries []entity.LogEntry) error,
+) error {
+	entries, err := s.GetLogs(ctx, id, opts)
+	if err != nil {
+		return err
+	}
+	// Only the first batch is limited to the tail.
+	opts.Tail = 0
+
+	ticker := time.NewTicker(s.followInterval)
+	defer ticker.Stop()
+
+	for {
+		if len(entries) > 0 {
+			if err = send(entries); err != nil {
+				return err
+			}
+			opts.AfterSeq = entries[len(entries)-1].Seq
+		} else {
+			current, err := s.containerService.GetContainer(ctx, id)
+			if errors.Is(err, usecase.ContainerNotFoundErr) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if current.IsTerminated() {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		entries, err = s.repo.List(ctx, id, opts)
+		if errors.Is(err, usecase.ContainerNotFoundErr) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package logs_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/infrastructure/servicetest"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/internal/usecase/logs"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newServices(t *testing.T) (*container.Service, *logs.Service, *entity.Container) {
+	services := servicetest.New()
+	target := services.RunningNode(t, entity.Resources{})
+	created, err := services.Containers.AddContainer(
+		context.Background(),
+		&entity.AddContainer{NodeID: target.ID, Image: "nginx"},
+	)
+	require.NoError(t, err)
+
+	logService := logs.NewService(memory.NewContainerLogRepository(services.Store), services.Containers, 10*time.Millisecond)
+	return services.Containers, logService, created
+}
+
+func TestAppendLogsValidates(t *testing.T) {
+	ctx := context.Background()
+	_, logService, created := newServices(t)
+
+	err := logService.AppendLogs(ctx, created.ID, []entity.LogEntry{{Stream: "stdin", Line: "hello"}})
+	assert.ErrorIs(t, err, entity.InvalidLogEntryErr)
+	err = logService.AppendLogs(ctx, created.ID, []entity.LogEntry{{Line: strings.Repeat("x", entity.MaxLogLineLength+1)}})
+	assert.ErrorIs(t, err, entity.InvalidLogEntryErr)
+
+	require.NoError(t, logService.AppendLogs(ctx, created.ID, []entity.LogEntry{{Line: "hello"}}))
+	entries, err := logService.GetLogs(ctx, created.ID, entity.LogOptions{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, entity.LogStreamStdout, entries[0].Stream)
+	assert.False(t, entries[0].Timestamp.IsZero())
+
+	_, err = logService.GetLogs(ctx, created.ID, entity.LogOptions{Tail: -1})
+	assert.ErrorIs(t, err, usecase.InvalidListOptionsErr)
+}
+
+func TestFollowLogsUntilContainerExits(t *testing.T) {
+	ctx := context.Background()
+	containerService, logService, created := newServices(t)
+	require.NoError(t, logService.AppendLogs(ctx, created.ID, []entity.LogEntry{{Line: "one"}, {Line: "two"}}))
+
+	received := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- logService.FollowLogs(ctx, created.ID, entity.LogOptions{Tail: 1}, func(entries []entity.LogEntry) error {
+			for _, entry := range entries {
+				received <- entry.Line
+			}
+			return nil
+		})
+	}()
+
+	assert.Equal(t, "two", <-received, "the first batch is limited to the tail")
+	require.NoError(t, logService.AppendLogs(ctx, created.ID, []entity.LogEntry{{Line: "three"}}))
+	assert.Equal(t, "three", <-received)
+
+	for _, status := range []entity.ContainerStatus{
+		entity.ContainerStatusCreating,
+		entity.ContainerStatusRunning,
+		entity.ContainerStatusExited,
+	} {
+		_, err := containerService.UpdateStatus(ctx, created.ID, &entity.UpdateContainerStatus{Status: status})
+		require.NoError(t, err)
+	}
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("following did not stop after the container exited")
+	}
+}
+
+func TestPrunerBoundsLogs(t *testing.T) {
+	ctx := context.Background()
+	_, logService, created := newServices(t)
+	pruner := logs.NewPruner(logService, time.Minute, time.Hour, 2)
+	now := time.Now().UTC()
+
+	require.NoError(t, logService.AppendLogs(ctx, created.ID, []entity.LogEntry{
+		{Line: "expired", Timestamp: now.Add(-2 * time.Hour)},
+		{Line: "a", Timestamp: now},
+		{Line: "b", Timestamp: now},
+		{Line: "c", Timestamp: now},
+	}))
+	require.NoError(t, pruner.Reconcile(ctx, now))
+
+	entries, err := logService.GetLogs(ctx, created.ID, entity.LogOptions{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "b", entries[0].Line)
+	assert.Equal(t, "c", entries[1].Line)
+}
@@ -0,0 +1,54 @@
+package logs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Pruner keeps the stored logs bounded by removing entries older than the
+// retention period and all but the newest maxEntries of every container.
+type Pruner struct {
+	service    *Service
+	interval   time.Duration
+	retention  time.Duration
+	maxEntries int
+}
+
+func NewPruner(service *Service, interval, retention time.Duration, maxEntries int) *Pruner {
+	return &Pruner{
+		service:    service,
+		interval:   interval,
+		retention:  retention,
+		maxEntries: maxEntries,
+	}
+}
+
+// Run blocks until ctx is cancelled, pruning every interval.
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := p.Reconcile(ctx, now.UTC()); err != nil {
+				log.Printf("log pruner: %v", err)
+			}
+		}
+	}
+}
+
+// Reconcile removes the entries beyond the bounds at now.
+func (p *Pruner) Reconcile(ctx context.Context, now time.Time) error {
+	removed, err := p.service.repo.Prune(ctx, now.Add(-p.retention), p.maxEntries)
+	if err != nil {
+		return err
+	}
+	if removed > 0 {
+		log.Printf("log pruner: removed %d log entries", removed)
+	}
+	return nil
+}
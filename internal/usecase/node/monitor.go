@@ -0,0 +1,56 @@
+package node
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Monitor periodically compares node heartbeats against a grace period and
+// flips node statuses between running and failed accordingly.
+type Monitor struct {
+	service     *Service
+	interval    time.Duration
+	gracePeriod time.Duration
+}
+
+func NewMonitor(service *Service, interval, gracePeriod time.Duration) *Monitor {
+	return &Monitor{
+		service:     service,
+		interval:    interval,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// Run blocks until ctx is cancelled, checking node heartbeats every interval.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+func (m *Monitor) check(ctx context.Context) {
+	deadline := time.Now().UTC().Add(-m.gracePeriod)
+
+	failed, err := m.service.FailStaleNodes(ctx, deadline)
+	if err != nil {
+		log.Printf("node monitor: failing stale nodes: %v", err)
+	} else if failed > 0 {
+		log.Printf("node monitor: marked %d node(s) as failed", failed)
+	}
+
+	recovered, err := m.service.RecoverNodes(ctx, deadline)
+	if err != nil {
+		log.Printf("node monitor: recovering nodes: %v", err)
+	} else if recovered > 0 {
+		log.Printf("node monitor: marked %d node(s) as running", recovered)
+	}
+}
@@ -2,150 +2,347 @@ package node
 
 import (
 	"context"
-	"database/sql"
+	"fmt"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/patch"
+	"github.com/wensiet/morchy-api/internal/usecase/watch"
 	"github.com/wensiet/morchy-api/pkg/entity"
-)
-
-const (
-	GetNodeQueryWithContainers = `
-		SELECT n.id, n.status, c.id, c.node_id, c.image, c.status
-		FROM node n
-		LEFT JOIN container c ON n.id = c.node_id
-		WHERE n.id = $1`
-	ListNodesQueryWithContainers = `
-		SELECT n.id, n.status, c.id, c.node_id, c.image, c.status
-		FROM node n
-		LEFT JOIN container c ON n.id = c.node_id`
-	AddNodeQuery    = "INSERT INTO node(id, status) VALUES($1, $2)"
-	UpdateNodeQuery = "UPDATE node SET status = $1 WHERE id = $2"
-	DeleteNodeQuery = "DELETE FROM node WHERE id = $1"
+	"time"
 )
 
 type IService interface {
 	GetNode(ctx context.Context, id uuid.UUID) (*entity.Node, error)
-	ListNodes(ctx context.Context) ([]*entity.Node, error)
-	AddNode(ctx context.Context) (*entity.Node, error)
+	ListNodes(ctx context.Context, opts entity.ListNodesOptions) ([]*entity.Node, string, error)
+	AddNode(ctx context.Context, req *entity.AddNode) (*entity.Node, error)
 	UpdateNode(ctx context.Context, node *entity.Node) error
+	PatchNode(ctx context.Context, id uuid.UUID, p patch.Patch, resourceVersion int64) (*entity.Node, error)
 	DeleteNode(ctx context.Context, id uuid.UUID) error
+	AddNodes(ctx context.Context, req *entity.BatchAddNodes) ([]*entity.Node, []error, error)
+	UpdateNodes(ctx context.Context, req *entity.BatchUpdateNodes) ([]*entity.Node, []error, error)
+	DeleteNodes(ctx context.Context, req *entity.BatchDelete) ([]error, error)
+	Cordon(ctx context.Context, id uuid.UUID) (*entity.Node, error)
+	Uncordon(ctx context.Context, id uuid.UUID) (*entity.Node, error)
+	Heartbeat(ctx context.Context, id uuid.UUID, capacity *entity.Resources) error
+	GetDesiredState(ctx context.Context, id uuid.UUID) (*entity.NodeDesiredState, error)
+	Watch(ctx context.Context, resourceVersion uint64) (*watch.Subscription, error)
 }
 
 type Service struct {
-	dbPool *pgxpool.Pool
+	repo usecase.NodeRepository
+	bus  *watch.Bus
 }
 
-func NewService(db *pgxpool.Pool) *Service {
+func NewService(repo usecase.NodeRepository, bus *watch.Bus) *Service {
 	return &Service{
-		dbPool: db,
+		repo: repo,
+		bus:  bus,
 	}
 }
 
 func (s *Service) GetNode(ctx context.Context, id uuid.UUID) (*entity.Node, error) {
-	conn, err := s.dbPool.Acquire(ctx)
-	if err != nil {
-		return nil, err
+	return s.repo.Get(ctx, id)
+}
+
+// ListNodes returns one page of nodes matching opts together with the
+// continue token for the next page, which is empty on the last page.
+func (s *Service) ListNodes(ctx context.Context, opts entity.ListNodesOptions) ([]*entity.Node, string, error) {
+	if opts.Status != "" {
+		if err := opts.Status.Validate(); err != nil {
+			return nil, "", fmt.Errorf("%w: %s", usecase.InvalidListOptionsErr, err)
+		}
 	}
-	defer conn.Release()
+	return s.repo.List(ctx, opts)
+}
 
-	var node entity.Node
-	rows, err := conn.Query(ctx, GetNodeQueryWithContainers, id)
+func (s *Service) AddNode(ctx context.Context, req *entity.AddNode) (*entity.Node, error) {
+	node, err := newNode(req)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	if err = s.repo.Create(ctx, node); err != nil {
+		return nil, err
+	}
+	s.bus.Publish(watch.NodeKind, entity.WatchEventAdded, node)
+	return node, nil
+}
 
-	for rows.Next() {
-		var container entity.Container
-		var image, status sql.NullString
+// AddNodes creates the nodes of the batch like AddNode does in a single
+// transaction. It returns the created node or the error of every item, in
+// atomic mode either all items are created or none.
+func (s *Service) AddNodes(ctx context.Context, req *entity.BatchAddNodes) ([]*entity.Node, []error, error) {
+	if err := entity.ValidateBatch(&req.Mode, len(req.Items)); err != nil {
+		return nil, nil, err
+	}
+	atomic := req.Mode == entity.BatchModeAtomic
 
-		err := rows.Scan(&node.ID, &node.Status, &container.ID, &container.NodeID, &image, &status)
-		if err != nil {
-			return nil, err
+	nodes := make([]*entity.Node, len(req.Items))
+	errs := make([]error, len(req.Items))
+	for i := range req.Items {
+		nodes[i], errs[i] = newNode(&req.Items[i])
+		if errs[i] != nil && atomic {
+			return make([]*entity.Node, len(nodes)), usecase.AbortBatch(errs, i), nil
 		}
+	}
 
-		if container.ID != uuid.Nil && image.Valid && status.Valid {
-			container.Image = image.String
-			container.Status = entity.ContainerStatus(status.String)
-			err := container.Status.Validate()
-			if err != nil {
-				return nil, err
-			}
-			node.Containers = append(node.Containers, container)
+	err := usecase.CreateValid(nodes, errs, func(valid []*entity.Node) ([]error, error) {
+		return s.repo.CreateBatch(ctx, valid, atomic)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, node := range nodes {
+		if node != nil {
+			s.bus.Publish(watch.NodeKind, entity.WatchEventAdded, node)
 		}
 	}
+	return nodes, errs, nil
+}
 
-	if node.ID == uuid.Nil {
-		return nil, usecase.NodeNotFoundErr
+// newNode validates req and makes the node it asks for.
+func newNode(req *entity.AddNode) (*entity.Node, error) {
+	if err := req.Capacity.Validate(); err != nil {
+		return nil, err
+	}
+	if err := req.Labels.Validate(); err != nil {
+		return nil, err
+	}
+	if err := entity.ValidateTaints(req.Taints); err != nil {
+		return nil, err
 	}
 
-	return &node, nil
+	node := entity.NewNode(req.Capacity)
+	if req.Labels != nil {
+		node.Labels = req.Labels
+	}
+	if req.Taints != nil {
+		node.Taints = req.Taints
+	}
+	return node, nil
 }
 
-func (s *Service) ListNodes(ctx context.Context) ([]*entity.Node, error) {
-	conn, err := s.dbPool.Acquire(ctx)
-	if err != nil {
-		return nil, err
+// UpdateNode saves the status, labels and taints of the node, labels and
+// taints are kept when they are omitted. Cordoning is left to Cordon and
+// Uncordon. A non-zero resource version has to match the stored one, else
+// the update fails with ResourceVersionConflictErr. The resource version of
+// node is set to the new one on success.
+func (s *Service) UpdateNode(ctx context.Context, node *entity.Node) error {
+	if err := validateUpdate(node); err != nil {
+		return err
 	}
-	defer conn.Release()
 
-	var nodes []*entity.Node
-	rows, err := conn.Query(ctx, ListNodesQueryWithContainers)
+	updated, err := s.repo.Update(ctx, node.ID, func(current *entity.Node) error {
+		return applyUpdate(current, node)
+	})
 	if err != nil {
-		return nil, err
+		return err
+	}
+	node.ResourceVersion = updated.ResourceVersion
+	s.bus.Publish(watch.NodeKind, entity.WatchEventModified, updated)
+	return nil
+}
+
+// UpdateNodes saves the nodes of the batch like UpdateNode does in a single
+// transaction. Every item has to carry the resource version it is based on,
+// items without one fail with ResourceVersionRequiredErr. It returns the
+// updated node or the error of every item, in atomic mode either all items
+// are updated or none.
+func (s *Service) UpdateNodes(ctx context.Context, req *entity.BatchUpdateNodes) ([]*entity.Node, []error, error) {
+	if err := entity.ValidateBatch(&req.Mode, len(req.Items)); err != nil {
+		return nil, nil, err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var node entity.Node
-		err := rows.Scan(&node.ID, &node.Status)
-		if err != nil {
-			return nil, err
+	ids := make([]uuid.UUID, len(req.Items))
+	for i := range req.Items {
+		ids[i] = req.Items[i].ID
+	}
+	updated, errs, err := s.repo.UpdateBatch(ctx, ids, func(i int, current *entity.Node) error {
+		if req.Items[i].ResourceVersion == 0 {
+			return usecase.ResourceVersionRequiredErr
+		}
+		if err := validateUpdate(&req.Items[i]); err != nil {
+			return err
+		}
+		return applyUpdate(current, &req.Items[i])
+	}, req.Mode == entity.BatchModeAtomic)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, node := range updated {
+		if node != nil {
+			s.bus.Publish(watch.NodeKind, entity.WatchEventModified, node)
 		}
-		nodes = append(nodes, &node)
 	}
-	return nodes, nil
+	return updated, errs, nil
 }
 
-func (s *Service) AddNode(ctx context.Context) (*entity.Node, error) {
-	conn, err := s.dbPool.Acquire(ctx)
+// PatchNode applies a JSON Merge Patch or JSON Patch to the current node and
+// saves the result like UpdateNode does. Only the status, labels and taints
+// can be changed, patches to other fields fail with InvalidPatchErr. The
+// resource version of the patched node has to match the stored one, a
+// non-zero resourceVersion takes its place.
+func (s *Service) PatchNode(ctx context.Context, id uuid.UUID, p patch.Patch, resourceVersion int64) (*entity.Node, error) {
+	updated, err := s.repo.Update(ctx, id, func(current *entity.Node) error {
+		var patched entity.Node
+		if err := p.Apply(current, &patched, patchableFields...); err != nil {
+			return err
+		}
+		if resourceVersion != 0 {
+			patched.ResourceVersion = resourceVersion
+		}
+		if err := validateUpdate(&patched); err != nil {
+			return err
+		}
+		return applyUpdate(current, &patched)
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Release()
+	s.bus.Publish(watch.NodeKind, entity.WatchEventModified, updated)
+	return updated, nil
+}
 
-	node := entity.NewNode()
-	_, err = conn.Exec(ctx, AddNodeQuery, node.ID, node.Status)
+// patchableFields are the members of a node that PatchNode changes.
+var patchableFields = []string{"status", "labels", "taints", "resource_version"}
+
+// validateUpdate checks the fields of node that updates change.
+func validateUpdate(node *entity.Node) error {
+	if err := node.Status.Validate(); err != nil {
+		return err
+	}
+	if err := node.Labels.Validate(); err != nil {
+		return err
+	}
+	return entity.ValidateTaints(node.Taints)
+}
+
+// applyUpdate copies the status, labels and taints of node to current after
+// checking the resource version.
+func applyUpdate(current, node *entity.Node) error {
+	if node.ResourceVersion != 0 && node.ResourceVersion != current.ResourceVersion {
+		return fmt.Errorf("%w: got %d, current is %d",
+			usecase.ResourceVersionConflictErr, node.ResourceVersion, current.ResourceVersion)
+	}
+	current.Status = node.Status
+	if node.Labels != nil {
+		current.Labels = node.Labels
+	}
+	if node.Taints != nil {
+		current.Taints = node.Taints
+	}
+	return nil
+}
+
+// Cordon marks the node unschedulable, the containers already on it are kept.
+func (s *Service) Cordon(ctx context.Context, id uuid.UUID) (*entity.Node, error) {
+	return s.setUnschedulable(ctx, id, true)
+}
+
+// Uncordon makes the node schedulable again.
+func (s *Service) Uncordon(ctx context.Context, id uuid.UUID) (*entity.Node, error) {
+	return s.setUnschedulable(ctx, id, false)
+}
+
+func (s *Service) setUnschedulable(ctx context.Context, id uuid.UUID, unschedulable bool) (*entity.Node, error) {
+	updated, err := s.repo.Update(ctx, id, func(current *entity.Node) error {
+		current.Unschedulable = unschedulable
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	return node, nil
+	s.bus.Publish(watch.NodeKind, entity.WatchEventModified, updated)
+	return updated, nil
 }
 
-func (s *Service) UpdateNode(ctx context.Context, node *entity.Node) error {
-	conn, err := s.dbPool.Acquire(ctx)
-	if err != nil {
+// DeleteNode removes a node without containers.
+func (s *Service) DeleteNode(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
 		return err
 	}
-	defer conn.Release()
+	s.bus.Publish(watch.NodeKind, entity.WatchEventDeleted, &entity.Node{ID: id, Containers: []entity.Container{}})
+	return nil
+}
+
+// DeleteNodes removes the nodes of the batch like DeleteNode does in a single
+// transaction and returns the error of every item, in atomic mode either all
+// items are deleted or none.
+func (s *Service) DeleteNodes(ctx context.Context, req *entity.BatchDelete) ([]error, error) {
+	if err := entity.ValidateBatch(&req.Mode, len(req.IDs)); err != nil {
+		return nil, err
+	}
+	errs, err := s.repo.DeleteBatch(ctx, req.IDs, req.Mode == entity.BatchModeAtomic)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range req.IDs {
+		if errs[i] == nil {
+			s.bus.Publish(watch.NodeKind, entity.WatchEventDeleted, &entity.Node{ID: id, Containers: []entity.Container{}})
+		}
+	}
+	return errs, nil
+}
 
-	err = node.Status.Validate()
+// Heartbeat records that the node with the given id is alive right now.
+// A non-nil capacity replaces the capacity reported earlier, watchers are
+// told about the node when that changes its capacity.
+func (s *Service) Heartbeat(ctx context.Context, id uuid.UUID, capacity *entity.Resources) error {
+	if capacity != nil {
+		if err := capacity.Validate(); err != nil {
+			return err
+		}
+	}
+	changed, err := s.repo.Heartbeat(ctx, id, time.Now().UTC(), capacity)
 	if err != nil {
 		return err
 	}
+	if changed {
+		s.publishModified(ctx, id)
+	}
+	return nil
+}
 
-	_, err = conn.Exec(ctx, UpdateNodeQuery, node.Status, node.ID)
-	return err
+// FailStaleNodes moves running nodes whose last heartbeat is older than
+// deadline, or that never sent one, to FailedNodeStatus.
+func (s *Service) FailStaleNodes(ctx context.Context, deadline time.Time) (int64, error) {
+	ids, err := s.repo.FailStale(ctx, deadline)
+	return s.publishStatuses(ctx, ids, err)
 }
 
-func (s *Service) DeleteNode(ctx context.Context, id uuid.UUID) error {
-	conn, err := s.dbPool.Acquire(ctx)
+// RecoverNodes moves new and failed nodes that sent a heartbeat after deadline to RunningNodeStatus.
+func (s *Service) RecoverNodes(ctx context.Context, deadline time.Time) (int64, error) {
+	ids, err := s.repo.Recover(ctx, deadline)
+	return s.publishStatuses(ctx, ids, err)
+}
+
+// publishStatuses publishes a watch event for each node changed by a bulk status update.
+func (s *Service) publishStatuses(ctx context.Context, ids []uuid.UUID, err error) (int64, error) {
 	if err != nil {
-		return err
+		return 0, err
 	}
-	defer conn.Release()
+	for _, id := range ids {
+		s.publishModified(ctx, id)
+	}
+	return int64(len(ids)), nil
+}
 
-	_, err = conn.Exec(ctx, DeleteNodeQuery, id)
-	return err
+// GetDesiredState returns every container assigned to the node together with
+// the node revision, which grows whenever that set of containers changes.
+func (s *Service) GetDesiredState(ctx context.Context, id uuid.UUID) (*entity.NodeDesiredState, error) {
+	return s.repo.GetDesiredState(ctx, id)
+}
+
+// Watch streams node events published after resourceVersion.
+func (s *Service) Watch(ctx context.Context, resourceVersion uint64) (*watch.Subscription, error) {
+	return s.bus.Subscribe(ctx, watch.NodeKind, resourceVersion)
+}
+
+// publishModified publishes the current state of the node. A node that can
+// no longer be read was deleted meanwhile and DeleteNode publishes that instead.
+func (s *Service) publishModified(ctx context.Context, id uuid.UUID) {
+	node, err := s.GetNode(ctx, id)
+	if err != nil {
+		return
+	}
+	s.bus.Publish(watch.NodeKind, entity.WatchEventModified, node)
 }
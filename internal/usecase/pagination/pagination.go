@@ -0,0 +1,208 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"strconv"
+	"strings"
+)
+
+const DefaultSortBy = "id"
+
+// NodeSortFields returns the value of every sortable node field.
+var NodeSortFields = map[string]func(node *entity.Node) string{
+	"id":     func(node *entity.Node) string { return node.ID.String() },
+	"status": func(node *entity.Node) string { return string(node.Status) },
+}
+
+// ContainerSortFields returns the value of every sortable container field.
+var ContainerSortFields = map[string]func(container *entity.Container) string{
+	"id":      func(container *entity.Container) string { return container.ID.String() },
+	"image":   func(container *entity.Container) string { return container.Image },
+	"status":  func(container *entity.Container) string { return string(container.Status) },
+	"node_id": func(container *entity.Container) string { return container.NodeID.String() },
+}
+
+// DeploymentSortFields returns the value of every sortable deployment field.
+var DeploymentSortFields = map[string]func(deployment *entity.Deployment) string{
+	"id":   func(deployment *entity.Deployment) string { return deployment.ID.String() },
+	"name": func(deployment *entity.Deployment) string { return deployment.Name },
+}
+
+// RolloutSortFields returns the value of every sortable rollout field.
+var RolloutSortFields = map[string]func(rollout *entity.Rollout) string{
+	"id":    func(rollout *entity.Rollout) string { return rollout.ID.String() },
+	"phase": func(rollout *entity.Rollout) string { return string(rollout.Phase) },
+}
+
+// JobSortFields returns the value of every sortable job field.
+var JobSortFields = map[string]func(job *entity.Job) string{
+	"id":   func(job *entity.Job) string { return job.ID.String() },
+	"name": func(job *entity.Job) string { return job.Name },
+}
+
+// CronJobSortFields returns the value of every sortable cron job field.
+var CronJobSortFields = map[string]func(cronJob *entity.CronJob) string{
+	"id":   func(cronJob *entity.CronJob) string { return cronJob.ID.String() },
+	"name": func(cronJob *entity.CronJob) string { return cronJob.Name },
+}
+
+// Cursor is the position right after the last item of a page, it is handed
+// out to clients as an opaque continue token.
+type Cursor struct {
+	SortBy string `json:"s"`
+	Value  string `json:"v"`
+	ID     string `json:"id"`
+}
+
+// Page is a validated entity.ListOptions.
+type Page struct {
+	SortBy     string
+	Descending bool
+	// After is nil for the first page.
+	After *Cursor
+	// Limit is zero when every remaining item is requested.
+	Limit int
+}
+
+// Parse validates opts against the sortable fields and decodes the continue token.
+func Parse(opts entity.ListOptions, isSortable func(field string) bool) (Page, error) {
+	page := Page{SortBy: opts.SortBy, Limit: opts.Limit}
+	if page.SortBy == "" {
+		page.SortBy = DefaultSortBy
+	}
+	if !isSortable(page.SortBy) {
+		return page, fmt.Errorf("%w: cannot sort by %q", usecase.InvalidListOptionsErr, page.SortBy)
+	}
+
+	switch opts.Order {
+	case "", entity.SortAscending:
+	case entity.SortDescending:
+		page.Descending = true
+	default:
+		return page, fmt.Errorf("%w: unknown order %q", usecase.InvalidListOptionsErr, opts.Order)
+	}
+	if opts.Limit < 0 {
+		return page, fmt.Errorf("%w: limit must not be negative", usecase.InvalidListOptionsErr)
+	}
+
+	if opts.Continue != "" {
+		c, err := decodeToken(opts.Continue)
+		if err != nil {
+			return page, err
+		}
+		if c.SortBy != page.SortBy {
+			return page, fmt.Errorf("%w: token was issued for sorting by %q", usecase.InvalidContinueTokenErr, c.SortBy)
+		}
+		page.After = &c
+	}
+	return page, nil
+}
+
+// NextToken builds the continue token pointing after the item with the given
+// sort value and id.
+func (p Page) NextToken(value, id string) string {
+	raw, _ := json.Marshal(Cursor{SortBy: p.SortBy, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// NextToken builds the continue token for sortBy pointing after the item with
+// the given sort value and id.
+func NextToken(sortBy, value, id string) string {
+	if sortBy == "" {
+		sortBy = DefaultSortBy
+	}
+	return Page{SortBy: sortBy}.NextToken(value, id)
+}
+
+func decodeToken(token string) (Cursor, error) {
+	var c Cursor
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, usecase.InvalidContinueTokenErr
+	}
+	if err = json.Unmarshal(raw, &c); err != nil || c.ID == "" {
+		return c, usecase.InvalidContinueTokenErr
+	}
+	return c, nil
+}
+
+// Builder collects WHERE conditions together with their positional arguments.
+type Builder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// Arg adds an argument and returns its placeholder.
+func (b *Builder) Arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// Where adds a condition, every %s in format is replaced by a placeholder for the matching value.
+func (b *Builder) Where(format string, values ...interface{}) {
+	placeholders := make([]interface{}, len(values))
+	for i, value := range values {
+		placeholders[i] = b.Arg(value)
+	}
+	b.conditions = append(b.conditions, fmt.Sprintf(format, placeholders...))
+}
+
+// WhereClause returns the collected conditions joined into a WHERE clause.
+func (b *Builder) WhereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+func (b *Builder) Args() []interface{} {
+	return b.args
+}
+
+// Page adds the continue token condition to the builder and returns the
+// parsed page with its ORDER BY and LIMIT clauses. sortColumns maps the
+// sortable fields to their columns and must contain DefaultSortBy. One extra
+// row is requested so that callers can tell whether another page exists.
+func (b *Builder) Page(opts entity.ListOptions, sortColumns map[string]string) (Page, string, string, error) {
+	page, err := Parse(opts, func(field string) bool {
+		_, ok := sortColumns[field]
+		return ok
+	})
+	if err != nil {
+		return page, "", "", err
+	}
+	column := sortColumns[page.SortBy]
+	idColumn := sortColumns[DefaultSortBy]
+
+	direction, comparison := "ASC", ">"
+	if page.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if page.After != nil {
+		if column == idColumn {
+			b.Where(idColumn+" "+comparison+" %s", page.After.ID)
+		} else {
+			b.Where("("+column+", "+idColumn+") "+comparison+" (%s, %s)", page.After.Value, page.After.ID)
+		}
+	}
+
+	orderBy := "ORDER BY " + idColumn + " " + direction
+	if column != idColumn {
+		orderBy = "ORDER BY " + column + " " + direction + ", " + idColumn + " " + direction
+	}
+	limit := ""
+	if page.Limit > 0 {
+		limit = "LIMIT " + b.Arg(page.Limit+1)
+	}
+	return page, orderBy, limit, nil
+}
+
+// EscapeLike escapes the LIKE wildcards in value so that it matches literally.
+func EscapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,62 @@
+package pagination_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/pagination"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+)
+
+var sortColumns = map[string]string{
+	"id":    "id",
+	"image": "image",
+}
+
+func TestBuilder_Page(t *testing.T) {
+	var b pagination.Builder
+	b.Where("status = %s", "running")
+	_, orderBy, limit, err := b.Page(entity.ListOptions{Limit: 10}, sortColumns)
+	assert.NoError(t, err)
+	assert.Equal(t, "WHERE status = $1", b.WhereClause())
+	assert.Equal(t, "ORDER BY id ASC", orderBy)
+	assert.Equal(t, "LIMIT $2", limit)
+	assert.Equal(t, []interface{}{"running", 11}, b.Args())
+}
+
+func TestBuilder_PageContinue(t *testing.T) {
+	token := pagination.NextToken("image", "nginx", "42")
+
+	var b pagination.Builder
+	page, orderBy, limit, err := b.Page(entity.ListOptions{
+		Continue: token,
+		SortBy:   "image",
+		Order:    entity.SortDescending,
+	}, sortColumns)
+	assert.NoError(t, err)
+	assert.Equal(t, "WHERE (image, id) < ($1, $2)", b.WhereClause())
+	assert.Equal(t, "ORDER BY image DESC, id DESC", orderBy)
+	assert.Empty(t, limit)
+	assert.Equal(t, []interface{}{"nginx", "42"}, b.Args())
+	assert.True(t, page.Descending)
+	assert.Equal(t, token, page.NextToken("nginx", "42"))
+}
+
+func TestBuilder_PageInvalid(t *testing.T) {
+	var b pagination.Builder
+	_, _, _, err := b.Page(entity.ListOptions{SortBy: "spec"}, sortColumns)
+	assert.ErrorIs(t, err, usecase.InvalidListOptionsErr)
+
+	_, _, _, err = b.Page(entity.ListOptions{Order: "sideways"}, sortColumns)
+	assert.ErrorIs(t, err, usecase.InvalidListOptionsErr)
+
+	_, _, _, err = b.Page(entity.ListOptions{Continue: "not a token"}, sortColumns)
+	assert.ErrorIs(t, err, usecase.InvalidContinueTokenErr)
+
+	_, _, _, err = b.Page(entity.ListOptions{Continue: pagination.NextToken("id", "1", "1"), SortBy: "image"}, sortColumns)
+	assert.ErrorIs(t, err, usecase.InvalidContinueTokenErr)
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `registry.io/my\_app\%`, pagination.EscapeLike("registry.io/my_app%"))
+}
@@ -0,0 +1,144 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"slices"
+)
+
+// Type is the media type of a patch document.
+type Type string
+
+const (
+	// MergePatchType is a JSON Merge Patch as described by RFC 7386.
+	MergePatchType Type = "application/merge-patch+json"
+	// JSONPatchType is a JSON Patch as described by RFC 6902.
+	JSONPatchType Type = "application/json-patch+json"
+)
+
+// Patch is a patch document together with its media type.
+type Patch struct {
+	Type Type
+	Data []byte
+}
+
+// Apply applies the patch to the JSON encoding of original and decodes the
+// result into patched. Only the top-level members named by mutable may
+// change, patches to any other member are rejected instead of being dropped
+// silently, and so are fields that patched does not have. Malformed patches
+// fail with InvalidPatchErr and failed test operations with PatchTestFailedErr.
+func (p Patch) Apply(original, patched any, mutable ...string) error {
+	raw, err := json.Marshal(original)
+	if err != nil {
+		return err
+	}
+	var doc, unpatched any
+	if err = decode(raw, &doc); err != nil {
+		return err
+	}
+	if err = decode(raw, &unpatched); err != nil {
+		return err
+	}
+
+	switch p.Type {
+	case MergePatchType:
+		var merge any
+		if err = decode(p.Data, &merge); err != nil {
+			return fmt.Errorf("%w: %s", usecase.InvalidPatchErr, err)
+		}
+		doc = mergePatch(doc, merge)
+	case JSONPatchType:
+		var operations []operation
+		if err = decode(p.Data, &operations); err != nil {
+			return fmt.Errorf("%w: %s", usecase.InvalidPatchErr, err)
+		}
+		for i, op := range operations {
+			if doc, err = op.apply(doc); err != nil {
+				return fmt.Errorf("operation %d: %w", i, err)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unsupported patch type %q", usecase.InvalidPatchErr, p.Type)
+	}
+
+	if err = checkImmutable(unpatched, doc, mutable); err != nil {
+		return err
+	}
+	if raw, err = json.Marshal(doc); err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(patched); err != nil {
+		return fmt.Errorf("%w: %s", usecase.InvalidPatchErr, err)
+	}
+	return nil
+}
+
+// checkImmutable fails with InvalidPatchErr when a top-level member of
+// original other than the mutable ones was changed, added or removed in patched.
+func checkImmutable(original, patched any, mutable []string) error {
+	before, _ := original.(map[string]any)
+	after, ok := patched.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%w: the patched document is not an object", usecase.InvalidPatchErr)
+	}
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if slices.Contains(mutable, key) {
+			continue
+		}
+		value, found := before[key]
+		other, ok := after[key]
+		if found != ok || !equal(value, other) {
+			return fmt.Errorf("%w: /%s cannot be changed", usecase.InvalidPatchErr, key)
+		}
+	}
+	return nil
+}
+
+// mergePatch merges patch into target following RFC 7386, null members
+// remove the member from target and anything but an object replaces it.
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// decode reads a single JSON value keeping numbers as json.Number, so that
+// large integers survive a round trip unchanged.
+func decode(raw []byte, value any) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(value); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package patch_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/patch"
+	"testing"
+)
+
+type document struct {
+	Name   string            `json:"name"`
+	Size   int64             `json:"size"`
+	Labels map[string]string `json:"labels"`
+	Ports  []int             `json:"ports"`
+}
+
+var mutable = []string{"name", "labels", "ports"}
+
+func newDocument() document {
+	return document{
+		Name:   "web",
+		Size:   1 << 62,
+		Labels: map[string]string{"app": "web", "a/b": "1"},
+		Ports:  []int{80, 443},
+	}
+}
+
+func TestPatch_ApplyMergePatch(t *testing.T) {
+	var patched document
+	err := patch.Patch{
+		Type: patch.MergePatchType,
+		Data: []byte(`{"name": "api", "labels": {"app": null, "tier": "backend"}, "ports": [8080]}`),
+	}.Apply(newDocument(), &patched, mutable...)
+	require.NoError(t, err)
+	assert.Equal(t, document{
+		Name:   "api",
+		Size:   1 << 62,
+		Labels: map[string]string{"a/b": "1", "tier": "backend"},
+		Ports:  []int{8080},
+	}, patched)
+}
+
+func TestPatch_ApplyJSONPatch(t *testing.T) {
+	var patched document
+	err := patch.Patch{
+		Type: patch.JSONPatchType,
+		Data: []byte(`[
+			{"op": "test", "path": "/name", "value": "web"},
+			{"op": "replace", "path": "/name", "value": "api"},
+			{"op": "add", "path": "/ports/-", "value": 8443},
+			{"op": "add", "path": "/ports/0", "value": 22},
+			{"op": "remove", "path": "/ports/1"},
+			{"op": "copy", "from": "/labels/app", "path": "/labels/copy"},
+			{"op": "move", "from": "/labels/a~1b", "path": "/labels/moved"},
+			{"op": "test", "path": "/size", "value": 4611686018427387904}
+		]`),
+	}.Apply(newDocument(), &patched, mutable...)
+	require.NoError(t, err)
+	assert.Equal(t, document{
+		Name:   "api",
+		Size:   1 << 62,
+		Labels: map[string]string{"app": "web", "copy": "web", "moved": "1"},
+		Ports:  []int{22, 443, 8443},
+	}, patched)
+}
+
+func TestPatch_ApplyErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch patch.Patch
+		err   error
+	}{
+		{"unsupported type", patch.Patch{Type: "application/json", Data: []byte(`{}`)}, usecase.InvalidPatchErr},
+		{"malformed merge patch", patch.Patch{Type: patch.MergePatchType, Data: []byte(`{`)}, usecase.InvalidPatchErr},
+		{"unknown field", patch.Patch{Type: patch.MergePatchType, Data: []byte(`{"nmae": "api"}`)}, usecase.InvalidPatchErr},
+		{"wrong type", patch.Patch{Type: patch.MergePatchType, Data: []byte(`{"size": "big"}`)}, usecase.InvalidPatchErr},
+		{"not a list", patch.Patch{Type: patch.JSONPatchType, Data: []byte(`{"op": "add"}`)}, usecase.InvalidPatchErr},
+		{"unknown op", patch.Patch{Type: patch.JSONPatchType, Data: []byte(`[{"op": "drop", "path": "/name"}]`)}, usecase.InvalidPatchErr},
+		{"missing value", patch.Patch{Type: patch.JSONPatchType, Data: []byte(`[{"op": "add", "path": "/name"}]`)}, usecase.InvalidPatchErr},
+		{"missing member", patch.Patch{Type: patch.JSONPatchType, Data: []byte(`[{"op": "remove", "path": "/labels/x"}]`)}, usecase.InvalidPatchErr},
+		{"out of bounds", patch.Patch{Type: patch.JSONPatchType, Data: []byte(`[{"op": "replace", "path": "/ports/2", "value": 1}]`)}, usecase.InvalidPatchErr},
+		{"leading zero", patch.Patch{Type: patch.JSONPatchType, Data: []byte(`[{"op": "remove", "path": "/ports/01"}]`)}, usecase.InvalidPatchErr},
+		{"relative pointer", patch.Patch{Type: patch.JSONPatchType, Data: []byte(`[{"op": "remove", "path": "name"}]`)}, usecase.InvalidPatchErr},
+		{"move into child", patch.Patch{Type: patch.JSONPatchType, Data: []byte(`[{"op": "move", "from": "/labels", "path": "/labels/x"}]`)}, usecase.InvalidPatchErr},
+		{"immutable member", patch.Patch{Type: patch.MergePatchType, Data: []byte(`{"size": 1}`)}, usecase.InvalidPatchErr},
+		{"removed immutable member", patch.Patch{Type: patch.JSONPatchType, Data: []byte(`[{"op": "remove", "path": "/size"}]`)}, usecase.InvalidPatchErr},
+		{"not an object", patch.Patch{Type: patch.MergePatchType, Data: []byte(`[]`)}, usecase.InvalidPatchErr},
+		{"failed test", patch.Patch{Type: patch.JSONPatchType, Data: []byte(`[{"op": "test", "path": "/name", "value": "api"}]`)}, usecase.PatchTestFailedErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patched document
+			assert.ErrorIs(t, tt.patch.Apply(newDocument(), &patched, mutable...), tt.err)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"time"
+)
+
+// NodeRepository stores nodes. Getters return NodeNotFoundErr for unknown ids.
+type NodeRepository interface {
+	// Get returns the node together with the containers assigned to it.
+	Get(ctx context.Context, id uuid.UUID) (*entity.Node, error)
+	// List returns one page of nodes and the continue token of the next page.
+	List(ctx context.Context, opts entity.ListNodesOptions) ([]*entity.Node, string, error)
+	Create(ctx context.Context, node *entity.Node) error
+	// Update applies mutate to the current node and stores its status,
+	// capacity and labels atomically, incrementing its resource version.
+	// Nodes moved to running are marked as seen now, so that FailStale gives
+	// them time for a first heartbeat. Errors returned by mutate abort the
+	// update and are returned as is.
+	Update(ctx context.Context, id uuid.UUID, mutate func(node *entity.Node) error) (*entity.Node, error)
+	// Delete removes the node, failing with NodeHasContainersErr while containers are assigned to it.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Heartbeat sets the last seen time and, when capacity is not nil, the capacity of the node.
+	// It reports whether the capacity changed, only then the resource version is incremented.
+	Heartbeat(ctx context.Context, id uuid.UUID, seenAt time.Time, capacity *entity.Resources) (bool, error)
+	// FailStale moves running nodes last seen before deadline, or never seen at all, to FailedNodeStatus
+	// and returns their ids. Like Recover it increments the resource version of every node it changes.
+	FailStale(ctx context.Context, deadline time.Time) ([]uuid.UUID, error)
+	// Recover moves new and failed nodes seen since deadline to RunningNodeStatus and returns their ids.
+	Recover(ctx context.Context, deadline time.Time) ([]uuid.UUID, error)
+	GetDesiredState(ctx context.Context, id uuid.UUID) (*entity.NodeDesiredState, error)
+	// CreateBatch, UpdateBatch and DeleteBatch work like Create, Update and
+	// Delete on many nodes at once within one transaction. They return the
+	// error of every node, nil for the nodes that were changed. When atomic
+	// the first failure undoes the whole batch and every other node fails
+	// with BatchAbortedErr, else failed nodes are skipped.
+	CreateBatch(ctx context.Context, nodes []*entity.Node, atomic bool) ([]error, error)
+	UpdateBatch(ctx context.Context, ids []uuid.UUID, mutate func(i int, node *entity.Node) error, atomic bool) ([]*entity.Node, []error, error)
+	DeleteBatch(ctx context.Context, ids []uuid.UUID, atomic bool) ([]error, error)
+}
+
+// ContainerRepository stores containers. Getters return ContainerNotFoundErr
+// for unknown ids and every change of the containers assigned to a node bumps
+// the revision of that node.
+type ContainerRepository interface {
+	Get(ctx context.Context, id uuid.UUID) (*entity.Container, error)
+	// List returns one page of containers and the continue token of the next page.
+	List(ctx context.Context, opts entity.ListContainersOptions) ([]entity.Container, string, error)
+	// Create stores the container and its initial status, failing with
+	// NodeNotFoundErr for an unknown node. The capacity of the node is checked
+	// with the node locked, so that concurrent placements cannot overcommit
+	// it, and InsufficientCapacityErr is returned when the container does not fit.
+	Create(ctx context.Context, container *entity.Container) error
+	// Update applies mutate to the current container and stores the result
+	// atomically, incrementing its resource version and recording status
+	// changes in the status history. Errors returned by mutate abort the
+	// update and are returned as is. A container moved to another node, or
+	// whose resources change, is checked against the capacity like in Create.
+	Update(ctx context.Context, id uuid.UUID, mutate func(container *entity.Container) error) (*entity.Container, error)
+	// Delete removes the container and returns its last state.
+	Delete(ctx context.Context, id uuid.UUID) (*entity.Container, error)
+	ListStatusHistory(ctx context.Context, id uuid.UUID) ([]entity.ContainerStatusChange, error)
+	// AddEvent records an event of the container, events are removed together with the container.
+	AddEvent(ctx context.Context, event *entity.ContainerEvent) error
+	// ListEvents returns the events of the container oldest first.
+	ListEvents(ctx context.Context, id uuid.UUID) ([]entity.ContainerEvent, error)
+	// CreateBatch, UpdateBatch and DeleteBatch work like Create, Update and
+	// Delete on many containers at once within one transaction. They return
+	// the error of every container, nil for the containers that were changed.
+	// When atomic the first failure undoes the whole batch and every other
+	// container fails with BatchAbortedErr, else failed containers are skipped.
+	CreateBatch(ctx context.Context, containers []*entity.Container, atomic bool) ([]error, error)
+	UpdateBatch(
+		ctx context.Context,
+		ids []uuid.UUID,
+		mutate func(i int, container *entity.Container) error,
+		atomic bool,
+	) ([]*entity.Container, []error, error)
+	DeleteBatch(ctx context.Context, ids []uuid.UUID, atomic bool) ([]*entity.Container, []error, error)
+}
+
+// ContainerLogRepository stores the log entries of containers. Entries are
+// removed together with their container.
+type ContainerLogRepository interface {
+	// Append stores the entries in order, assigning their sequence numbers, and
+	// fails with ContainerNotFoundErr for an unknown container.
+	Append(ctx context.Context, containerID uuid.UUID, entries []entity.LogEntry) error
+	// List returns the entries of the container selected by opts ordered by
+	// sequence number, failing with ContainerNotFoundErr for an unknown container.
+	List(ctx context.Context, containerID uuid.UUID, opts entity.LogOptions) ([]entity.LogEntry, error)
+	// Prune removes entries logged before olderThan and all but the newest
+	// maxEntries of every container, returning how many were removed.
+	Prune(ctx context.Context, olderThan time.Time, maxEntries int) (int64, error)
+}
+
+// DeploymentRepository stores deployments. Getters return DeploymentNotFoundErr for unknown ids.
+type DeploymentRepository interface {
+	Get(ctx context.Context, id uuid.UUID) (*entity.Deployment, error)
+	// List returns one page of deployments and the continue token of the next page.
+	List(ctx context.Context, opts entity.ListOptions) ([]entity.Deployment, string, error)
+	// Create stores the deployment, failing with DeploymentExistsErr when its name is taken.
+	Create(ctx context.Context, deployment *entity.Deployment) error
+	// Update applies mutate to the current deployment and stores its replicas,
+	// template and status atomically. Errors returned by mutate abort the
+	// update and are returned as is.
+	Update(ctx context.Context, id uuid.UUID, mutate func(deployment *entity.Deployment) error) (*entity.Deployment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// RolloutRepository stores rollouts. Getters return RolloutNotFoundErr for unknown ids.
+type RolloutRepository interface {
+	Get(ctx context.Context, id uuid.UUID) (*entity.Rollout, error)
+	// List returns one page of rollouts and the continue token of the next page.
+	List(ctx context.Context, opts entity.ListOptions) ([]entity.Rollout, string, error)
+	// Create fails with RolloutConflictErr when the rollout is active and
+	// another active rollout has one of its containers.
+	Create(ctx context.Context, rollout *entity.Rollout) error
+	// Update applies mutate to the current rollout and stores everything but
+	// its id, images and creation time atomically. Errors returned by mutate
+	// abort the update and are returned as is, rollouts that become active
+	// again fail with RolloutConflictErr like in Create.
+	Update(ctx context.Context, id uuid.UUID, mutate func(rollout *entity.Rollout) error) (*entity.Rollout, error)
+}
+
+// JobRepository stores jobs. Getters return JobNotFoundErr for unknown ids.
+type JobRepository interface {
+	Get(ctx context.Context, id uuid.UUID) (*entity.Job, error)
+	// List returns one page of jobs matching opts and the continue token of the next page.
+	List(ctx context.Context, opts entity.ListJobsOptions) ([]entity.Job, string, error)
+	// Create stores the job, failing with JobExistsErr when its name is taken.
+	Create(ctx context.Context, job *entity.Job) error
+	// Update applies mutate to the current job and stores its status
+	// atomically. Errors returned by mutate abort the update and are
+	// returned as is.
+	Update(ctx context.Context, id uuid.UUID, mutate func(job *entity.Job) error) (*entity.Job, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// CronJobRepository stores cron jobs. Getters return CronJobNotFoundErr for unknown ids.
+type CronJobRepository interface {
+	Get(ctx context.Context, id uuid.UUID) (*entity.CronJob, error)
+	// List returns one page of cron jobs and the continue token of the next page.
+	List(ctx context.Context, opts entity.ListOptions) ([]entity.CronJob, string, error)
+	// Create stores the cron job, failing with CronJobExistsErr when its name is taken.
+	Create(ctx context.Context, cronJob *entity.CronJob) error
+	// Update applies mutate to the current cron job and stores everything but
+	// its id, name and creation time atomically. Errors returned by mutate
+	// abort the update and are returned as is.
+	Update(ctx context.Context, id uuid.UUID, mutate func(cronJob *entity.CronJob) error) (*entity.CronJob, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// IdempotencyRepository stores the requests sent with an idempotency key.
+// Getters return IdempotencyKeyNotFoundErr for unknown keys.
+type IdempotencyRepository interface {
+	Get(ctx context.Context, key string) (*entity.IdempotencyRecord, error)
+	// Create stores the record, failing with IdempotencyKeyExistsErr when its key is taken.
+	Create(ctx context.Context, record *entity.IdempotencyRecord) error
+	// Complete stores the response of the request with the key, failing with
+	// IdempotencyKeyNotFoundErr when the record was removed meanwhile.
+	Complete(ctx context.Context, key string, response *entity.IdempotentResponse) error
+	// Delete removes the record, unknown keys are ignored.
+	Delete(ctx context.Context, key string) error
+	// Prune removes the records created before olderThan, returning how many were removed.
+	Prune(ctx context.Context, olderThan time.Time) (int64, error)
+}
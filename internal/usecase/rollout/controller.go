@@ -0,0 +1,242 @@
+package rollout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"log"
+	"slices"
+	"time"
+)
+
+// Controller advances active rollouts one batch at a time. A batch is only
+// started once every container of the previous one runs, and only as far as
+// MaxUnavailable allows. A switched container that fails, exits or stops
+// halts the rollout.
+type Controller struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewController(service *Service, interval time.Duration) *Controller {
+	return &Controller{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run blocks until ctx is cancelled, reconciling every interval.
+func (c *Controller) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Reconcile(ctx); err != nil {
+				log.Printf("rollout controller: %v", err)
+			}
+		}
+	}
+}
+
+// Reconcile advances every active rollout that is not paused by one step.
+func (c *Controller) Reconcile(ctx context.Context) error {
+	rollouts, _, err := c.service.repo.List(ctx, entity.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing rollouts: %w", err)
+	}
+	for i := range rollouts {
+		rollout := &rollouts[i]
+		if rollout.Paused {
+			continue
+		}
+		switch rollout.Phase {
+		case entity.RolloutPhaseProgressing:
+			err = c.progress(ctx, rollout)
+		case entity.RolloutPhaseRollingBack:
+			err = c.rollBack(ctx, rollout)
+		default:
+			continue
+		}
+		if err != nil {
+			log.Printf("rollout controller: rollout %s: %v", rollout.ID, err)
+		}
+	}
+	return nil
+}
+
+func (c *Controller) progress(ctx context.Context, rollout *entity.Rollout) error {
+	containers, err := c.load(ctx, rollout.Containers)
+	if err != nil {
+		return err
+	}
+
+	waiting := 0
+	for _, id := range rollout.Updated {
+		switched, ok := containers[id]
+		if !ok || switched.Image != rollout.ToImage {
+			continue
+		}
+		if switched.IsTerminated() {
+			return c.save(ctx, rollout, nil, entity.RolloutPhaseFailed, fmt.Sprintf(
+				"container %s is %s after switching to %s", id, switched.Status, rollout.ToImage,
+			))
+		}
+		if !switched.IsReady() {
+			waiting++
+		}
+	}
+	if waiting > 0 {
+		return c.save(ctx, rollout, nil, rollout.Phase, fmt.Sprintf(
+			"waiting for %d container(s) running %s to become ready", waiting, rollout.ToImage,
+		))
+	}
+
+	var remaining []uuid.UUID
+	unavailable := 0
+	for _, id := range rollout.Containers {
+		target, ok := containers[id]
+		if !ok || target.Status == entity.ContainerStatusTerminating {
+			continue
+		}
+		if !target.IsReady() {
+			unavailable++
+		}
+		if target.Image == rollout.FromImage && !slices.Contains(rollout.Updated, id) {
+			remaining = append(remaining, id)
+		}
+	}
+	if len(remaining) == 0 {
+		return c.save(ctx, rollout, nil, entity.RolloutPhaseCompleted, "")
+	}
+
+	batch := min(rollout.BatchSize, rollout.MaxUnavailable-unavailable, len(remaining))
+	if batch <= 0 {
+		return c.save(ctx, rollout, nil, rollout.Phase, fmt.Sprintf(
+			"waiting: %d container(s) are unavailable, at most %d may be", unavailable, rollout.MaxUnavailable,
+		))
+	}
+	replaced, err := c.replace(ctx, remaining[:batch], rollout.FromImage, rollout.ToImage)
+	if err != nil {
+		return err
+	}
+	return c.save(ctx, rollout, replaced, rollout.Phase, fmt.Sprintf(
+		"switching %d container(s) to %s", len(replaced), rollout.ToImage,
+	))
+}
+
+// rollBack switches the updated containers back in batches. It ignores
+// MaxUnavailable, since the containers running ToImage are likely the
+// unavailable ones, and does not halt on containers that fail again.
+func (c *Controller) rollBack(ctx context.Context, rollout *entity.Rollout) error {
+	containers, err := c.load(ctx, rollout.Containers)
+	if err != nil {
+		return err
+	}
+
+	waiting := 0
+	for _, id := range rollout.Reverted {
+		reverted, ok := containers[id]
+		if !ok || reverted.Image != rollout.FromImage {
+			continue
+		}
+		if reverted.Status == entity.ContainerStatusPending || reverted.Status == entity.ContainerStatusCreating {
+			waiting++
+		}
+	}
+	if waiting > 0 {
+		return c.save(ctx, rollout, nil, rollout.Phase, fmt.Sprintf(
+			"waiting for %d container(s) to run %s again", waiting, rollout.FromImage,
+		))
+	}
+
+	var remaining []uuid.UUID
+	for _, id := range rollout.Updated {
+		if updated, ok := containers[id]; ok && updated.Image == rollout.ToImage {
+			remaining = append(remaining, id)
+		}
+	}
+	if len(remaining) == 0 {
+		return c.save(ctx, rollout, nil, entity.RolloutPhaseRolledBack, "")
+	}
+
+	replaced, err := c.replace(ctx, remaining[:min(rollout.BatchSize, len(remaining))], rollout.ToImage, rollout.FromImage)
+	if err != nil {
+		return err
+	}
+	return c.save(ctx, rollout, replaced, rollout.Phase, fmt.Sprintf(
+		"switching %d container(s) back to %s", len(replaced), rollout.FromImage,
+	))
+}
+
+// load returns the containers that still exist by id.
+func (c *Controller) load(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.Container, error) {
+	containers := make(map[uuid.UUID]*entity.Container, len(ids))
+	for _, id := range ids {
+		target, err := c.service.containerService.GetContainer(ctx, id)
+		if errors.Is(err, usecase.ContainerNotFoundErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		containers[id] = target
+	}
+	return containers, nil
+}
+
+// replace switches the containers from image from to image to and returns
+// the ids of those that were switched.
+func (c *Controller) replace(ctx context.Context, ids []uuid.UUID, from, to string) ([]uuid.UUID, error) {
+	var replaced []uuid.UUID
+	for _, id := range ids {
+		target, err := c.service.containerService.ReplaceImage(ctx, id, from, to)
+		if errors.Is(err, usecase.ContainerNotFoundErr) {
+			continue
+		}
+		if err != nil {
+			return replaced, err
+		}
+		if target.Image == to {
+			replaced = append(replaced, id)
+		}
+	}
+	return replaced, nil
+}
+
+// save records the replaced containers and moves the rollout to phase with
+// message, unless it was paused or changed phase in the meantime.
+func (c *Controller) save(
+	ctx context.Context,
+	rollout *entity.Rollout,
+	replaced []uuid.UUID,
+	phase entity.RolloutPhase,
+	message string,
+) error {
+	if len(replaced) == 0 && phase == rollout.Phase && message == rollout.Message {
+		return nil
+	}
+	_, err := c.service.repo.Update(ctx, rollout.ID, func(current *entity.Rollout) error {
+		// Replaced containers are recorded regardless, a rollback has to find them.
+		if rollout.Phase == entity.RolloutPhaseRollingBack {
+			current.Updated = slices.DeleteFunc(current.Updated, func(id uuid.UUID) bool {
+				return slices.Contains(replaced, id)
+			})
+			current.Reverted = append(current.Reverted, replaced...)
+		} else {
+			current.Updated = append(current.Updated, replaced...)
+		}
+		if current.Phase == rollout.Phase && !current.Paused {
+			current.Phase = phase
+			current.Message = message
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,130 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"slices"
+)
+
+type IService interface {
+	GetRollout(ctx context.Context, id uuid.UUID) (*entity.Rollout, error)
+	ListRollouts(ctx context.Context, opts entity.ListOptions) ([]entity.Rollout, string, error)
+	AddRollout(ctx context.Context, req *entity.AddRollout) (*entity.Rollout, error)
+	Pause(ctx context.Context, id uuid.UUID) (*entity.Rollout, error)
+	Resume(ctx context.Context, id uuid.UUID) (*entity.Rollout, error)
+	Rollback(ctx context.Context, id uuid.UUID) (*entity.Rollout, error)
+}
+
+type Service struct {
+	repo             usecase.RolloutRepository
+	containerService container.IService
+}
+
+func NewService(repo usecase.RolloutRepository, containerService container.IService) *Service {
+	return &Service{
+		repo:             repo,
+		containerService: containerService,
+	}
+}
+
+func (s *Service) GetRollout(ctx context.Context, id uuid.UUID) (*entity.Rollout, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// ListRollouts returns one page of rollouts together with the continue token
+// for the next page, which is empty on the last page.
+func (s *Service) ListRollouts(ctx context.Context, opts entity.ListOptions) ([]entity.Rollout, string, error) {
+	return s.repo.List(ctx, opts)
+}
+
+// AddRollout starts a rollout of every container running FromImage, the
+// containers are switched to ToImage by the Controller. Containers that are
+// part of another active rollout are rejected with RolloutConflictErr, the
+// repository enforces it for rollouts started concurrently.
+func (s *Service) AddRollout(ctx context.Context, req *entity.AddRollout) (*entity.Rollout, error) {
+	rollout := entity.NewRollout(req, nil)
+	if err := rollout.Validate(); err != nil {
+		return nil, err
+	}
+
+	containers, _, err := s.containerService.ListContainers(ctx, entity.ListContainersOptions{ImagePrefix: req.FromImage})
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range containers {
+		if target.Image == req.FromImage && target.Status != entity.ContainerStatusTerminating {
+			rollout.Containers = append(rollout.Containers, target.ID)
+		}
+	}
+	if len(rollout.Containers) == 0 {
+		return nil, fmt.Errorf("%w: no container runs image %s", entity.InvalidRolloutErr, req.FromImage)
+	}
+
+	rollouts, _, err := s.repo.List(ctx, entity.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, active := range rollouts {
+		if !active.Phase.IsActive() {
+			continue
+		}
+		if slices.ContainsFunc(rollout.Containers, func(id uuid.UUID) bool { return slices.Contains(active.Containers, id) }) {
+			return nil, fmt.Errorf("%w: rollout %s", usecase.RolloutConflictErr, active.ID)
+		}
+	}
+
+	if err = s.repo.Create(ctx, rollout); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
+// Pause stops an active rollout from switching further containers, the
+// containers switched already are kept.
+func (s *Service) Pause(ctx context.Context, id uuid.UUID) (*entity.Rollout, error) {
+	return s.repo.Update(ctx, id, func(current *entity.Rollout) error {
+		if !current.Phase.IsActive() || current.Paused {
+			return fmt.Errorf("%w: cannot pause a %s rollout", usecase.InvalidRolloutActionErr, describe(current))
+		}
+		current.Paused = true
+		return nil
+	})
+}
+
+// Resume continues a paused rollout.
+func (s *Service) Resume(ctx context.Context, id uuid.UUID) (*entity.Rollout, error) {
+	return s.repo.Update(ctx, id, func(current *entity.Rollout) error {
+		if !current.Paused {
+			return fmt.Errorf("%w: cannot resume a %s rollout", usecase.InvalidRolloutActionErr, describe(current))
+		}
+		current.Paused = false
+		return nil
+	})
+}
+
+// Rollback switches every container the rollout updated back to FromImage.
+// Progressing, failed and completed rollouts can be rolled back.
+func (s *Service) Rollback(ctx context.Context, id uuid.UUID) (*entity.Rollout, error) {
+	return s.repo.Update(ctx, id, func(current *entity.Rollout) error {
+		switch current.Phase {
+		case entity.RolloutPhaseProgressing, entity.RolloutPhaseFailed, entity.RolloutPhaseCompleted:
+		default:
+			return fmt.Errorf("%w: cannot roll back a %s rollout", usecase.InvalidRolloutActionErr, describe(current))
+		}
+		current.Phase = entity.RolloutPhaseRollingBack
+		current.Paused = false
+		current.Message = ""
+		return nil
+	})
+}
+
+func describe(rollout *entity.Rollout) string {
+	if rollout.Paused {
+		return "paused " + string(rollout.Phase)
+	}
+	return string(rollout.Phase)
+}
@@ -0,0 +1,171 @@
+package rollout_test
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/infrastructure/memory"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/container"
+	"github.com/wensiet/morchy-api/internal/usecase/node"
+	"github.com/wensiet/morchy-api/internal/usecase/rollout"
+	"github.com/wensiet/morchy-api/internal/usecase/scheduler"
+	"github.com/wensiet/morchy-api/internal/usecase/watch"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+	"time"
+)
+
+type fixture struct {
+	containerService *container.Service
+	rolloutService   *rollout.Service
+	controller       *rollout.Controller
+	containers       []uuid.UUID
+}
+
+// newFixture starts count running containers of image nginx:1.
+func newFixture(t *testing.T, count int) *fixture {
+	ctx := context.Background()
+	store := memory.NewStore()
+	bus := watch.NewBus(10)
+	nodeService := node.NewService(memory.NewNodeRepository(store), bus)
+	containerService := container.NewService(
+		memory.NewContainerRepository(store),
+		nodeService,
+		&scheduler.LeastLoaded{},
+		bus,
+	)
+	rolloutService := rollout.NewService(memory.NewRolloutRepository(store), containerService)
+	f := &fixture{
+		containerService: containerService,
+		rolloutService:   rolloutService,
+		controller:       rollout.NewController(rolloutService, time.Second),
+	}
+
+	target, err := nodeService.AddNode(ctx, &entity.AddNode{})
+	require.NoError(t, err)
+	for range count {
+		created, err := containerService.AddContainer(ctx, &entity.AddContainer{NodeID: target.ID, Image: "nginx:1"})
+		require.NoError(t, err)
+		f.setStatus(t, created.ID, entity.ContainerStatusCreating, entity.ContainerStatusRunning)
+		f.containers = append(f.containers, created.ID)
+	}
+	return f
+}
+
+// setStatus reports the statuses of the container like its node would.
+func (f *fixture) setStatus(t *testing.T, id uuid.UUID, statuses ...entity.ContainerStatus) {
+	t.Helper()
+	for _, status := range statuses {
+		current, err := f.containerService.GetContainer(context.Background(), id)
+		require.NoError(t, err)
+		current.Status = status
+		require.NoError(t, f.containerService.UpdateContainer(context.Background(), current))
+	}
+}
+
+func (f *fixture) reconcile(t *testing.T, id uuid.UUID) *entity.Rollout {
+	t.Helper()
+	require.NoError(t, f.controller.Reconcile(context.Background()))
+	current, err := f.rolloutService.GetRollout(context.Background(), id)
+	require.NoError(t, err)
+	return current
+}
+
+func TestRolloutSwitchesContainersInBatches(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, 3)
+
+	_, err := f.rolloutService.AddRollout(ctx, &entity.AddRollout{FromImage: "nginx:0", ToImage: "nginx:2"})
+	assert.ErrorIs(t, err, entity.InvalidRolloutErr)
+
+	started, err := f.rolloutService.AddRollout(ctx, &entity.AddRollout{FromImage: "nginx:1", ToImage: "nginx:2", BatchSize: 2})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, f.containers, started.Containers)
+	assert.Equal(t, 2, started.MaxUnavailable)
+
+	_, err = f.rolloutService.AddRollout(ctx, &entity.AddRollout{FromImage: "nginx:1", ToImage: "nginx:3"})
+	assert.ErrorIs(t, err, usecase.RolloutConflictErr)
+
+	current := f.reconcile(t, started.ID)
+	require.Len(t, current.Updated, 2)
+	for _, id := range current.Updated {
+		switched, err := f.containerService.GetContainer(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "nginx:2", switched.Image)
+		assert.Equal(t, entity.ContainerStatusPending, switched.Status)
+	}
+
+	current = f.reconcile(t, started.ID)
+	assert.Len(t, current.Updated, 2, "the next batch waits for the previous one")
+	assert.Contains(t, current.Message, "waiting for 2 container(s)")
+
+	_, err = f.rolloutService.Pause(ctx, started.ID)
+	require.NoError(t, err)
+	for _, id := range current.Updated {
+		f.setStatus(t, id, entity.ContainerStatusCreating, entity.ContainerStatusRunning)
+	}
+	current = f.reconcile(t, started.ID)
+	assert.Len(t, current.Updated, 2, "paused rollouts do not progress")
+
+	_, err = f.rolloutService.Resume(ctx, started.ID)
+	require.NoError(t, err)
+	current = f.reconcile(t, started.ID)
+	require.Len(t, current.Updated, 3)
+	f.setStatus(t, current.Updated[2], entity.ContainerStatusCreating, entity.ContainerStatusRunning)
+
+	current = f.reconcile(t, started.ID)
+	assert.Equal(t, entity.RolloutPhaseCompleted, current.Phase)
+	assert.Empty(t, current.Message)
+	_, err = f.rolloutService.Pause(ctx, started.ID)
+	assert.ErrorIs(t, err, usecase.InvalidRolloutActionErr)
+}
+
+func TestRolloutHaltsAndRollsBack(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, 2)
+
+	started, err := f.rolloutService.AddRollout(ctx, &entity.AddRollout{FromImage: "nginx:1", ToImage: "nginx:broken"})
+	require.NoError(t, err)
+	current := f.reconcile(t, started.ID)
+	require.Len(t, current.Updated, 1)
+	broken := current.Updated[0]
+
+	f.setStatus(t, broken, entity.ContainerStatusCreating, entity.ContainerStatusFailed)
+	current = f.reconcile(t, started.ID)
+	assert.Equal(t, entity.RolloutPhaseFailed, current.Phase)
+	assert.Contains(t, current.Message, broken.String())
+	current = f.reconcile(t, started.ID)
+	assert.Len(t, current.Updated, 1, "failed rollouts do not progress")
+
+	_, err = f.rolloutService.Resume(ctx, started.ID)
+	assert.ErrorIs(t, err, usecase.InvalidRolloutActionErr)
+	_, err = f.rolloutService.Rollback(ctx, started.ID)
+	require.NoError(t, err)
+
+	current = f.reconcile(t, started.ID)
+	assert.Equal(t, []uuid.UUID{broken}, current.Reverted)
+	assert.Empty(t, current.Updated)
+	reverted, err := f.containerService.GetContainer(ctx, broken)
+	require.NoError(t, err)
+	assert.Equal(t, "nginx:1", reverted.Image)
+	assert.Equal(t, entity.ContainerStatusPending, reverted.Status)
+
+	current = f.reconcile(t, started.ID)
+	assert.Equal(t, entity.RolloutPhaseRollingBack, current.Phase)
+	f.setStatus(t, broken, entity.ContainerStatusCreating, entity.ContainerStatusRunning)
+	current = f.reconcile(t, started.ID)
+	assert.Equal(t, entity.RolloutPhaseRolledBack, current.Phase)
+
+	events, err := f.containerService.ListEvents(ctx, broken)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, entity.EventReasonImageReplaced, events[1].Reason)
+	assert.Equal(t, "image changed from nginx:broken to nginx:1", events[1].Message)
+
+	_, err = f.rolloutService.Rollback(ctx, started.ID)
+	assert.ErrorIs(t, err, usecase.InvalidRolloutActionErr)
+	_, err = f.rolloutService.Pause(ctx, uuid.New())
+	assert.ErrorIs(t, err, usecase.RolloutNotFoundErr)
+}
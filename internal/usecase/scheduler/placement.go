@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"fmt"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"slices"
+	"strings"
+)
+
+// Constraints are the parsed placement rules of a container. A nil
+// *Constraints places no restrictions on the node.
+type Constraints struct {
+	nodeSelector entity.Labels
+	nodeAffinity entity.LabelSelector
+	// nodeAffinityText is the node affinity as it was requested.
+	nodeAffinityText string
+	affinity         []containerTerm
+	antiAffinity     []containerTerm
+}
+
+type containerTerm struct {
+	image    string
+	selector entity.LabelSelector
+	text     string
+}
+
+// NewConstraints validates placement, errors wrap usecase.InvalidPlacementErr.
+func NewConstraints(placement entity.Placement) (*Constraints, error) {
+	if err := placement.NodeSelector.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: node_selector: %s", usecase.InvalidPlacementErr, err)
+	}
+	nodeAffinity, err := entity.ParseLabelSelector(placement.NodeAffinity)
+	if err != nil {
+		return nil, fmt.Errorf("%w: node_affinity: %s", usecase.InvalidPlacementErr, err)
+	}
+
+	c := &Constraints{
+		nodeSelector:     placement.NodeSelector,
+		nodeAffinity:     nodeAffinity,
+		nodeAffinityText: placement.NodeAffinity,
+	}
+	if c.affinity, err = newContainerTerms("affinity", placement.Affinity); err != nil {
+		return nil, err
+	}
+	if c.antiAffinity, err = newContainerTerms("anti_affinity", placement.AntiAffinity); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func newContainerTerms(field string, terms []entity.ContainerAffinityTerm) ([]containerTerm, error) {
+	var result []containerTerm
+	for i, term := range terms {
+		if term.Image == "" && term.LabelSelector == "" {
+			return nil, fmt.Errorf("%w: %s[%d] needs an image or a label_selector", usecase.InvalidPlacementErr, field, i)
+		}
+		selector, err := entity.ParseLabelSelector(term.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s[%d]: %s", usecase.InvalidPlacementErr, field, i, err)
+		}
+
+		var text []string
+		if term.Image != "" {
+			text = append(text, "image "+term.Image)
+		}
+		if term.LabelSelector != "" {
+			text = append(text, fmt.Sprintf("labels %q", term.LabelSelector))
+		}
+		result = append(result, containerTerm{
+			image:    term.Image,
+			selector: selector,
+			text:     strings.Join(text, " and "),
+		})
+	}
+	return result, nil
+}
+
+func (t containerTerm) matchesAny(containers []entity.Container) bool {
+	return slices.ContainsFunc(containers, func(container entity.Container) bool {
+		return (t.image == "" || container.Image == t.image) && t.selector.Matches(container.Labels)
+	})
+}
+
+// Check returns why the node satisfies the constraints, or an error wrapping
+// usecase.PlacementConstraintErr naming the first rule the node violates.
+func (c *Constraints) Check(node *entity.Node) ([]string, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	var reasons []string
+	if len(c.nodeSelector) > 0 {
+		keys := make([]string, 0, len(c.nodeSelector))
+		for key := range c.nodeSelector {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = key + "=" + c.nodeSelector[key]
+			if value, ok := node.Labels[key]; !ok || value != c.nodeSelector[key] {
+				return nil, fmt.Errorf("%w: node %s lacks label %s", usecase.PlacementConstraintErr, node.ID, pairs[i])
+			}
+		}
+		reasons = append(reasons, "node has labels "+strings.Join(pairs, ","))
+	}
+	if len(c.nodeAffinity) > 0 {
+		if !c.nodeAffinity.Matches(node.Labels) {
+			return nil, fmt.Errorf(
+				"%w: node %s labels do not match node affinity %q",
+				usecase.PlacementConstraintErr, node.ID, c.nodeAffinityText,
+			)
+		}
+		reasons = append(reasons, fmt.Sprintf("node labels match node affinity %q", c.nodeAffinityText))
+	}
+	for _, term := range c.affinity {
+		if !term.matchesAny(node.Containers) {
+			return nil, fmt.Errorf("%w: node %s runs no container with %s", usecase.PlacementConstraintErr, node.ID, term.text)
+		}
+		reasons = append(reasons, "affinity: node runs a container with "+term.text)
+	}
+	for _, term := range c.antiAffinity {
+		if term.matchesAny(node.Containers) {
+			return nil, fmt.Errorf(
+				"%w: node %s already runs a container with %s",
+				usecase.PlacementConstraintErr, node.ID, term.text,
+			)
+		}
+		reasons = append(reasons, "anti-affinity: node runs no container with "+term.text)
+	}
+	return reasons, nil
+}
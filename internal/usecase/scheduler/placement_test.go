@@ -0,0 +1,82 @@
+package scheduler_test
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/scheduler"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+)
+
+func TestNewConstraints_Invalid(t *testing.T) {
+	placements := []entity.Placement{
+		{NodeSelector: entity.Labels{"bad key": "x"}},
+		{NodeAffinity: "zone in eu"},
+		{Affinity: []entity.ContainerAffinityTerm{{}}},
+		{AntiAffinity: []entity.ContainerAffinityTerm{{LabelSelector: "=web"}}},
+	}
+	for _, placement := range placements {
+		_, err := scheduler.NewConstraints(placement)
+		assert.ErrorIs(t, err, usecase.InvalidPlacementErr)
+	}
+}
+
+func TestConstraints_Check(t *testing.T) {
+	node := newNode(entity.RunningNodeStatus, "redis")
+	node.Labels = entity.Labels{"zone": "eu", "disk": "ssd"}
+	node.Containers[0].Labels = entity.Labels{"app": "cache"}
+
+	constraints, err := scheduler.NewConstraints(entity.Placement{
+		NodeSelector: entity.Labels{"zone": "eu"},
+		NodeAffinity: "disk in (ssd,nvme)",
+		Affinity:     []entity.ContainerAffinityTerm{{LabelSelector: "app=cache"}},
+		AntiAffinity: []entity.ContainerAffinityTerm{{Image: "nginx"}},
+	})
+	require.NoError(t, err)
+
+	reasons, err := constraints.Check(node)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"node has labels zone=eu",
+		`node labels match node affinity "disk in (ssd,nvme)"`,
+		`affinity: node runs a container with labels "app=cache"`,
+		"anti-affinity: node runs no container with image nginx",
+	}, reasons)
+
+	node.Containers = append(node.Containers, *entity.NewContainer(node.ID, "nginx"))
+	_, err = constraints.Check(node)
+	assert.ErrorIs(t, err, usecase.PlacementConstraintErr)
+
+	node.Labels["zone"] = "us"
+	_, err = constraints.Check(node)
+	assert.ErrorIs(t, err, usecase.PlacementConstraintErr)
+}
+
+func TestSchedule_AntiAffinity(t *testing.T) {
+	withNginx := newNode(entity.RunningNodeStatus, "nginx")
+	withRedis := newNode(entity.RunningNodeStatus, "redis", "postgres")
+	constraints, err := scheduler.NewConstraints(entity.Placement{
+		AntiAffinity: []entity.ContainerAffinityTerm{{Image: "nginx"}},
+	})
+	require.NoError(t, err)
+
+	node, reasons, err := scheduler.Schedule(
+		scheduler.LeastLoaded{},
+		[]*entity.Node{withNginx, withRedis},
+		entity.NewContainer(uuid.Nil, "nginx"),
+		constraints,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, withRedis.ID, node.ID)
+	assert.Contains(t, reasons, "anti-affinity: node runs no container with image nginx")
+
+	_, _, err = scheduler.Schedule(
+		scheduler.LeastLoaded{},
+		[]*entity.Node{withNginx},
+		entity.NewContainer(uuid.Nil, "nginx"),
+		constraints,
+	)
+	assert.ErrorIs(t, err, usecase.NoEligibleNodeErr)
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"fmt"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"math/rand/v2"
+)
+
+const (
+	LeastLoadedStrategy = "least-loaded"
+	SpreadStrategy      = "spread"
+	RandomStrategy      = "random"
+)
+
+// Scheduler picks a node for a container out of a non-empty list of eligible nodes.
+type Scheduler interface {
+	Select(nodes []*entity.Node, container *entity.Container) *entity.Node
+}
+
+func New(strategy string) (Scheduler, error) {
+	switch strategy {
+	case LeastLoadedStrategy:
+		return LeastLoaded{}, nil
+	case SpreadStrategy:
+		return Spread{}, nil
+	case RandomStrategy:
+		return Random{}, nil
+	default:
+		return nil, usecase.UnknownSchedulerErr
+	}
+}
+
+// Schedule filters out nodes that cannot accept the container, violate
+// constraints or carry taints the container does not tolerate and lets s
+// choose among the rest, avoiding nodes with untolerated PreferNoSchedule
+// taints while others are left. It returns the chosen node together with the
+// reasons it was placed there.
+func Schedule(
+	s Scheduler,
+	nodes []*entity.Node,
+	container *entity.Container,
+	constraints *Constraints,
+) (*entity.Node, []string, error) {
+	var eligible, avoided []*entity.Node
+	reasons := make(map[*entity.Node][]string)
+	rejected := 0
+	for _, node := range nodes {
+		if !IsEligible(node, container) {
+			continue
+		}
+		taintReasons, err := CheckTaints(node, container)
+		if err != nil {
+			rejected++
+			continue
+		}
+		nodeReasons, err := constraints.Check(node)
+		if err != nil {
+			rejected++
+			continue
+		}
+		reasons[node] = append(nodeReasons, taintReasons...)
+		if prefersAvoiding(node, container) {
+			avoided = append(avoided, node)
+		} else {
+			eligible = append(eligible, node)
+		}
+	}
+
+	var fallback []string
+	if len(eligible) == 0 && len(avoided) > 0 {
+		eligible = avoided
+		fallback = []string{"every eligible node has a PreferNoSchedule taint the container does not tolerate"}
+	}
+	if len(eligible) == 0 {
+		if rejected > 0 {
+			return nil, nil, fmt.Errorf(
+				"%w: placement rules or taints reject all %d running node(s) with enough capacity",
+				usecase.NoEligibleNodeErr, rejected,
+			)
+		}
+		return nil, nil, usecase.NoEligibleNodeErr
+	}
+
+	target := s.Select(eligible, container)
+	result := append([]string{
+		fmt.Sprintf("chosen by the scheduler out of %d eligible node(s)", len(eligible)),
+		"node is running, schedulable and has enough free capacity",
+	}, reasons[target]...)
+	return target, append(result, fallback...), nil
+}
+
+// IsEligible reports whether the container may be placed on the node.
+func IsEligible(node *entity.Node, container *entity.Container) bool {
+	return node.Status == entity.RunningNodeStatus && !node.Unschedulable && node.CanFit(container.Resources)
+}
+
+// LeastLoaded picks the node running the fewest containers.
+type LeastLoaded struct{}
+
+func (LeastLoaded) Select(nodes []*entity.Node, _ *entity.Container) *entity.Node {
+	best := nodes[0]
+	for _, node := range nodes[1:] {
+		if len(node.Containers) < len(best.Containers) {
+			best = node
+		}
+	}
+	return best
+}
+
+// Spread picks the node running the fewest containers with the same image,
+// falling back to the overall container count on ties.
+type Spread struct{}
+
+func (Spread) Select(nodes []*entity.Node, container *entity.Container) *entity.Node {
+	best := nodes[0]
+	bestSame := countImage(best, container.Image)
+	for _, node := range nodes[1:] {
+		same := countImage(node, container.Image)
+		if same < bestSame || (same == bestSame && len(node.Containers) < len(best.Containers)) {
+			best, bestSame = node, same
+		}
+	}
+	return best
+}
+
+// Random picks any of the nodes with equal probability.
+type Random struct{}
+
+func (Random) Select(nodes []*entity.Node, _ *entity.Container) *entity.Node {
+	return nodes[rand.IntN(len(nodes))]
+}
+
+func countImage(node *entity.Node, image string) int {
+	count := 0
+	for _, container := range node.Containers {
+		if container.Image == image {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,115 @@
+package scheduler_test
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/scheduler"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+)
+
+func newNode(status entity.NodeStatus, images ...string) *entity.Node {
+	node := entity.NewNode(entity.Resources{})
+	node.Status = status
+	for _, image := range images {
+		node.Containers = append(node.Containers, *entity.NewContainer(node.ID, image))
+	}
+	return node
+}
+
+func TestSchedule_NoEligibleNode(t *testing.T) {
+	cordoned := newNode(entity.RunningNodeStatus)
+	cordoned.Unschedulable = true
+	nodes := []*entity.Node{
+		newNode(entity.NewNodeStatus),
+		newNode(entity.FailedNodeStatus),
+		cordoned,
+	}
+	_, _, err := scheduler.Schedule(scheduler.LeastLoaded{}, nodes, entity.NewContainer(uuid.Nil, "nginx"), nil)
+	assert.ErrorIs(t, err, usecase.NoEligibleNodeErr)
+}
+
+func TestSchedule_LeastLoaded(t *testing.T) {
+	busy := newNode(entity.RunningNodeStatus, "nginx", "redis")
+	idle := newNode(entity.RunningNodeStatus, "nginx")
+	failed := newNode(entity.FailedNodeStatus)
+
+	node, _, err := scheduler.Schedule(
+		scheduler.LeastLoaded{},
+		[]*entity.Node{busy, failed, idle},
+		entity.NewContainer(uuid.Nil, "nginx"),
+		nil,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, idle.ID, node.ID)
+}
+
+func TestSchedule_Spread(t *testing.T) {
+	sameImage := newNode(entity.RunningNodeStatus, "nginx")
+	otherImages := newNode(entity.RunningNodeStatus, "redis", "postgres")
+
+	node, _, err := scheduler.Schedule(
+		scheduler.Spread{},
+		[]*entity.Node{sameImage, otherImages},
+		entity.NewContainer(uuid.Nil, "nginx"),
+		nil,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, otherImages.ID, node.ID)
+}
+
+func TestSchedule_SkipsFullNodes(t *testing.T) {
+	full := newNode(entity.RunningNodeStatus, "nginx")
+	full.Capacity = entity.Resources{Memory: 1024}
+	full.Containers[0].Resources = entity.Resources{Memory: 1000}
+	roomy := newNode(entity.RunningNodeStatus, "nginx", "redis")
+	roomy.Capacity = entity.Resources{Memory: 4096}
+
+	container := entity.NewContainer(uuid.Nil, "nginx")
+	container.Resources = entity.Resources{Memory: 512}
+
+	node, _, err := scheduler.Schedule(scheduler.LeastLoaded{}, []*entity.Node{full, roomy}, container, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, roomy.ID, node.ID)
+
+	container.Resources = entity.Resources{Memory: 8192}
+	_, _, err = scheduler.Schedule(scheduler.LeastLoaded{}, []*entity.Node{full, roomy}, container, nil)
+	assert.ErrorIs(t, err, usecase.NoEligibleNodeErr)
+}
+
+func TestNew(t *testing.T) {
+	for _, strategy := range []string{scheduler.LeastLoadedStrategy, scheduler.SpreadStrategy, scheduler.RandomStrategy} {
+		_, err := scheduler.New(strategy)
+		assert.NoError(t, err)
+	}
+	_, err := scheduler.New("round-robin")
+	assert.ErrorIs(t, err, usecase.UnknownSchedulerErr)
+}
+
+func TestSchedule_Taints(t *testing.T) {
+	tainted := newNode(entity.RunningNodeStatus)
+	tainted.Taints = []entity.Taint{{Key: "gpu", Effect: entity.TaintEffectNoSchedule}}
+	avoided := newNode(entity.RunningNodeStatus, "nginx", "redis")
+	avoided.Taints = []entity.Taint{{Key: "spot", Effect: entity.TaintEffectPreferNoSchedule}}
+	plain := newNode(entity.RunningNodeStatus, "nginx", "redis", "postgres")
+
+	container := entity.NewContainer(uuid.Nil, "nginx")
+	node, _, err := scheduler.Schedule(scheduler.LeastLoaded{}, []*entity.Node{tainted, avoided, plain}, container, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, plain.ID, node.ID)
+
+	node, reasons, err := scheduler.Schedule(scheduler.LeastLoaded{}, []*entity.Node{tainted, avoided}, container, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, avoided.ID, node.ID)
+	assert.Contains(t, reasons, "every eligible node has a PreferNoSchedule taint the container does not tolerate")
+
+	_, _, err = scheduler.Schedule(scheduler.LeastLoaded{}, []*entity.Node{tainted}, container, nil)
+	assert.ErrorIs(t, err, usecase.NoEligibleNodeErr)
+
+	container.Spec.Tolerations = []entity.Toleration{{Key: "gpu", Operator: entity.TolerationOpExists}}
+	node, reasons, err = scheduler.Schedule(scheduler.LeastLoaded{}, []*entity.Node{tainted, avoided, plain}, container, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, tainted.ID, node.ID)
+	assert.Contains(t, reasons, "container tolerates taint gpu:NoSchedule")
+}
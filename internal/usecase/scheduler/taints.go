@@ -0,0 +1,35 @@
+package scheduler
+
+import (
+	"fmt"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+)
+
+// CheckTaints returns why the container may run on the node despite its
+// NoSchedule and NoExecute taints, or an error wrapping
+// usecase.PlacementConstraintErr naming the first taint it does not tolerate.
+func CheckTaints(node *entity.Node, container *entity.Container) ([]string, error) {
+	untolerated := entity.UntoleratedTaints(
+		node.Taints, container.Spec.Tolerations,
+		entity.TaintEffectNoSchedule, entity.TaintEffectNoExecute,
+	)
+	if len(untolerated) > 0 {
+		return nil, fmt.Errorf("%w: node %s has taint %s", usecase.PlacementConstraintErr, node.ID, untolerated[0])
+	}
+
+	var reasons []string
+	for _, taint := range node.Taints {
+		if taint.Effect != entity.TaintEffectPreferNoSchedule {
+			reasons = append(reasons, "container tolerates taint "+taint.String())
+		}
+	}
+	return reasons, nil
+}
+
+// prefersAvoiding reports whether the node has a PreferNoSchedule taint the container does not tolerate.
+func prefersAvoiding(node *entity.Node, container *entity.Container) bool {
+	return len(entity.UntoleratedTaints(
+		node.Taints, container.Spec.Tolerations, entity.TaintEffectPreferNoSchedule,
+	)) > 0
+}
@@ -0,0 +1,152 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"sync"
+)
+
+type Kind string
+
+const (
+	NodeKind      Kind = "node"
+	ContainerKind Kind = "container"
+)
+
+// subscriberBuffer is how many events a subscriber may lag behind before it is dropped.
+const subscriberBuffer = 64
+
+type event struct {
+	kind Kind
+	entity.WatchEvent
+}
+
+// Bus is an in-process event bus. Every published event gets the next
+// resource version, and the latest events are kept so that watchers can
+// resume from a resource version they have already seen.
+type Bus struct {
+	mu          sync.Mutex
+	version     uint64
+	history     []event
+	historySize int
+	subscribers map[*Subscription]struct{}
+}
+
+func NewBus(historySize int) *Bus {
+	return &Bus{
+		historySize: historySize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish stores the event and fans it out to the subscribers of kind.
+//
+// Services publish after their change was committed, so events of
+// concurrent changes may be published in a different order than the changes
+// were committed. Every object carries its own resource_version, watchers
+// keep the object with the highest one when two events of it arrive out of
+// order.
+func (b *Bus) Publish(kind Kind, eventType entity.WatchEventType, object interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.version++
+	ev := event{
+		kind: kind,
+		WatchEvent: entity.WatchEvent{
+			Type:            eventType,
+			ResourceVersion: b.version,
+			Object:          object,
+		},
+	}
+
+	b.history = append(b.history, ev)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for sub := range b.subscribers {
+		if sub.kind != kind {
+			continue
+		}
+		select {
+		case sub.events <- ev.WatchEvent:
+		default:
+			// The subscriber fell too far behind, it has to resume from its last version.
+			b.unsubscribe(sub)
+		}
+	}
+}
+
+// Subscribe returns a subscription to events of kind published after
+// resourceVersion, or to new events only when resourceVersion is zero.
+// A resourceVersion the bus cannot replay from fails with
+// ResourceVersionTooOldErr, both when its events were dropped from the
+// history and when it is ahead of the bus, like after a restart of the API
+// reset the versions. The subscription is closed when ctx is done.
+func (b *Bus) Subscribe(ctx context.Context, kind Kind, resourceVersion uint64) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if resourceVersion > b.version {
+		return nil, fmt.Errorf("%w: %d is ahead of the latest version %d, the history was reset",
+			usecase.ResourceVersionTooOldErr, resourceVersion, b.version)
+	}
+	var replay []entity.WatchEvent
+	if resourceVersion != 0 && resourceVersion < b.version {
+		oldest := b.version - uint64(len(b.history)) + 1
+		if resourceVersion+1 < oldest {
+			return nil, usecase.ResourceVersionTooOldErr
+		}
+		for _, ev := range b.history {
+			if ev.kind == kind && ev.ResourceVersion > resourceVersion {
+				replay = append(replay, ev.WatchEvent)
+			}
+		}
+	}
+
+	sub := &Subscription{
+		kind:   kind,
+		events: make(chan entity.WatchEvent, len(replay)+subscriberBuffer),
+	}
+	for _, ev := range replay {
+		sub.events <- ev
+	}
+	b.subscribers[sub] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.unsubscribe(sub)
+	}()
+
+	return sub, nil
+}
+
+// Version returns the resource version of the latest published event.
+func (b *Bus) Version() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.version
+}
+
+func (b *Bus) unsubscribe(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.events)
+}
+
+type Subscription struct {
+	kind   Kind
+	events chan entity.WatchEvent
+}
+
+// Events returns the channel of events, it is closed when the subscription ends.
+func (s *Subscription) Events() <-chan entity.WatchEvent {
+	return s.events
+}
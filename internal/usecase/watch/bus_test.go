@@ -0,0 +1,70 @@
+package watch_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/wensiet/morchy-api/internal/usecase"
+	"github.com/wensiet/morchy-api/internal/usecase/watch"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+)
+
+func TestBus_Subscribe(t *testing.T) {
+	bus := watch.NewBus(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub, err := bus.Subscribe(ctx, watch.NodeKind, 0)
+	assert.NoError(t, err)
+
+	bus.Publish(watch.ContainerKind, entity.WatchEventAdded, "container")
+	bus.Publish(watch.NodeKind, entity.WatchEventAdded, "node")
+
+	event := <-sub.Events()
+	assert.Equal(t, entity.WatchEventAdded, event.Type)
+	assert.Equal(t, uint64(2), event.ResourceVersion)
+	assert.Equal(t, "node", event.Object)
+
+	cancel()
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+}
+
+func TestBus_Resume(t *testing.T) {
+	bus := watch.NewBus(3)
+	for i := 0; i < 5; i++ {
+		bus.Publish(watch.NodeKind, entity.WatchEventModified, i)
+	}
+
+	sub, err := bus.Subscribe(context.Background(), watch.NodeKind, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), (<-sub.Events()).ResourceVersion)
+	assert.Equal(t, uint64(5), (<-sub.Events()).ResourceVersion)
+
+	_, err = bus.Subscribe(context.Background(), watch.NodeKind, 2)
+	assert.NoError(t, err)
+
+	_, err = bus.Subscribe(context.Background(), watch.NodeKind, 1)
+	assert.ErrorIs(t, err, usecase.ResourceVersionTooOldErr)
+
+	_, err = bus.Subscribe(context.Background(), watch.NodeKind, 5)
+	assert.NoError(t, err)
+	_, err = bus.Subscribe(context.Background(), watch.NodeKind, 6)
+	assert.ErrorIs(t, err, usecase.ResourceVersionTooOldErr, "versions from before a restart are not accepted")
+}
+
+func TestBus_DropsSlowSubscriber(t *testing.T) {
+	bus := watch.NewBus(1000)
+	sub, err := bus.Subscribe(context.Background(), watch.NodeKind, 0)
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		bus.Publish(watch.NodeKind, entity.WatchEventModified, i)
+	}
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	assert.Less(t, received, 100)
+}
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE node
+    DROP COLUMN last_seen;
+
+COMMIT;
\ No newline at end of file
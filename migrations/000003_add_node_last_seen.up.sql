@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE node
+    ADD COLUMN last_seen TIMESTAMPTZ;
+
+COMMIT;
\ No newline at end of file
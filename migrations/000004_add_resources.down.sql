@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE container
+    DROP COLUMN cpu_request,
+    DROP COLUMN memory_request,
+    DROP COLUMN disk_request;
+
+ALTER TABLE node
+    DROP COLUMN cpu_capacity,
+    DROP COLUMN memory_capacity,
+    DROP COLUMN disk_capacity;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE node
+    ADD COLUMN cpu_capacity    BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN memory_capacity BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN disk_capacity   BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE container
+    ADD COLUMN cpu_request    BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN memory_request BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN disk_request   BIGINT NOT NULL DEFAULT 0;
+
+COMMIT;
\ No newline at end of file
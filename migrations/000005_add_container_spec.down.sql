@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE container
+    DROP COLUMN spec;
+
+COMMIT;
\ No newline at end of file
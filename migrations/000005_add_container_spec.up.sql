@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE container
+    ADD COLUMN spec JSONB NOT NULL DEFAULT '{}';
+
+COMMIT;
\ No newline at end of file
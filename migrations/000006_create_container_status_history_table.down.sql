@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX container_status_history__container_id__changed_at;
+DROP TABLE container_status_history;
+
+COMMIT;
\ No newline at end of file
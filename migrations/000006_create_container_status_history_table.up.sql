@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE container_status_history
+(
+    id           BIGSERIAL PRIMARY KEY,
+    container_id VARCHAR(36) NOT NULL,
+    from_status  VARCHAR(12),
+    to_status    VARCHAR(12) NOT NULL,
+    changed_at   TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (container_id) REFERENCES container (id) ON DELETE CASCADE
+);
+
+CREATE INDEX container_status_history__container_id__changed_at ON container_status_history (container_id, changed_at);
+
+COMMIT;
\ No newline at end of file
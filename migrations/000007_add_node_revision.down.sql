@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE node
+    DROP COLUMN revision;
+
+COMMIT;
\ No newline at end of file
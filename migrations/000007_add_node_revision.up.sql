@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE node
+    ADD COLUMN revision BIGINT NOT NULL DEFAULT 0;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP INDEX container__labels;
+DROP INDEX node__labels;
+
+ALTER TABLE container
+    DROP COLUMN labels;
+
+ALTER TABLE node
+    DROP COLUMN labels;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE node
+    ADD COLUMN labels JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE container
+    ADD COLUMN labels JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX node__labels ON node USING GIN (labels);
+CREATE INDEX container__labels ON container USING GIN (labels);
+
+COMMIT;
\ No newline at end of file
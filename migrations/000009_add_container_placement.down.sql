@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE container
+    DROP COLUMN placement,
+    DROP COLUMN placement_reasons;
+
+COMMIT;
\ No newline at end of file
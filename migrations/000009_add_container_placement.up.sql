@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE container
+    ADD COLUMN placement         JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN placement_reasons JSONB NOT NULL DEFAULT '[]';
+
+COMMIT;
\ No newline at end of file
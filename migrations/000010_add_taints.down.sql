@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE container
+    DROP COLUMN reschedule_reason;
+
+ALTER TABLE node
+    DROP COLUMN taints;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE node
+    ADD COLUMN taints JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE container
+    ADD COLUMN reschedule_reason TEXT NOT NULL DEFAULT '';
+
+COMMIT;
\ No newline at end of file
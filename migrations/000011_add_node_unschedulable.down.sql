@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE node
+    DROP COLUMN unschedulable;
+
+COMMIT;
\ No newline at end of file
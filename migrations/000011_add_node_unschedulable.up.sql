@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE node
+    ADD COLUMN unschedulable BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMIT;
\ No newline at end of file
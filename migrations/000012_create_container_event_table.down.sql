@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX container_event__container_id__created_at;
+DROP TABLE container_event;
+
+COMMIT;
\ No newline at end of file
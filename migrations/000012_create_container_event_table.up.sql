@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE container_event
+(
+    id           BIGSERIAL PRIMARY KEY,
+    container_id VARCHAR(36) NOT NULL,
+    reason       VARCHAR(64) NOT NULL,
+    message      TEXT        NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (container_id) REFERENCES container (id) ON DELETE CASCADE
+);
+
+CREATE INDEX container_event__container_id__created_at ON container_event (container_id, created_at);
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP INDEX container__owner_id;
+
+ALTER TABLE container
+    DROP COLUMN owner_kind,
+    DROP COLUMN owner_id;
+
+DROP TABLE deployment;
+
+COMMIT;
\ No newline at end of file
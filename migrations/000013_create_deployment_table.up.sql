@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE deployment
+(
+    id       VARCHAR(36) PRIMARY KEY,
+    name     VARCHAR(63) NOT NULL UNIQUE,
+    replicas INTEGER     NOT NULL,
+    template JSONB       NOT NULL,
+    status   JSONB       NOT NULL DEFAULT '{}'
+);
+
+ALTER TABLE container
+    ADD COLUMN owner_kind VARCHAR(32),
+    ADD COLUMN owner_id   VARCHAR(36);
+
+CREATE INDEX container__owner_id ON container (owner_id);
+
+COMMIT;
\ No newline at end of file
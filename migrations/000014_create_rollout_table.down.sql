@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE rollout;
+
+COMMIT;
\ No newline at end of file
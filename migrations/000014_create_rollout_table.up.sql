@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE rollout
+(
+    id              VARCHAR(36) PRIMARY KEY,
+    from_image      TEXT        NOT NULL,
+    to_image        TEXT        NOT NULL,
+    batch_size      INTEGER     NOT NULL,
+    max_unavailable INTEGER     NOT NULL,
+    phase           VARCHAR(32) NOT NULL,
+    paused          BOOLEAN     NOT NULL DEFAULT FALSE,
+    message         TEXT        NOT NULL DEFAULT '',
+    containers      JSONB       NOT NULL DEFAULT '[]',
+    updated         JSONB       NOT NULL DEFAULT '[]',
+    reverted        JSONB       NOT NULL DEFAULT '[]',
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+COMMIT;
\ No newline at end of file
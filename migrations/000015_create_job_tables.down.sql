@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP TABLE cronjob;
+
+DROP INDEX job__owner_id;
+
+DROP TABLE job;
+
+ALTER TABLE container
+    DROP COLUMN exit_code;
+
+COMMIT;
\ No newline at end of file
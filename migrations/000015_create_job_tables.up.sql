@@ -0,0 +1,33 @@
+BEGIN;
+
+ALTER TABLE container
+    ADD COLUMN exit_code INTEGER;
+
+CREATE TABLE job
+(
+    id         VARCHAR(36) PRIMARY KEY,
+    name       VARCHAR(63) NOT NULL UNIQUE,
+    spec       JSONB       NOT NULL,
+    status     JSONB       NOT NULL,
+    owner_kind VARCHAR(32),
+    owner_id   VARCHAR(36),
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX job__owner_id ON job (owner_id);
+
+CREATE TABLE cronjob
+(
+    id                            VARCHAR(36) PRIMARY KEY,
+    name                          VARCHAR(52) NOT NULL UNIQUE,
+    schedule                      TEXT        NOT NULL,
+    concurrency_policy            VARCHAR(16) NOT NULL,
+    suspend                       BOOLEAN     NOT NULL DEFAULT FALSE,
+    successful_jobs_history_limit INTEGER     NOT NULL,
+    failed_jobs_history_limit     INTEGER     NOT NULL,
+    job_template                  JSONB       NOT NULL,
+    status                        JSONB       NOT NULL,
+    created_at                    TIMESTAMPTZ NOT NULL
+);
+
+COMMIT;
\ No newline at end of file
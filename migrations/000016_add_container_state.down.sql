@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE container
+    ADD COLUMN exit_code INTEGER;
+
+UPDATE container
+SET exit_code = (state ->> 'exit_code')::INTEGER;
+
+ALTER TABLE container
+    DROP COLUMN state;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE container
+    ADD COLUMN state JSONB NOT NULL DEFAULT '{}';
+
+UPDATE container
+SET state = jsonb_build_object('exit_code', exit_code)
+WHERE exit_code IS NOT NULL;
+
+ALTER TABLE container
+    DROP COLUMN exit_code;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX container_log__logged_at;
+DROP INDEX container_log__container_id__seq;
+DROP TABLE container_log;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE container_log
+(
+    seq          BIGSERIAL PRIMARY KEY,
+    container_id VARCHAR(36) NOT NULL,
+    stream       VARCHAR(8)  NOT NULL,
+    logged_at    TIMESTAMPTZ NOT NULL,
+    line         TEXT        NOT NULL,
+    FOREIGN KEY (container_id) REFERENCES container (id) ON DELETE CASCADE
+);
+
+CREATE INDEX container_log__container_id__seq ON container_log (container_id, seq);
+
+CREATE INDEX container_log__logged_at ON container_log (logged_at);
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE container
+    DROP COLUMN conditions;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,25 @@
+BEGIN;
+
+ALTER TABLE container
+    ADD COLUMN conditions JSONB NOT NULL DEFAULT '[]';
+
+-- Containers created before probes existed have none, so running ones are live and ready.
+UPDATE container
+SET conditions = jsonb_build_array(
+        jsonb_build_object(
+                'type', 'Live',
+                'status', status = 'running',
+                'reason', CASE WHEN status = 'running' THEN 'Running' ELSE 'NotRunning' END,
+                'message', CASE WHEN status = 'running' THEN '' ELSE 'container is ' || status END,
+                'last_transition_time', to_json(now())
+        ),
+        jsonb_build_object(
+                'type', 'Ready',
+                'status', status = 'running',
+                'reason', CASE WHEN status = 'running' THEN 'Running' ELSE 'NotRunning' END,
+                'message', CASE WHEN status = 'running' THEN '' ELSE 'container is ' || status END,
+                'last_transition_time', to_json(now())
+        )
+    );
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE container
+    DROP COLUMN resource_version;
+ALTER TABLE node
+    DROP COLUMN resource_version;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE node
+    ADD COLUMN resource_version BIGINT NOT NULL DEFAULT 1;
+
+ALTER TABLE container
+    ADD COLUMN resource_version BIGINT NOT NULL DEFAULT 1;
+
+COMMIT;
\ No newline at end of file
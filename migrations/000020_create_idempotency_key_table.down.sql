@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX idempotency_key__created_at;
+DROP TABLE idempotency_key;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE idempotency_key
+(
+    key             VARCHAR(255) PRIMARY KEY,
+    request_hash    VARCHAR(64)  NOT NULL,
+    response_status INTEGER,
+    response_header JSONB,
+    response_body   BYTEA,
+    created_at      TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX idempotency_key__created_at ON idempotency_key (created_at);
+
+COMMIT;
\ No newline at end of file
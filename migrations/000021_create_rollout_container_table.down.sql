@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX rollout_container__container_id;
+DROP TABLE rollout_container;
+
+COMMIT;
\ No newline at end of file
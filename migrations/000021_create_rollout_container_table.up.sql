@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE rollout_container
+(
+    rollout_id   VARCHAR(36) NOT NULL REFERENCES rollout (id) ON DELETE CASCADE,
+    container_id VARCHAR(36) NOT NULL,
+    active       BOOLEAN     NOT NULL,
+    PRIMARY KEY (rollout_id, container_id)
+);
+
+CREATE UNIQUE INDEX rollout_container__container_id ON rollout_container (container_id) WHERE active;
+
+INSERT INTO rollout_container (rollout_id, container_id, active)
+SELECT rollout.id, container.id, rollout.phase IN ('progressing', 'rolling_back')
+FROM rollout, jsonb_array_elements_text(rollout.containers) AS container (id)
+ON CONFLICT DO NOTHING;
+
+COMMIT;
\ No newline at end of file
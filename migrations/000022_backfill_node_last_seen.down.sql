@@ -0,0 +1,5 @@
+BEGIN;
+
+-- The backfilled times cannot be told apart from heartbeats, they are kept.
+
+COMMIT;
\ No newline at end of file
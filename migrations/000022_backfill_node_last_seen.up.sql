@@ -0,0 +1,7 @@
+BEGIN;
+
+UPDATE node
+SET last_seen = now()
+WHERE status = 'running' AND last_seen IS NULL;
+
+COMMIT;
\ No newline at end of file
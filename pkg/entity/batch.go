@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+var InvalidBatchErr = errors.New("invalid batch")
+
+// MaxBatchSize bounds the items of one batch request.
+const MaxBatchSize = 500
+
+type BatchMode string
+
+const (
+	// BatchModeAtomic applies all items or, when one of them fails, none.
+	BatchModeAtomic BatchMode = "atomic"
+	// BatchModeBestEffort applies every item that succeeds and skips the failed ones.
+	BatchModeBestEffort BatchMode = "best_effort"
+)
+
+// BatchAddContainers godoc
+// entity.BatchAddContainers struct
+type BatchAddContainers struct {
+	// Mode defaults to atomic
+	Mode  BatchMode      `json:"mode" enums:"atomic,best_effort"`
+	Items []AddContainer `json:"items"`
+}
+
+// BatchUpdateContainers godoc
+// entity.BatchUpdateContainers struct
+type BatchUpdateContainers struct {
+	// Mode defaults to atomic
+	Mode  BatchMode   `json:"mode" enums:"atomic,best_effort"`
+	Items []Container `json:"items"`
+}
+
+// BatchAddNodes godoc
+// entity.BatchAddNodes struct
+type BatchAddNodes struct {
+	// Mode defaults to atomic
+	Mode  BatchMode `json:"mode" enums:"atomic,best_effort"`
+	Items []AddNode `json:"items"`
+}
+
+// BatchUpdateNodes godoc
+// entity.BatchUpdateNodes struct
+type BatchUpdateNodes struct {
+	// Mode defaults to atomic
+	Mode  BatchMode `json:"mode" enums:"atomic,best_effort"`
+	Items []Node    `json:"items"`
+}
+
+// BatchDelete godoc
+// entity.BatchDelete struct
+type BatchDelete struct {
+	// Mode defaults to atomic
+	Mode BatchMode   `json:"mode" enums:"atomic,best_effort"`
+	IDs  []uuid.UUID `json:"ids"`
+}
+
+// ValidateBatch checks the mode and the number of items of a batch,
+// defaulting the mode to atomic.
+func ValidateBatch(mode *BatchMode, items int) error {
+	switch *mode {
+	case "":
+		*mode = BatchModeAtomic
+	case BatchModeAtomic, BatchModeBestEffort:
+	default:
+		return fmt.Errorf("%w: unknown mode %q", InvalidBatchErr, *mode)
+	}
+	if items == 0 {
+		return fmt.Errorf("%w: no items", InvalidBatchErr)
+	}
+	if items > MaxBatchSize {
+		return fmt.Errorf("%w: more than %d items", InvalidBatchErr, MaxBatchSize)
+	}
+	return nil
+}
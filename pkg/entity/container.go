@@ -2,49 +2,269 @@ package entity
 
 import (
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"reflect"
+	"time"
 )
 
-var InvalidContainerStatusErr = errors.New("invalid container status")
+var (
+	InvalidContainerStatusErr = errors.New("invalid container status")
+	InvalidContainerStateErr  = errors.New("invalid container state")
+	InvalidRestartPolicyErr   = errors.New("invalid restart policy")
+)
+
+// maxContainerStateReasonLength bounds the reason reported by node agents.
+const maxContainerStateReasonLength = 1024
 
 type ContainerStatus string
 
+type RestartPolicy string
+
+// ContainerPort godoc
+// entity.ContainerPort struct
+type ContainerPort struct {
+	ContainerPort int    `json:"container_port"`
+	Protocol      string `json:"protocol"`
+}
+
+// ContainerSpec godoc
+// entity.ContainerSpec struct
+type ContainerSpec struct {
+	Command       []string          `json:"command"`
+	Args          []string          `json:"args"`
+	Env           map[string]string `json:"env"`
+	Ports         []ContainerPort   `json:"ports"`
+	WorkingDir    string            `json:"working_dir"`
+	RestartPolicy RestartPolicy     `json:"restart_policy"`
+	Tolerations   []Toleration      `json:"tolerations"`
+	// LivenessProbe failures make the node agent restart the container according to its restart policy
+	LivenessProbe *Probe `json:"liveness_probe"`
+	// ReadinessProbe decides whether the container is ready, it is not restarted when the probe fails
+	ReadinessProbe *Probe `json:"readiness_probe"`
+}
+
 // AddContainer godoc
 // entity.AddContainer struct
 type AddContainer struct {
-	NodeID uuid.UUID `json:"node_id"`
-	Image  string    `json:"image"`
+	// NodeID is optional, the scheduler picks a running node when it is omitted
+	NodeID    uuid.UUID     `json:"node_id"`
+	Image     string        `json:"image"`
+	Resources Resources     `json:"resources"`
+	Spec      ContainerSpec `json:"spec"`
+	Labels    Labels        `json:"labels"`
+	Placement Placement     `json:"placement"`
+	// Owner is set by controllers creating containers on behalf of another resource
+	Owner *OwnerReference `json:"-"`
+}
+
+const (
+	OwnerKindDeployment = "deployment"
+	OwnerKindJob        = "job"
+	OwnerKindCronJob    = "cronjob"
+)
+
+// OwnerReference godoc
+// entity.OwnerReference struct
+type OwnerReference struct {
+	Kind string    `json:"kind" enums:"deployment,job,cronjob"`
+	ID   uuid.UUID `json:"id"`
 }
 
 // Container godoc
 // entity.Container struct
 type Container struct {
-	ID     uuid.UUID       `json:"id"`
-	NodeID uuid.UUID       `json:"node_id"`
-	Image  string          `json:"image"`
-	Status ContainerStatus `json:"status"`
+	ID        uuid.UUID       `json:"id"`
+	NodeID    uuid.UUID       `json:"node_id"`
+	Image     string          `json:"image"`
+	Status    ContainerStatus `json:"status"`
+	Resources Resources       `json:"resources"`
+	Spec      ContainerSpec   `json:"spec"`
+	Labels    Labels          `json:"labels"`
+	Placement Placement       `json:"placement"`
+	// PlacementReasons explains why the container was placed on its node
+	PlacementReasons []string `json:"placement_reasons"`
+	// RescheduleReason is set while the container waits to be moved off its node
+	RescheduleReason string `json:"reschedule_reason"`
+	// Owner is the resource managing the container, nil for containers created directly
+	Owner *OwnerReference `json:"owner"`
+	// State is reported by the node agent through the status sub-resource
+	State ContainerState `json:"state"`
+	// Conditions are derived from the status, the probes and the reported probe results
+	Conditions []ContainerCondition `json:"conditions"`
+	// ResourceVersion grows with every change of the container, updates have
+	// to send back the version they were based on
+	ResourceVersion int64 `json:"resource_version"`
 }
 
 func NewContainer(nodeID uuid.UUID, image string) *Container {
 	return &Container{
-		ID:     uuid.New(),
-		NodeID: nodeID,
-		Image:  image,
-		Status: ContainerStatusPending,
+		ID:               uuid.New(),
+		NodeID:           nodeID,
+		Image:            image,
+		Status:           ContainerStatusPending,
+		Labels:           Labels{},
+		PlacementReasons: []string{},
+		Conditions:       []ContainerCondition{},
+		ResourceVersion:  1,
+	}
+}
+
+// DesiredStateChanged reports whether updated differs from c in what the node
+// agents run: the node, image, resources, spec or placement. Status, state
+// and conditions are reported by the agents and do not count.
+func (c *Container) DesiredStateChanged(updated *Container) bool {
+	return c.NodeID != updated.NodeID ||
+		c.Image != updated.Image ||
+		c.Resources != updated.Resources ||
+		!reflect.DeepEqual(c.Spec, updated.Spec) ||
+		!reflect.DeepEqual(c.Placement, updated.Placement)
+}
+
+// ContainerState godoc
+// entity.ContainerState struct
+type ContainerState struct {
+	// ExitCode of the process, only reported with the stopped, exited and failed statuses
+	ExitCode *int `json:"exit_code"`
+	// Signal that terminated the process, like SIGKILL
+	Signal string `json:"signal"`
+	// OOMKilled is set when the process was killed for exceeding its memory limit
+	OOMKilled bool `json:"oom_killed"`
+	// RestartCount is how many times the node agent restarted the container according to its restart policy
+	RestartCount int        `json:"restart_count"`
+	StartedAt    *time.Time `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+	// Reason is a human readable explanation of the status, like why the container failed
+	Reason string `json:"reason"`
+	// Liveness and Readiness are the latest results of the probes of the spec
+	Liveness  *ProbeResult `json:"liveness"`
+	Readiness *ProbeResult `json:"readiness"`
+}
+
+// Validate checks the state reported together with status. Details of a
+// terminated process are only accepted with the statuses of one.
+func (s *ContainerState) Validate(status ContainerStatus) error {
+	terminated := status == ContainerStatusStopped || status == ContainerStatusExited || status == ContainerStatusFailed
+	if !terminated && (s.ExitCode != nil || s.Signal != "" || s.OOMKilled || s.FinishedAt != nil) {
+		return fmt.Errorf("%w: exit_code, signal, oom_killed and finished_at require a terminated container, got status %s",
+			InvalidContainerStateErr, status)
+	}
+	if s.ExitCode != nil && (*s.ExitCode < 0 || *s.ExitCode > 255) {
+		return fmt.Errorf("%w: exit_code must be between 0 and 255", InvalidContainerStateErr)
+	}
+	if s.RestartCount < 0 {
+		return fmt.Errorf("%w: restart_count must not be negative", InvalidContainerStateErr)
+	}
+	if s.StartedAt != nil && s.FinishedAt != nil && s.FinishedAt.Before(*s.StartedAt) {
+		return fmt.Errorf("%w: finished_at must not be before started_at", InvalidContainerStateErr)
+	}
+	if len(s.Reason) > maxContainerStateReasonLength {
+		return fmt.Errorf("%w: reason must be at most %d characters", InvalidContainerStateErr, maxContainerStateReasonLength)
+	}
+	for _, result := range []*ProbeResult{s.Liveness, s.Readiness} {
+		if result == nil {
+			continue
+		}
+		if err := result.validate(); err != nil {
+			return fmt.Errorf("%w: %s", InvalidContainerStateErr, err)
+		}
 	}
+	return nil
 }
 
+// UpdateContainerStatus godoc
+// entity.UpdateContainerStatus struct
+type UpdateContainerStatus struct {
+	Status ContainerStatus `json:"status"`
+	State  ContainerState  `json:"state"`
+}
+
+// ContainerStatusChange godoc
+// entity.ContainerStatusChange struct
+type ContainerStatusChange struct {
+	ContainerID uuid.UUID       `json:"container_id"`
+	From        ContainerStatus `json:"from,omitempty"`
+	To          ContainerStatus `json:"to"`
+	ChangedAt   time.Time       `json:"changed_at"`
+}
+
+// ContainerEvent godoc
+// entity.ContainerEvent struct
+type ContainerEvent struct {
+	ContainerID uuid.UUID `json:"container_id"`
+	// Reason is a short machine readable cause, like Rescheduled
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const (
+	// EventReasonRescheduled is recorded when a container is moved to another node.
+	EventReasonRescheduled = "Rescheduled"
+	// EventReasonRescheduleFailed is recorded when a container has to leave its node but no other node can take it.
+	EventReasonRescheduleFailed = "RescheduleFailed"
+	// EventReasonImageReplaced is recorded when a rollout switches the image of a container.
+	EventReasonImageReplaced = "ImageReplaced"
+)
+
 const (
-	ContainerStatusRunning ContainerStatus = "running"
-	ContainerStatusFailed  ContainerStatus = "failed"
-	ContainerStatusPending ContainerStatus = "pending"
+	ContainerStatusPending     ContainerStatus = "pending"
+	ContainerStatusCreating    ContainerStatus = "creating"
+	ContainerStatusRunning     ContainerStatus = "running"
+	ContainerStatusStopped     ContainerStatus = "stopped"
+	ContainerStatusExited      ContainerStatus = "exited"
+	ContainerStatusFailed      ContainerStatus = "failed"
+	ContainerStatusTerminating ContainerStatus = "terminating"
 )
 
+// containerStatusTransitions lists the statuses a container may move to from each status.
+var containerStatusTransitions = map[ContainerStatus][]ContainerStatus{
+	ContainerStatusPending:  {ContainerStatusCreating, ContainerStatusFailed, ContainerStatusTerminating},
+	ContainerStatusCreating: {ContainerStatusRunning, ContainerStatusFailed, ContainerStatusTerminating},
+	ContainerStatusRunning: {
+		ContainerStatusStopped,
+		ContainerStatusExited,
+		ContainerStatusFailed,
+		ContainerStatusTerminating,
+	},
+	ContainerStatusStopped:     {ContainerStatusPending, ContainerStatusTerminating},
+	ContainerStatusExited:      {ContainerStatusPending, ContainerStatusTerminating},
+	ContainerStatusFailed:      {ContainerStatusPending, ContainerStatusTerminating},
+	ContainerStatusTerminating: {},
+}
+
 func (cs ContainerStatus) Validate() error {
-	switch cs {
-	case ContainerStatusRunning, ContainerStatusPending, ContainerStatusFailed:
+	if _, ok := containerStatusTransitions[cs]; !ok {
+		return InvalidContainerStatusErr
+	}
+	return nil
+}
+
+// CanTransitionTo reports whether a container in cs may move to next.
+// Staying in the same status is always allowed.
+func (cs ContainerStatus) CanTransitionTo(next ContainerStatus) bool {
+	if cs == next {
+		return true
+	}
+	for _, allowed := range containerStatusTransitions[cs] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	RestartPolicyAlways    RestartPolicy = "always"
+	RestartPolicyOnFailure RestartPolicy = "on-failure"
+	RestartPolicyNever     RestartPolicy = "never"
+)
+
+func (rp RestartPolicy) Validate() error {
+	switch rp {
+	case RestartPolicyAlways, RestartPolicyOnFailure, RestartPolicyNever:
 		return nil
 	default:
-		return InvalidContainerStatusErr
+		return InvalidRestartPolicyErr
 	}
 }
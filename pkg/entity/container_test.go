@@ -0,0 +1,36 @@
+package entity_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+)
+
+func TestContainerStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, entity.ContainerStatusPending.CanTransitionTo(entity.ContainerStatusCreating))
+	assert.True(t, entity.ContainerStatusCreating.CanTransitionTo(entity.ContainerStatusRunning))
+	assert.True(t, entity.ContainerStatusRunning.CanTransitionTo(entity.ContainerStatusExited))
+	assert.True(t, entity.ContainerStatusFailed.CanTransitionTo(entity.ContainerStatusPending))
+	assert.True(t, entity.ContainerStatusRunning.CanTransitionTo(entity.ContainerStatusRunning))
+
+	assert.False(t, entity.ContainerStatusPending.CanTransitionTo(entity.ContainerStatusRunning))
+	assert.False(t, entity.ContainerStatusFailed.CanTransitionTo(entity.ContainerStatusRunning))
+	assert.False(t, entity.ContainerStatusTerminating.CanTransitionTo(entity.ContainerStatusPending))
+	assert.False(t, entity.ContainerStatusRunning.CanTransitionTo("unknown"))
+}
+
+func TestContainerStatus_Validate(t *testing.T) {
+	assert.NoError(t, entity.ContainerStatusTerminating.Validate())
+	assert.ErrorIs(t, entity.ContainerStatus("paused").Validate(), entity.InvalidContainerStatusErr)
+}
+
+func TestContainer_IsTerminated(t *testing.T) {
+	assert.True(t, (&entity.Container{Status: entity.ContainerStatusExited}).IsTerminated())
+	assert.True(t, (&entity.Container{Status: entity.ContainerStatusStopped}).IsTerminated())
+	assert.True(t, (&entity.Container{Status: entity.ContainerStatusFailed}).IsTerminated())
+
+	rescheduling := &entity.Container{Status: entity.ContainerStatusFailed, RescheduleReason: "node failed"}
+	assert.False(t, rescheduling.IsTerminated())
+	assert.False(t, (&entity.Container{Status: entity.ContainerStatusRunning}).IsTerminated())
+	assert.False(t, (&entity.Container{Status: entity.ContainerStatusTerminating}).IsTerminated())
+}
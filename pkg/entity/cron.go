@@ -0,0 +1,181 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var InvalidCronScheduleErr = errors.New("invalid cron schedule")
+
+// cronSearchYears bounds the search for the next run of schedules that can
+// never run, like the 30th of February.
+const cronSearchYears = 5
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+// cronFields are the fields of a cron expression in order.
+var cronFields = [5]cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{
+		name:  "month",
+		min:   1,
+		max:   12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"},
+	},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// CronSchedule is a parsed cron expression with the five standard fields:
+// minute, hour, day of month, month and day of week. Every field accepts *,
+// numbers, ranges like 1-5, steps like */15 or 0-30/10 and comma separated
+// lists of those, months and days of week also accept names like jan or mon.
+// Sunday is both 0 and 7. The @yearly, @monthly, @weekly, @daily and @hourly
+// macros are accepted as well.
+type CronSchedule struct {
+	expr string
+	// fields holds one bit per allowed value of every field.
+	fields [5]uint64
+	// daysRestricted and weekdaysRestricted are false for fields starting
+	// with *. When both are restricted a day matches if either of them does.
+	daysRestricted, weekdaysRestricted bool
+}
+
+func ParseCronSchedule(expr string) (*CronSchedule, error) {
+	schedule := &CronSchedule{expr: expr}
+	normalized := strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(normalized)]; ok {
+		normalized = macro
+	}
+
+	parts := strings.Fields(normalized)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("%w: %q must have 5 fields", InvalidCronScheduleErr, expr)
+	}
+	for i, field := range cronFields {
+		bits, err := field.parse(parts[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %s", InvalidCronScheduleErr, expr, err)
+		}
+		schedule.fields[i] = bits
+	}
+	// Sunday may be written as 7.
+	if schedule.fields[4]&(1<<7) != 0 {
+		schedule.fields[4] |= 1
+	}
+	schedule.daysRestricted = !strings.HasPrefix(parts[2], "*")
+	schedule.weekdaysRestricted = !strings.HasPrefix(parts[4], "*")
+	return schedule, nil
+}
+
+func (s *CronSchedule) String() string {
+	return s.expr
+}
+
+// Next returns the first time after t the schedule runs at, in the location
+// of t. It returns the zero time when the schedule does not run within the
+// next five years.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+	limit := next.AddDate(cronSearchYears, 0, 0)
+
+	for next.Before(limit) {
+		year, month, day := next.Date()
+		switch {
+		case !s.has(3, int(month)):
+			next = time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+		case !s.matchesDay(next):
+			next = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+		case !s.has(1, next.Hour()):
+			next = time.Date(year, month, day, next.Hour()+1, 0, 0, 0, loc)
+		case !s.has(0, next.Minute()):
+			next = time.Date(year, month, day, next.Hour(), next.Minute()+1, 0, 0, loc)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+func (s *CronSchedule) has(field, value int) bool {
+	return s.fields[field]&(1<<value) != 0
+}
+
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	day, weekday := s.has(2, t.Day()), s.has(4, int(t.Weekday()))
+	if s.daysRestricted && s.weekdaysRestricted {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+// parse returns a bit set of the values the field expression allows.
+func (f cronField) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		span, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepExpr); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepExpr, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		if span != "*" {
+			from, to, isRange := strings.Cut(span, "-")
+			var err error
+			if low, err = f.value(from); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = f.value(to); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = f.max
+			}
+			if low > high {
+				return 0, fmt.Errorf("range %q in %s field is reversed", span, f.name)
+			}
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(expr string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(expr, name) {
+			return f.min + i, nil
+		}
+	}
+	value, err := strconv.Atoi(expr)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("value %q of %s field must be between %d and %d", expr, f.name, f.min, f.max)
+	}
+	return value, nil
+}
@@ -0,0 +1,54 @@
+package entity_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+	"time"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	// A Wednesday.
+	start := time.Date(2025, time.January, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := map[string]time.Time{
+		"* * * * *":          time.Date(2025, time.January, 15, 10, 8, 0, 0, time.UTC),
+		"*/15 * * * *":       time.Date(2025, time.January, 15, 10, 15, 0, 0, time.UTC),
+		"0 3 * * *":          time.Date(2025, time.January, 16, 3, 0, 0, 0, time.UTC),
+		"@hourly":            time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC),
+		"30 9 * * mon-fri":   time.Date(2025, time.January, 16, 9, 30, 0, 0, time.UTC),
+		"0 0 * * 7":          time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC),
+		"0 0 1 * *":          time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+		"0 12 29 feb *":      time.Date(2028, time.February, 29, 12, 0, 0, 0, time.UTC),
+		"0 0 13 * fri":       time.Date(2025, time.January, 17, 0, 0, 0, 0, time.UTC),
+		"5,10-20/5 10 * * *": time.Date(2025, time.January, 15, 10, 10, 0, 0, time.UTC),
+		"0 0 30 2 *":         {},
+	}
+	for expr, want := range tests {
+		t.Run(expr, func(t *testing.T) {
+			schedule, err := entity.ParseCronSchedule(expr)
+			require.NoError(t, err)
+			assert.Equal(t, want, schedule.Next(start))
+		})
+	}
+}
+
+func TestParseCronSchedule_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@reboot",
+	} {
+		_, err := entity.ParseCronSchedule(expr)
+		assert.ErrorIs(t, err, entity.InvalidCronScheduleErr, expr)
+	}
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"regexp"
+)
+
+var InvalidDeploymentErr = errors.New("invalid deployment")
+
+var namePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// ContainerTemplate godoc
+// entity.ContainerTemplate struct
+type ContainerTemplate struct {
+	Image     string        `json:"image"`
+	Resources Resources     `json:"resources"`
+	Spec      ContainerSpec `json:"spec"`
+	Labels    Labels        `json:"labels"`
+	Placement Placement     `json:"placement"`
+}
+
+// AddDeployment godoc
+// entity.AddDeployment struct
+type AddDeployment struct {
+	// Name is a lowercase DNS label unique among deployments
+	Name     string            `json:"name"`
+	Replicas int               `json:"replicas"`
+	Template ContainerTemplate `json:"template"`
+}
+
+// DeploymentStatus godoc
+// entity.DeploymentStatus struct
+type DeploymentStatus struct {
+	// Replicas is the number of containers the deployment owns
+	Replicas int `json:"replicas"`
+	// ReadyReplicas is the number of owned containers that are ready
+	ReadyReplicas int `json:"ready_replicas"`
+	// Message explains why the deployment could not converge, it is empty when it did
+	Message string `json:"message"`
+}
+
+// Deployment godoc
+// entity.Deployment struct
+type Deployment struct {
+	ID       uuid.UUID         `json:"id"`
+	Name     string            `json:"name"`
+	Replicas int               `json:"replicas"`
+	Template ContainerTemplate `json:"template"`
+	// Status is maintained by the deployment controller and ignored on updates
+	Status DeploymentStatus `json:"status"`
+}
+
+func NewDeployment(name string, replicas int, template ContainerTemplate) *Deployment {
+	return &Deployment{
+		ID:       uuid.New(),
+		Name:     name,
+		Replicas: replicas,
+		Template: template,
+	}
+}
+
+// ValidateName checks that name is a lowercase DNS label of at most 63 characters.
+func ValidateName(name string) error {
+	if len(name) > 63 || !namePattern.MatchString(name) {
+		return fmt.Errorf("name %q must be a lowercase DNS label", name)
+	}
+	return nil
+}
+
+// Validate checks the name and replica count, the template is validated by the container service.
+func (d *Deployment) Validate() error {
+	if err := ValidateName(d.Name); err != nil {
+		return fmt.Errorf("%w: %s", InvalidDeploymentErr, err)
+	}
+	if d.Replicas < 0 {
+		return fmt.Errorf("%w: replicas must not be negative", InvalidDeploymentErr)
+	}
+	return nil
+}
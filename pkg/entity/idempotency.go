@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"net/http"
+	"time"
+)
+
+// MaxIdempotencyKeyLength bounds the keys clients may choose.
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyRecord remembers a request sent with an Idempotency-Key header
+// so that retries of it are answered with the original response.
+type IdempotencyRecord struct {
+	Key string
+	// RequestHash identifies the request the key was first used for.
+	RequestHash string
+	// Response is nil while the request is processed.
+	Response  *IdempotentResponse
+	CreatedAt time.Time
+}
+
+// IdempotentResponse is the stored response of a request.
+type IdempotentResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// InProgress reports whether the request has not been answered yet.
+func (r *IdempotencyRecord) InProgress() bool {
+	return r.Response == nil
+}
@@ -0,0 +1,245 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+var (
+	InvalidJobErr     = errors.New("invalid job")
+	InvalidCronJobErr = errors.New("invalid cron job")
+)
+
+// maxCronJobNameLength leaves room for the schedule time appended to the
+// names of the jobs a cron job creates.
+const maxCronJobNameLength = 52
+
+const (
+	DefaultSuccessfulJobsHistoryLimit = 3
+	DefaultFailedJobsHistoryLimit     = 1
+)
+
+type JobPhase string
+
+const (
+	JobPhaseActive    JobPhase = "active"
+	JobPhaseSucceeded JobPhase = "succeeded"
+	JobPhaseFailed    JobPhase = "failed"
+)
+
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyPolicyAllow starts scheduled jobs even while earlier ones are active.
+	ConcurrencyPolicyAllow ConcurrencyPolicy = "Allow"
+	// ConcurrencyPolicyForbid skips scheduled jobs while an earlier one is active.
+	ConcurrencyPolicyForbid ConcurrencyPolicy = "Forbid"
+	// ConcurrencyPolicyReplace deletes the active jobs before starting a scheduled one.
+	ConcurrencyPolicyReplace ConcurrencyPolicy = "Replace"
+)
+
+func (p ConcurrencyPolicy) Validate() error {
+	switch p {
+	case ConcurrencyPolicyAllow, ConcurrencyPolicyForbid, ConcurrencyPolicyReplace:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown concurrency policy %q", InvalidCronJobErr, p)
+	}
+}
+
+// JobSpec godoc
+// entity.JobSpec struct
+type JobSpec struct {
+	// Template describes the containers of the job, they are never restarted in place
+	Template ContainerTemplate `json:"template"`
+	// Completions is how many containers have to exit with code 0, 1 when omitted
+	Completions int `json:"completions"`
+	// Parallelism is how many containers may run at once, 1 when omitted
+	Parallelism int `json:"parallelism"`
+	// BackoffLimit is how many failed containers are retried before the job fails
+	BackoffLimit int `json:"backoff_limit"`
+}
+
+// Validate fills in the default completions and parallelism and checks the
+// counts, the template is validated by the container service.
+func (s *JobSpec) Validate() error {
+	if s.Completions == 0 {
+		s.Completions = 1
+	}
+	if s.Parallelism == 0 {
+		s.Parallelism = 1
+	}
+	if s.Completions < 0 || s.Parallelism < 0 || s.BackoffLimit < 0 {
+		return fmt.Errorf("%w: completions, parallelism and backoff_limit must not be negative", InvalidJobErr)
+	}
+	return nil
+}
+
+// AddJob godoc
+// entity.AddJob struct
+type AddJob struct {
+	// Name is a lowercase DNS label unique among jobs
+	Name string  `json:"name"`
+	Spec JobSpec `json:"spec"`
+	// Owner is set when a cron job creates the job
+	Owner *OwnerReference `json:"-"`
+}
+
+// JobRun godoc
+// entity.JobRun struct
+type JobRun struct {
+	ContainerID uuid.UUID `json:"container_id"`
+	NodeID      uuid.UUID `json:"node_id"`
+	// ExitCode is the exit code reported by the node agent, nil when none was reported
+	ExitCode  *int `json:"exit_code"`
+	Succeeded bool `json:"succeeded"`
+	// Reason is the final status of the container
+	Reason ContainerStatus `json:"reason"`
+	// Message is the reason reported by the node agent
+	Message    string    `json:"message"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// JobStatus godoc
+// entity.JobStatus struct
+type JobStatus struct {
+	Phase JobPhase `json:"phase" enums:"active,succeeded,failed"`
+	// Active is the number of containers of the job that did not finish yet
+	Active    int `json:"active"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	// Runs lists every finished container of the job, finished containers are removed from their nodes
+	Runs        []JobRun   `json:"runs"`
+	CompletedAt *time.Time `json:"completed_at"`
+	// Message explains why the job is waiting or failed
+	Message string `json:"message"`
+}
+
+// Job godoc
+// entity.Job struct
+type Job struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Spec JobSpec   `json:"spec"`
+	// Owner is the cron job that created the job, nil for jobs created directly
+	Owner     *OwnerReference `json:"owner"`
+	Status    JobStatus       `json:"status"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func NewJob(name string, spec JobSpec) *Job {
+	return &Job{
+		ID:        uuid.New(),
+		Name:      name,
+		Spec:      spec,
+		Status:    JobStatus{Phase: JobPhaseActive, Runs: []JobRun{}},
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+func (j *Job) Validate() error {
+	if err := ValidateName(j.Name); err != nil {
+		return fmt.Errorf("%w: %s", InvalidJobErr, err)
+	}
+	return j.Spec.Validate()
+}
+
+// IsFinished reports whether the job succeeded or failed.
+func (j *Job) IsFinished() bool {
+	return j.Status.Phase == JobPhaseSucceeded || j.Status.Phase == JobPhaseFailed
+}
+
+// AddCronJob godoc
+// entity.AddCronJob struct
+type AddCronJob struct {
+	// Name is a lowercase DNS label of at most 52 characters unique among cron jobs
+	Name string `json:"name"`
+	// Schedule is a cron expression evaluated in UTC, like "0 3 * * *"
+	Schedule          string            `json:"schedule"`
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrency_policy" enums:"Allow,Forbid,Replace"`
+	Suspend           bool              `json:"suspend"`
+	// SuccessfulJobsHistoryLimit is how many succeeded jobs are kept, 3 when omitted
+	SuccessfulJobsHistoryLimit *int `json:"successful_jobs_history_limit"`
+	// FailedJobsHistoryLimit is how many failed jobs are kept, 1 when omitted
+	FailedJobsHistoryLimit *int    `json:"failed_jobs_history_limit"`
+	JobTemplate            JobSpec `json:"job_template"`
+}
+
+// CronJobStatus godoc
+// entity.CronJobStatus struct
+type CronJobStatus struct {
+	LastScheduleTime *time.Time `json:"last_schedule_time"`
+	// Active lists the jobs of the cron job that did not finish yet
+	Active []uuid.UUID `json:"active"`
+	// Message explains why the last scheduled job was skipped or could not be created
+	Message string `json:"message"`
+}
+
+// CronJob godoc
+// entity.CronJob struct
+type CronJob struct {
+	ID                         uuid.UUID         `json:"id"`
+	Name                       string            `json:"name"`
+	Schedule                   string            `json:"schedule"`
+	ConcurrencyPolicy          ConcurrencyPolicy `json:"concurrency_policy" enums:"Allow,Forbid,Replace"`
+	Suspend                    bool              `json:"suspend"`
+	SuccessfulJobsHistoryLimit int               `json:"successful_jobs_history_limit"`
+	FailedJobsHistoryLimit     int               `json:"failed_jobs_history_limit"`
+	JobTemplate                JobSpec           `json:"job_template"`
+	// Status is maintained by the cron job controller and ignored on updates
+	Status    CronJobStatus `json:"status"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// NewCronJob creates a cron job from req, filling in the default concurrency
+// policy and history limits.
+func NewCronJob(req *AddCronJob) *CronJob {
+	cronJob := &CronJob{
+		ID:                         uuid.New(),
+		Name:                       req.Name,
+		Schedule:                   req.Schedule,
+		ConcurrencyPolicy:          req.ConcurrencyPolicy,
+		Suspend:                    req.Suspend,
+		SuccessfulJobsHistoryLimit: DefaultSuccessfulJobsHistoryLimit,
+		FailedJobsHistoryLimit:     DefaultFailedJobsHistoryLimit,
+		JobTemplate:                req.JobTemplate,
+		Status:                     CronJobStatus{Active: []uuid.UUID{}},
+		CreatedAt:                  time.Now().UTC(),
+	}
+	if cronJob.ConcurrencyPolicy == "" {
+		cronJob.ConcurrencyPolicy = ConcurrencyPolicyAllow
+	}
+	if req.SuccessfulJobsHistoryLimit != nil {
+		cronJob.SuccessfulJobsHistoryLimit = *req.SuccessfulJobsHistoryLimit
+	}
+	if req.FailedJobsHistoryLimit != nil {
+		cronJob.FailedJobsHistoryLimit = *req.FailedJobsHistoryLimit
+	}
+	return cronJob
+}
+
+// Validate checks everything but the job template's container template,
+// which is validated by the container service.
+func (c *CronJob) Validate() error {
+	if len(c.Name) > maxCronJobNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", InvalidCronJobErr, maxCronJobNameLength)
+	}
+	if err := ValidateName(c.Name); err != nil {
+		return fmt.Errorf("%w: %s", InvalidCronJobErr, err)
+	}
+	if _, err := ParseCronSchedule(c.Schedule); err != nil {
+		return fmt.Errorf("%w: %s", InvalidCronJobErr, err)
+	}
+	if c.ConcurrencyPolicy == "" {
+		c.ConcurrencyPolicy = ConcurrencyPolicyAllow
+	}
+	if err := c.ConcurrencyPolicy.Validate(); err != nil {
+		return err
+	}
+	if c.SuccessfulJobsHistoryLimit < 0 || c.FailedJobsHistoryLimit < 0 {
+		return fmt.Errorf("%w: history limits must not be negative", InvalidCronJobErr)
+	}
+	return c.JobTemplate.Validate()
+}
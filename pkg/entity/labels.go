@@ -0,0 +1,204 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	InvalidLabelsErr        = errors.New("invalid labels")
+	InvalidLabelSelectorErr = errors.New("invalid label selector")
+)
+
+const (
+	maxLabelNameLength   = 63
+	maxLabelPrefixLength = 253
+)
+
+var (
+	labelNamePattern   = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	labelPrefixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// Labels are key/value pairs used to group and select nodes and containers.
+// Keys are an optional DNS subdomain prefix and a name separated by a slash,
+// like "topology.morchy.io/zone" or "app".
+type Labels map[string]string
+
+func (l Labels) Validate() error {
+	for key, value := range l {
+		if err := validateLabelKey(key); err != nil {
+			return err
+		}
+		if err := validateLabelValue(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateLabelKey(key string) error {
+	name := key
+	if prefix, rest, ok := strings.Cut(key, "/"); ok {
+		if len(prefix) > maxLabelPrefixLength || !labelPrefixPattern.MatchString(prefix) {
+			return fmt.Errorf("%w: key %q has an invalid prefix", InvalidLabelsErr, key)
+		}
+		name = rest
+	}
+	if len(name) > maxLabelNameLength || !labelNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: key %q has an invalid name", InvalidLabelsErr, key)
+	}
+	return nil
+}
+
+func validateLabelValue(value string) error {
+	if value == "" {
+		return nil
+	}
+	if len(value) > maxLabelNameLength || !labelNamePattern.MatchString(value) {
+		return fmt.Errorf("%w: invalid value %q", InvalidLabelsErr, value)
+	}
+	return nil
+}
+
+type SelectorOperator string
+
+const (
+	SelectorEquals       SelectorOperator = "="
+	SelectorNotEquals    SelectorOperator = "!="
+	SelectorIn           SelectorOperator = "in"
+	SelectorNotIn        SelectorOperator = "notin"
+	SelectorExists       SelectorOperator = "exists"
+	SelectorDoesNotExist SelectorOperator = "!"
+)
+
+// LabelRequirement is a single condition of a LabelSelector. Values holds one
+// value for equality operators and none for the existence operators.
+type LabelRequirement struct {
+	Key      string
+	Operator SelectorOperator
+	Values   []string
+}
+
+func (r LabelRequirement) Matches(labels Labels) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case SelectorEquals:
+		return ok && value == r.Values[0]
+	case SelectorNotEquals:
+		return !ok || value != r.Values[0]
+	case SelectorIn:
+		return ok && slices.Contains(r.Values, value)
+	case SelectorNotIn:
+		return !ok || !slices.Contains(r.Values, value)
+	case SelectorExists:
+		return ok
+	case SelectorDoesNotExist:
+		return !ok
+	default:
+		return false
+	}
+}
+
+// LabelSelector matches labels satisfying every requirement, an empty
+// selector matches everything.
+type LabelSelector []LabelRequirement
+
+// ParseLabelSelector parses a comma separated list of requirements in the
+// Kubernetes syntax:
+//
+//	key=value, key==value, key!=value, key in (v1,v2), key notin (v1,v2), key, !key
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var result LabelSelector
+	for _, part := range splitRequirements(selector) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			if strings.TrimSpace(selector) == "" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("%w: empty requirement in %q", InvalidLabelSelectorErr, selector)
+		}
+		requirement, err := parseRequirement(part)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, requirement)
+	}
+	return result, nil
+}
+
+func (s LabelSelector) Matches(labels Labels) bool {
+	for _, requirement := range s {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitRequirements splits on the commas outside of value lists.
+func splitRequirements(selector string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range selector {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, selector[start:])
+}
+
+func parseRequirement(part string) (LabelRequirement, error) {
+	invalid := func(reason string) (LabelRequirement, error) {
+		return LabelRequirement{}, fmt.Errorf("%w: %q %s", InvalidLabelSelectorErr, part, reason)
+	}
+
+	var requirement LabelRequirement
+	if open := strings.IndexByte(part, '('); open >= 0 {
+		fields := strings.Fields(part[:open])
+		if len(fields) != 2 || !strings.HasSuffix(part, ")") {
+			return invalid("is not a key followed by in or notin and a value list")
+		}
+		requirement.Key = fields[0]
+		switch SelectorOperator(fields[1]) {
+		case SelectorIn, SelectorNotIn:
+			requirement.Operator = SelectorOperator(fields[1])
+		default:
+			return invalid("has an unknown set operator")
+		}
+		for _, value := range strings.Split(part[open+1:len(part)-1], ",") {
+			requirement.Values = append(requirement.Values, strings.TrimSpace(value))
+		}
+	} else if key, value, ok := strings.Cut(part, "!="); ok {
+		requirement = LabelRequirement{Key: strings.TrimSpace(key), Operator: SelectorNotEquals, Values: []string{strings.TrimSpace(value)}}
+	} else if key, value, ok = strings.Cut(part, "=="); ok {
+		requirement = LabelRequirement{Key: strings.TrimSpace(key), Operator: SelectorEquals, Values: []string{strings.TrimSpace(value)}}
+	} else if key, value, ok = strings.Cut(part, "="); ok {
+		requirement = LabelRequirement{Key: strings.TrimSpace(key), Operator: SelectorEquals, Values: []string{strings.TrimSpace(value)}}
+	} else if key, ok = strings.CutPrefix(part, "!"); ok {
+		requirement = LabelRequirement{Key: strings.TrimSpace(key), Operator: SelectorDoesNotExist}
+	} else {
+		requirement = LabelRequirement{Key: part, Operator: SelectorExists}
+	}
+
+	if err := validateLabelKey(requirement.Key); err != nil {
+		return invalid("has an invalid key")
+	}
+	for _, value := range requirement.Values {
+		if err := validateLabelValue(value); err != nil {
+			return invalid("has an invalid value")
+		}
+	}
+	return requirement, nil
+}
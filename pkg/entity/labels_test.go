@@ -0,0 +1,45 @@
+package entity_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+)
+
+func TestLabels_Validate(t *testing.T) {
+	assert.NoError(t, entity.Labels{"app": "web", "morchy.io/zone": "eu-1", "empty": ""}.Validate())
+	assert.NoError(t, entity.Labels(nil).Validate())
+
+	assert.ErrorIs(t, entity.Labels{"": "web"}.Validate(), entity.InvalidLabelsErr)
+	assert.ErrorIs(t, entity.Labels{"-app": "web"}.Validate(), entity.InvalidLabelsErr)
+	assert.ErrorIs(t, entity.Labels{"Morchy.io/zone": "eu"}.Validate(), entity.InvalidLabelsErr)
+	assert.ErrorIs(t, entity.Labels{"app": "web server"}.Validate(), entity.InvalidLabelsErr)
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	selector, err := entity.ParseLabelSelector("zone = eu, tier in (web, api),tier notin (db),gpu,!legacy,env!=dev")
+	require.NoError(t, err)
+	assert.Equal(t, entity.LabelSelector{
+		{Key: "zone", Operator: entity.SelectorEquals, Values: []string{"eu"}},
+		{Key: "tier", Operator: entity.SelectorIn, Values: []string{"web", "api"}},
+		{Key: "tier", Operator: entity.SelectorNotIn, Values: []string{"db"}},
+		{Key: "gpu", Operator: entity.SelectorExists},
+		{Key: "legacy", Operator: entity.SelectorDoesNotExist},
+		{Key: "env", Operator: entity.SelectorNotEquals, Values: []string{"dev"}},
+	}, selector)
+
+	assert.True(t, selector.Matches(entity.Labels{"zone": "eu", "tier": "web", "gpu": "", "env": "prod"}))
+	assert.True(t, selector.Matches(entity.Labels{"zone": "eu", "tier": "api", "gpu": "a100"}))
+	assert.False(t, selector.Matches(entity.Labels{"zone": "eu", "tier": "db", "gpu": ""}))
+	assert.False(t, selector.Matches(entity.Labels{"zone": "eu", "tier": "web", "gpu": "", "legacy": "true"}))
+
+	empty, err := entity.ParseLabelSelector(" ")
+	require.NoError(t, err)
+	assert.True(t, empty.Matches(nil))
+
+	for _, invalid := range []string{"zone=eu,", "tier in web", "tier within (web)", "zone=eu west", "=eu", "tier in (web"} {
+		_, err = entity.ParseLabelSelector(invalid)
+		assert.ErrorIs(t, err, entity.InvalidLabelSelectorErr, invalid)
+	}
+}
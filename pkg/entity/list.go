@@ -0,0 +1,41 @@
+package entity
+
+import "github.com/google/uuid"
+
+type SortOrder string
+
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// ListOptions describes one page of a list request. A zero Limit returns every
+// remaining item, Continue is the token returned with the previous page.
+type ListOptions struct {
+	Limit         int
+	Continue      string
+	SortBy        string
+	Order         SortOrder
+	LabelSelector LabelSelector
+}
+
+type ListNodesOptions struct {
+	ListOptions
+	Status NodeStatus
+}
+
+type ListContainersOptions struct {
+	ListOptions
+	Status      ContainerStatus
+	NodeID      uuid.UUID
+	ImagePrefix string
+	OwnerID     uuid.UUID
+	OwnerKind   string
+	// Rescheduling keeps only containers with a reschedule reason
+	Rescheduling bool
+}
+
+type ListJobsOptions struct {
+	ListOptions
+	OwnerID uuid.UUID
+}
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var InvalidLogEntryErr = errors.New("invalid log entry")
+
+// MaxLogLineLength bounds a single log line, agents split longer output.
+const MaxLogLineLength = 16 * 1024
+
+type LogStream string
+
+const (
+	LogStreamStdout LogStream = "stdout"
+	LogStreamStderr LogStream = "stderr"
+)
+
+// LogEntry godoc
+// entity.LogEntry struct
+type LogEntry struct {
+	// Seq orders the entries of a container, it is assigned when the entry is stored
+	Seq       int64     `json:"seq"`
+	Stream    LogStream `json:"stream" enums:"stdout,stderr"`
+	Timestamp time.Time `json:"timestamp"`
+	Line      string    `json:"line"`
+}
+
+// Validate checks an entry sent by a node agent, defaulting the stream to
+// stdout and the timestamp to now.
+func (e *LogEntry) Validate(now time.Time) error {
+	switch e.Stream {
+	case "":
+		e.Stream = LogStreamStdout
+	case LogStreamStdout, LogStreamStderr:
+	default:
+		return fmt.Errorf("%w: unknown stream %q", InvalidLogEntryErr, e.Stream)
+	}
+	if len(e.Line) > MaxLogLineLength {
+		return fmt.Errorf("%w: lines must be at most %d bytes", InvalidLogEntryErr, MaxLogLineLength)
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = now
+	}
+	return nil
+}
+
+// LogOptions selects the log entries of a container. Zero values do not
+// restrict the entries.
+type LogOptions struct {
+	// Tail returns only the last Tail entries
+	Tail int
+	// Since returns only entries logged at or after Since
+	Since time.Time
+	// AfterSeq returns only entries stored after the entry with this sequence number
+	AfterSeq int64
+}
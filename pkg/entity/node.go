@@ -3,24 +3,99 @@ package entity
 import (
 	"errors"
 	"github.com/google/uuid"
+	"time"
 )
 
 var InvalidNodeStatusErr = errors.New("invalid node status")
 
 type NodeStatus string
 
+// AddNode godoc
+// entity.AddNode struct
+type AddNode struct {
+	Capacity Resources `json:"capacity"`
+	Labels   Labels    `json:"labels"`
+	Taints   []Taint   `json:"taints"`
+}
+
+// NodeHeartbeat godoc
+// entity.NodeHeartbeat struct
+type NodeHeartbeat struct {
+	// Capacity is optional, the stored capacity is kept when it is omitted
+	Capacity *Resources `json:"capacity"`
+}
+
 type Node struct {
-	ID         uuid.UUID   `json:"id"`
-	Status     NodeStatus  `json:"status"`
+	ID       uuid.UUID  `json:"id"`
+	Status   NodeStatus `json:"status"`
+	LastSeen *time.Time `json:"last_seen"`
+	Capacity Resources  `json:"capacity"`
+	Revision int64      `json:"revision"`
+	Labels   Labels     `json:"labels"`
+	Taints   []Taint    `json:"taints"`
+	// Unschedulable is set while the node is cordoned, no containers are placed on it then
+	Unschedulable bool        `json:"unschedulable"`
+	Containers    []Container `json:"containers"`
+	// ResourceVersion grows with every change of the node but heartbeats,
+	// updates have to send back the version they were based on
+	ResourceVersion int64 `json:"resource_version"`
+}
+
+// NodeDesiredState godoc
+// entity.NodeDesiredState struct
+type NodeDesiredState struct {
+	NodeID     uuid.UUID   `json:"node_id"`
+	Revision   int64       `json:"revision"`
 	Containers []Container `json:"containers"`
 }
 
-func NewNode() *Node {
+// NodeDrain godoc
+// entity.NodeDrain struct
+type NodeDrain struct {
+	NodeID uuid.UUID              `json:"node_id"`
+	Moved  []ContainerMove        `json:"moved"`
+	Failed []ContainerMoveFailure `json:"failed"`
+}
+
+// ContainerMove godoc
+// entity.ContainerMove struct
+type ContainerMove struct {
+	ContainerID uuid.UUID `json:"container_id"`
+	// NodeID is the node the container was moved to
+	NodeID uuid.UUID `json:"node_id"`
+}
+
+// ContainerMoveFailure godoc
+// entity.ContainerMoveFailure struct
+type ContainerMoveFailure struct {
+	ContainerID uuid.UUID `json:"container_id"`
+	Reason      string    `json:"reason"`
+}
+
+func NewNode(capacity Resources) *Node {
 	return &Node{
-		ID:         uuid.New(),
-		Status:     NewNodeStatus,
-		Containers: []Container{},
+		ID:              uuid.New(),
+		Status:          NewNodeStatus,
+		Capacity:        capacity,
+		Labels:          Labels{},
+		Taints:          []Taint{},
+		Containers:      []Container{},
+		ResourceVersion: 1,
+	}
+}
+
+// Allocated returns the sum of resources requested by the node's containers.
+func (n *Node) Allocated() Resources {
+	var allocated Resources
+	for _, container := range n.Containers {
+		allocated = allocated.Add(container.Resources)
 	}
+	return allocated
+}
+
+// CanFit reports whether the node has enough free capacity for requested.
+func (n *Node) CanFit(requested Resources) bool {
+	return n.Capacity.Fits(n.Allocated(), requested)
 }
 
 const (
@@ -0,0 +1,23 @@
+package entity
+
+// ContainerAffinityTerm godoc
+// entity.ContainerAffinityTerm struct
+type ContainerAffinityTerm struct {
+	// Image matches containers running exactly this image, any image when empty
+	Image string `json:"image"`
+	// LabelSelector matches containers by labels, any labels when empty
+	LabelSelector string `json:"label_selector"`
+}
+
+// Placement godoc
+// entity.Placement struct
+type Placement struct {
+	// NodeSelector lists labels the node must have
+	NodeSelector Labels `json:"node_selector"`
+	// NodeAffinity is a label selector the node labels must match
+	NodeAffinity string `json:"node_affinity"`
+	// Affinity terms must each match a container already on the node
+	Affinity []ContainerAffinityTerm `json:"affinity"`
+	// AntiAffinity terms must not match any container already on the node
+	AntiAffinity []ContainerAffinityTerm `json:"anti_affinity"`
+}
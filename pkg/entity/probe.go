@@ -0,0 +1,260 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultProbePeriodSeconds    = 10
+	DefaultProbeTimeoutSeconds   = 1
+	DefaultProbeSuccessThreshold = 1
+	DefaultProbeFailureThreshold = 3
+)
+
+const (
+	ProbeSchemeHTTP  = "http"
+	ProbeSchemeHTTPS = "https"
+)
+
+// HTTPGetAction godoc
+// entity.HTTPGetAction struct
+type HTTPGetAction struct {
+	// Path defaults to /
+	Path string `json:"path"`
+	Port int    `json:"port"`
+	// Scheme defaults to http
+	Scheme  string            `json:"scheme" enums:"http,https"`
+	Headers map[string]string `json:"headers"`
+}
+
+// TCPSocketAction godoc
+// entity.TCPSocketAction struct
+type TCPSocketAction struct {
+	Port int `json:"port"`
+}
+
+// ExecAction godoc
+// entity.ExecAction struct
+type ExecAction struct {
+	// Command is run inside the container, a zero exit code means success
+	Command []string `json:"command"`
+}
+
+// Probe godoc
+// entity.Probe struct
+type Probe struct {
+	// Exactly one of HTTPGet, TCPSocket and Exec is set
+	HTTPGet             *HTTPGetAction   `json:"http_get"`
+	TCPSocket           *TCPSocketAction `json:"tcp_socket"`
+	Exec                *ExecAction      `json:"exec"`
+	InitialDelaySeconds int              `json:"initial_delay_seconds"`
+	PeriodSeconds       int              `json:"period_seconds"`
+	TimeoutSeconds      int              `json:"timeout_seconds"`
+	// SuccessThreshold is how many probes in a row have to succeed after a failure
+	SuccessThreshold int `json:"success_threshold"`
+	// FailureThreshold is how many probes in a row have to fail after a success
+	FailureThreshold int `json:"failure_threshold"`
+}
+
+// Validate checks the probe and fills in defaults for its timing, thresholds
+// and HTTP scheme and path.
+func (p *Probe) Validate() error {
+	handlers := 0
+	if p.HTTPGet != nil {
+		handlers++
+		if err := validateProbePort(p.HTTPGet.Port); err != nil {
+			return err
+		}
+		if p.HTTPGet.Path == "" {
+			p.HTTPGet.Path = "/"
+		}
+		if !strings.HasPrefix(p.HTTPGet.Path, "/") {
+			return fmt.Errorf("http_get path %q must start with /", p.HTTPGet.Path)
+		}
+		p.HTTPGet.Scheme = strings.ToLower(p.HTTPGet.Scheme)
+		if p.HTTPGet.Scheme == "" {
+			p.HTTPGet.Scheme = ProbeSchemeHTTP
+		}
+		if p.HTTPGet.Scheme != ProbeSchemeHTTP && p.HTTPGet.Scheme != ProbeSchemeHTTPS {
+			return fmt.Errorf("unsupported http_get scheme %q", p.HTTPGet.Scheme)
+		}
+	}
+	if p.TCPSocket != nil {
+		handlers++
+		if err := validateProbePort(p.TCPSocket.Port); err != nil {
+			return err
+		}
+	}
+	if p.Exec != nil {
+		handlers++
+		if len(p.Exec.Command) == 0 {
+			return errors.New("exec command is required")
+		}
+	}
+	if handlers != 1 {
+		return errors.New("exactly one of http_get, tcp_socket and exec is required")
+	}
+
+	if p.PeriodSeconds == 0 {
+		p.PeriodSeconds = DefaultProbePeriodSeconds
+	}
+	if p.TimeoutSeconds == 0 {
+		p.TimeoutSeconds = DefaultProbeTimeoutSeconds
+	}
+	if p.SuccessThreshold == 0 {
+		p.SuccessThreshold = DefaultProbeSuccessThreshold
+	}
+	if p.FailureThreshold == 0 {
+		p.FailureThreshold = DefaultProbeFailureThreshold
+	}
+	switch {
+	case p.InitialDelaySeconds < 0:
+		return errors.New("initial_delay_seconds must not be negative")
+	case p.PeriodSeconds < 1, p.TimeoutSeconds < 1:
+		return errors.New("period_seconds and timeout_seconds must be at least 1")
+	case p.TimeoutSeconds > p.PeriodSeconds:
+		return errors.New("timeout_seconds must not exceed period_seconds")
+	case p.SuccessThreshold < 1, p.FailureThreshold < 1:
+		return errors.New("success_threshold and failure_threshold must be at least 1")
+	}
+	return nil
+}
+
+func validateProbePort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("probe port %d is out of range", port)
+	}
+	return nil
+}
+
+// ProbeResult godoc
+// entity.ProbeResult struct
+type ProbeResult struct {
+	// Succeeded is the outcome of the latest probe
+	Succeeded            bool      `json:"succeeded"`
+	ConsecutiveSuccesses int       `json:"consecutive_successes"`
+	ConsecutiveFailures  int       `json:"consecutive_failures"`
+	Message              string    `json:"message"`
+	ProbedAt             time.Time `json:"probed_at"`
+}
+
+func (r *ProbeResult) validate() error {
+	if r.ConsecutiveSuccesses < 0 || r.ConsecutiveFailures < 0 {
+		return errors.New("consecutive probe counts must not be negative")
+	}
+	if len(r.Message) > maxContainerStateReasonLength {
+		return fmt.Errorf("probe messages must be at most %d characters", maxContainerStateReasonLength)
+	}
+	return nil
+}
+
+type ContainerConditionType string
+
+const (
+	// ContainerConditionLive is true while the container runs and its liveness probe does not fail.
+	ContainerConditionLive ContainerConditionType = "Live"
+	// ContainerConditionReady is true while the container is live and its readiness probe succeeds.
+	ContainerConditionReady ContainerConditionType = "Ready"
+)
+
+// ContainerCondition godoc
+// entity.ContainerCondition struct
+type ContainerCondition struct {
+	Type   ContainerConditionType `json:"type" enums:"Live,Ready"`
+	Status bool                   `json:"status"`
+	// Reason is a short machine readable cause, like ReadinessProbeFailed
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// LastTransitionTime is when Status last changed
+	LastTransitionTime time.Time `json:"last_transition_time"`
+}
+
+// Condition returns the condition of type conditionType, nil when it is not set.
+func (c *Container) Condition(conditionType ContainerConditionType) *ContainerCondition {
+	for i := range c.Conditions {
+		if c.Conditions[i].Type == conditionType {
+			return &c.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsReady reports whether the container runs and passes its probes.
+func (c *Container) IsReady() bool {
+	ready := c.Condition(ContainerConditionReady)
+	return ready != nil && ready.Status
+}
+
+// IsTerminated reports whether the container stopped for good and will not
+// run again. Failed containers waiting to be rescheduled are not terminated.
+func (c *Container) IsTerminated() bool {
+	switch c.Status {
+	case ContainerStatusStopped, ContainerStatusExited:
+		return true
+	case ContainerStatusFailed:
+		return c.RescheduleReason == ""
+	default:
+		return false
+	}
+}
+
+// RefreshConditions derives the conditions of the container from its status,
+// the probes of its spec and the probe results reported by its node agent.
+// A probe changes its condition only once the consecutive results reach its
+// threshold, until then the condition keeps its previous status.
+func (c *Container) RefreshConditions(now time.Time) {
+	var live, ready ContainerCondition
+	if c.Status != ContainerStatusRunning {
+		live = ContainerCondition{Status: false, Reason: "NotRunning", Message: "container is " + string(c.Status)}
+		ready = live
+	} else {
+		live = c.probeCondition(ContainerConditionLive, "Liveness", c.Spec.LivenessProbe, c.State.Liveness, true)
+		ready = c.probeCondition(ContainerConditionReady, "Readiness", c.Spec.ReadinessProbe, c.State.Readiness, false)
+		if !live.Status {
+			ready = ContainerCondition{Status: false, Reason: "NotLive", Message: live.Message}
+		}
+	}
+	live.Type = ContainerConditionLive
+	ready.Type = ContainerConditionReady
+
+	conditions := []ContainerCondition{live, ready}
+	for i := range conditions {
+		conditions[i].LastTransitionTime = now
+		if previous := c.Condition(conditions[i].Type); previous != nil && previous.Status == conditions[i].Status {
+			conditions[i].LastTransitionTime = previous.LastTransitionTime
+		}
+	}
+	c.Conditions = conditions
+}
+
+// probeCondition derives a condition of a running container from a probe.
+// Without a result yet the condition is initial.
+func (c *Container) probeCondition(
+	conditionType ContainerConditionType,
+	probeName string,
+	probe *Probe,
+	result *ProbeResult,
+	initial bool,
+) ContainerCondition {
+	if probe == nil {
+		return ContainerCondition{Status: true, Reason: "Running"}
+	}
+	if result == nil {
+		return ContainerCondition{Status: initial, Reason: probeName + "ProbePending"}
+	}
+	switch {
+	case result.ConsecutiveFailures >= probe.FailureThreshold:
+		return ContainerCondition{Status: false, Reason: probeName + "ProbeFailed", Message: result.Message}
+	case result.ConsecutiveSuccesses >= probe.SuccessThreshold:
+		return ContainerCondition{Status: true, Reason: probeName + "ProbeSucceeded", Message: result.Message}
+	}
+	condition := ContainerCondition{Status: initial, Reason: probeName + "ProbePending", Message: result.Message}
+	if previous := c.Condition(conditionType); previous != nil && previous.Reason != "NotRunning" && previous.Reason != "NotLive" {
+		condition.Status = previous.Status
+		condition.Reason = previous.Reason
+	}
+	return condition
+}
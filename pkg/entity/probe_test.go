@@ -0,0 +1,77 @@
+package entity_test
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+	"time"
+)
+
+func TestProbe_Validate(t *testing.T) {
+	probe := entity.Probe{HTTPGet: &entity.HTTPGetAction{Port: 8080}}
+	require.NoError(t, probe.Validate())
+	assert.Equal(t, "/", probe.HTTPGet.Path)
+	assert.Equal(t, entity.ProbeSchemeHTTP, probe.HTTPGet.Scheme)
+	assert.Equal(t, entity.DefaultProbePeriodSeconds, probe.PeriodSeconds)
+	assert.Equal(t, entity.DefaultProbeFailureThreshold, probe.FailureThreshold)
+
+	assert.Error(t, (&entity.Probe{}).Validate())
+	assert.Error(t, (&entity.Probe{
+		TCPSocket: &entity.TCPSocketAction{Port: 5432},
+		Exec:      &entity.ExecAction{Command: []string{"true"}},
+	}).Validate())
+	assert.Error(t, (&entity.Probe{TCPSocket: &entity.TCPSocketAction{Port: 70000}}).Validate())
+	assert.Error(t, (&entity.Probe{Exec: &entity.ExecAction{}}).Validate())
+	assert.Error(t, (&entity.Probe{HTTPGet: &entity.HTTPGetAction{Port: 80, Scheme: "ftp"}}).Validate())
+	assert.Error(t, (&entity.Probe{Exec: &entity.ExecAction{Command: []string{"true"}}, PeriodSeconds: 1, TimeoutSeconds: 2}).Validate())
+}
+
+func TestContainer_RefreshConditions(t *testing.T) {
+	start := time.Now().UTC()
+	container := entity.NewContainer(uuid.New(), "nginx")
+	container.Spec.ReadinessProbe = &entity.Probe{
+		TCPSocket:        &entity.TCPSocketAction{Port: 80},
+		SuccessThreshold: 2,
+		FailureThreshold: 2,
+	}
+	container.RefreshConditions(start)
+	assert.False(t, container.IsReady())
+	assert.Equal(t, "NotRunning", container.Condition(entity.ContainerConditionReady).Reason)
+
+	report := func(succeeded bool, successes, failures int, at time.Time) {
+		container.State.Readiness = &entity.ProbeResult{
+			Succeeded:            succeeded,
+			ConsecutiveSuccesses: successes,
+			ConsecutiveFailures:  failures,
+		}
+		container.RefreshConditions(at)
+	}
+
+	container.Status = entity.ContainerStatusRunning
+	container.RefreshConditions(start)
+	assert.False(t, container.IsReady(), "ready only once the readiness probe succeeds")
+	assert.True(t, container.Condition(entity.ContainerConditionLive).Status, "live without a liveness probe")
+
+	report(true, 1, 0, start)
+	assert.False(t, container.IsReady(), "below the success threshold")
+	readyAt := start.Add(time.Second)
+	report(true, 2, 0, readyAt)
+	assert.True(t, container.IsReady())
+	assert.Equal(t, readyAt, container.Condition(entity.ContainerConditionReady).LastTransitionTime)
+
+	report(false, 0, 1, readyAt.Add(time.Second))
+	assert.True(t, container.IsReady(), "below the failure threshold")
+	assert.Equal(t, readyAt, container.Condition(entity.ContainerConditionReady).LastTransitionTime)
+	report(false, 0, 2, readyAt.Add(2*time.Second))
+	assert.False(t, container.IsReady())
+	assert.Equal(t, "ReadinessProbeFailed", container.Condition(entity.ContainerConditionReady).Reason)
+
+	container.Spec.LivenessProbe = &entity.Probe{Exec: &entity.ExecAction{Command: []string{"true"}}, FailureThreshold: 1}
+	container.State.Liveness = &entity.ProbeResult{ConsecutiveFailures: 1, Message: "exit code 1"}
+	report(true, 2, 0, readyAt.Add(3*time.Second))
+	assert.False(t, container.Condition(entity.ContainerConditionLive).Status)
+	assert.False(t, container.IsReady(), "containers failing their liveness probe are not ready")
+	assert.Equal(t, "exit code 1", container.Condition(entity.ContainerConditionReady).Message)
+}
@@ -0,0 +1,43 @@
+package entity
+
+import "errors"
+
+var InvalidResourcesErr = errors.New("invalid resources: values must not be negative")
+
+// Resources godoc
+// entity.Resources struct
+type Resources struct {
+	// CPU in millicores
+	CPU int64 `json:"cpu"`
+	// Memory in bytes
+	Memory int64 `json:"memory"`
+	// Disk in bytes
+	Disk int64 `json:"disk"`
+}
+
+func (r Resources) Validate() error {
+	if r.CPU < 0 || r.Memory < 0 || r.Disk < 0 {
+		return InvalidResourcesErr
+	}
+	return nil
+}
+
+func (r Resources) Add(other Resources) Resources {
+	return Resources{
+		CPU:    r.CPU + other.CPU,
+		Memory: r.Memory + other.Memory,
+		Disk:   r.Disk + other.Disk,
+	}
+}
+
+// Fits reports whether requested can be added to used without exceeding r.
+// A zero capacity means the value was never reported and is not enforced.
+func (r Resources) Fits(used, requested Resources) bool {
+	return fits(r.CPU, used.CPU, requested.CPU) &&
+		fits(r.Memory, used.Memory, requested.Memory) &&
+		fits(r.Disk, used.Disk, requested.Disk)
+}
+
+func fits(capacity, used, requested int64) bool {
+	return capacity == 0 || used+requested <= capacity
+}
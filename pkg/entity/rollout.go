@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+var InvalidRolloutErr = errors.New("invalid rollout")
+
+type RolloutPhase string
+
+const (
+	RolloutPhaseProgressing RolloutPhase = "progressing"
+	RolloutPhaseCompleted   RolloutPhase = "completed"
+	RolloutPhaseFailed      RolloutPhase = "failed"
+	RolloutPhaseRollingBack RolloutPhase = "rolling_back"
+	RolloutPhaseRolledBack  RolloutPhase = "rolled_back"
+)
+
+// IsActive reports whether the rollout still changes containers.
+func (p RolloutPhase) IsActive() bool {
+	return p == RolloutPhaseProgressing || p == RolloutPhaseRollingBack
+}
+
+// AddRollout godoc
+// entity.AddRollout struct
+type AddRollout struct {
+	FromImage string `json:"from_image"`
+	ToImage   string `json:"to_image"`
+	// BatchSize is how many containers are switched at once, 1 when omitted
+	BatchSize int `json:"batch_size"`
+	// MaxUnavailable is how many of the targeted containers may be not ready at once, BatchSize when omitted
+	MaxUnavailable int `json:"max_unavailable"`
+}
+
+// Rollout godoc
+// entity.Rollout struct
+type Rollout struct {
+	ID             uuid.UUID    `json:"id"`
+	FromImage      string       `json:"from_image"`
+	ToImage        string       `json:"to_image"`
+	BatchSize      int          `json:"batch_size"`
+	MaxUnavailable int          `json:"max_unavailable"`
+	Phase          RolloutPhase `json:"phase" enums:"progressing,completed,failed,rolling_back,rolled_back"`
+	Paused         bool         `json:"paused"`
+	// Message explains why the rollout is waiting or failed
+	Message string `json:"message"`
+	// Containers are the containers running FromImage when the rollout was created
+	Containers []uuid.UUID `json:"containers"`
+	// Updated are the containers switched to ToImage and not switched back yet
+	Updated []uuid.UUID `json:"updated"`
+	// Reverted are the containers switched back to FromImage by a rollback
+	Reverted  []uuid.UUID `json:"reverted"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// NewRollout creates a progressing rollout of the containers, filling in the
+// default batch size and unavailability budget.
+func NewRollout(req *AddRollout, containers []uuid.UUID) *Rollout {
+	rollout := &Rollout{
+		ID:             uuid.New(),
+		FromImage:      req.FromImage,
+		ToImage:        req.ToImage,
+		BatchSize:      req.BatchSize,
+		MaxUnavailable: req.MaxUnavailable,
+		Phase:          RolloutPhaseProgressing,
+		Containers:     containers,
+		Updated:        []uuid.UUID{},
+		Reverted:       []uuid.UUID{},
+		CreatedAt:      time.Now().UTC(),
+	}
+	if rollout.BatchSize == 0 {
+		rollout.BatchSize = 1
+	}
+	if rollout.MaxUnavailable == 0 {
+		rollout.MaxUnavailable = rollout.BatchSize
+	}
+	return rollout
+}
+
+func (r *Rollout) Validate() error {
+	if r.FromImage == "" || r.ToImage == "" {
+		return fmt.Errorf("%w: from_image and to_image are required", InvalidRolloutErr)
+	}
+	if r.FromImage == r.ToImage {
+		return fmt.Errorf("%w: from_image and to_image must differ", InvalidRolloutErr)
+	}
+	if r.BatchSize < 1 || r.MaxUnavailable < 1 {
+		return fmt.Errorf("%w: batch_size and max_unavailable must be positive", InvalidRolloutErr)
+	}
+	return nil
+}
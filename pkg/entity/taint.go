@@ -0,0 +1,143 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	InvalidTaintErr      = errors.New("invalid taint")
+	InvalidTolerationErr = errors.New("invalid toleration")
+)
+
+type TaintEffect string
+
+const (
+	// TaintEffectNoSchedule keeps new containers off the node.
+	TaintEffectNoSchedule TaintEffect = "NoSchedule"
+	// TaintEffectPreferNoSchedule makes the scheduler avoid the node when possible.
+	TaintEffectPreferNoSchedule TaintEffect = "PreferNoSchedule"
+	// TaintEffectNoExecute keeps new containers off the node and evicts running ones.
+	TaintEffectNoExecute TaintEffect = "NoExecute"
+)
+
+func (e TaintEffect) Validate() error {
+	switch e {
+	case TaintEffectNoSchedule, TaintEffectPreferNoSchedule, TaintEffectNoExecute:
+		return nil
+	default:
+		return fmt.Errorf("unknown taint effect %q", e)
+	}
+}
+
+// Taint godoc
+// entity.Taint struct
+type Taint struct {
+	Key    string      `json:"key"`
+	Value  string      `json:"value"`
+	Effect TaintEffect `json:"effect" enums:"NoSchedule,PreferNoSchedule,NoExecute"`
+}
+
+func (t Taint) String() string {
+	if t.Value == "" {
+		return t.Key + ":" + string(t.Effect)
+	}
+	return t.Key + "=" + t.Value + ":" + string(t.Effect)
+}
+
+// ValidateTaints checks every taint and that no key is repeated with the same effect.
+func ValidateTaints(taints []Taint) error {
+	seen := make(map[Taint]bool, len(taints))
+	for _, taint := range taints {
+		if err := validateLabelKey(taint.Key); err != nil {
+			return fmt.Errorf("%w: invalid key %q", InvalidTaintErr, taint.Key)
+		}
+		if err := validateLabelValue(taint.Value); err != nil {
+			return fmt.Errorf("%w: invalid value %q", InvalidTaintErr, taint.Value)
+		}
+		if err := taint.Effect.Validate(); err != nil {
+			return fmt.Errorf("%w: %s", InvalidTaintErr, err)
+		}
+		key := Taint{Key: taint.Key, Effect: taint.Effect}
+		if seen[key] {
+			return fmt.Errorf("%w: duplicate taint %s:%s", InvalidTaintErr, taint.Key, taint.Effect)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+type TolerationOperator string
+
+const (
+	TolerationOpEqual  TolerationOperator = "Equal"
+	TolerationOpExists TolerationOperator = "Exists"
+)
+
+// Toleration godoc
+// entity.Toleration struct
+type Toleration struct {
+	// Key is the taint key, an empty key with the Exists operator tolerates every taint
+	Key string `json:"key"`
+	// Operator defaults to Equal
+	Operator TolerationOperator `json:"operator" enums:"Equal,Exists"`
+	// Value must be empty for the Exists operator
+	Value string `json:"value"`
+	// Effect is the tolerated effect, every effect when empty
+	Effect TaintEffect `json:"effect" enums:"NoSchedule,PreferNoSchedule,NoExecute"`
+}
+
+func (t Toleration) Validate() error {
+	switch t.Operator {
+	case "", TolerationOpEqual:
+		if t.Key == "" {
+			return fmt.Errorf("%w: the Equal operator needs a key", InvalidTolerationErr)
+		}
+	case TolerationOpExists:
+		if t.Value != "" {
+			return fmt.Errorf("%w: the Exists operator takes no value", InvalidTolerationErr)
+		}
+	default:
+		return fmt.Errorf("%w: unknown operator %q", InvalidTolerationErr, t.Operator)
+	}
+	if t.Effect != "" {
+		if err := t.Effect.Validate(); err != nil {
+			return fmt.Errorf("%w: %s", InvalidTolerationErr, err)
+		}
+	}
+	return nil
+}
+
+// Tolerates reports whether the toleration matches the taint.
+func (t Toleration) Tolerates(taint Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Operator == TolerationOpExists {
+		return t.Key == "" || t.Key == taint.Key
+	}
+	return t.Key == taint.Key && t.Value == taint.Value
+}
+
+// UntoleratedTaints returns the taints of the node with one of effects that
+// none of tolerations matches.
+func UntoleratedTaints(taints []Taint, tolerations []Toleration, effects ...TaintEffect) []Taint {
+	var result []Taint
+	for _, taint := range taints {
+		relevant := false
+		for _, effect := range effects {
+			relevant = relevant || taint.Effect == effect
+		}
+		if !relevant {
+			continue
+		}
+		tolerated := false
+		for _, toleration := range tolerations {
+			tolerated = tolerated || toleration.Tolerates(taint)
+		}
+		if !tolerated {
+			result = append(result, taint)
+		}
+	}
+	return result
+}
@@ -0,0 +1,36 @@
+package entity_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wensiet/morchy-api/pkg/entity"
+	"testing"
+)
+
+func TestValidateTaints(t *testing.T) {
+	assert.NoError(t, entity.ValidateTaints(nil))
+	assert.NoError(t, entity.ValidateTaints([]entity.Taint{
+		{Key: "gpu", Value: "a100", Effect: entity.TaintEffectNoSchedule},
+		{Key: "gpu", Effect: entity.TaintEffectNoExecute},
+	}))
+
+	assert.ErrorIs(t, entity.ValidateTaints([]entity.Taint{{Key: "", Effect: entity.TaintEffectNoSchedule}}), entity.InvalidTaintErr)
+	assert.ErrorIs(t, entity.ValidateTaints([]entity.Taint{{Key: "gpu", Effect: "NoWay"}}), entity.InvalidTaintErr)
+	assert.ErrorIs(t, entity.ValidateTaints([]entity.Taint{
+		{Key: "gpu", Value: "a100", Effect: entity.TaintEffectNoSchedule},
+		{Key: "gpu", Value: "h100", Effect: entity.TaintEffectNoSchedule},
+	}), entity.InvalidTaintErr)
+}
+
+func TestToleration_Tolerates(t *testing.T) {
+	taint := entity.Taint{Key: "gpu", Value: "a100", Effect: entity.TaintEffectNoExecute}
+
+	assert.True(t, entity.Toleration{Key: "gpu", Value: "a100"}.Tolerates(taint))
+	assert.True(t, entity.Toleration{Key: "gpu", Operator: entity.TolerationOpExists}.Tolerates(taint))
+	assert.True(t, entity.Toleration{Operator: entity.TolerationOpExists}.Tolerates(taint))
+	assert.False(t, entity.Toleration{Key: "gpu", Value: "h100"}.Tolerates(taint))
+	assert.False(t, entity.Toleration{Key: "gpu", Value: "a100", Effect: entity.TaintEffectNoSchedule}.Tolerates(taint))
+
+	assert.Error(t, entity.Toleration{Value: "a100"}.Validate())
+	assert.Error(t, entity.Toleration{Key: "gpu", Operator: entity.TolerationOpExists, Value: "a100"}.Validate())
+	assert.Error(t, entity.Toleration{Key: "gpu", Operator: "Like"}.Validate())
+}
@@ -0,0 +1,17 @@
+package entity
+
+type WatchEventType string
+
+const (
+	WatchEventAdded    WatchEventType = "ADDED"
+	WatchEventModified WatchEventType = "MODIFIED"
+	WatchEventDeleted  WatchEventType = "DELETED"
+)
+
+// WatchEvent godoc
+// entity.WatchEvent struct
+type WatchEvent struct {
+	Type            WatchEventType `json:"type"`
+	ResourceVersion uint64         `json:"resource_version"`
+	Object          interface{}    `json:"object"`
+}